@@ -0,0 +1,148 @@
+package adjudication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+)
+
+// Decision statuses persisted on claims.status
+const (
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Claim is the part of a submission that rules evaluate
+type Claim struct {
+	NDC         string
+	NPI         string
+	Quantity    int64
+	Price       float64
+	ServiceDate time.Time
+}
+
+// Source is the reference data rules read; *sqlc.Queries satisfies it so rules run inside the claim transaction
+type Source interface {
+	GetPharmacy(ctx context.Context, npi string) (sqlc.Pharmacy, error)
+	GetDrug(ctx context.Context, ndc string) (sqlc.Drug, error)
+	GetLastApprovedFill(ctx context.Context, arg sqlc.GetLastApprovedFillParams) (sqlc.Claim, error)
+}
+
+// Rule is a single adjudication check. It returns a reject when the claim fails the check.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, source Source, claim Claim) (*Reject, error)
+}
+
+// Reject is an NCPDP-style reject code with its description
+type Reject struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Decision is the outcome of adjudicating a claim
+type Decision struct {
+	Status  string   `json:"status"`
+	Rejects []Reject `json:"rejects,omitempty"`
+}
+
+// Approved reports whether the claim passed every rule
+func (d Decision) Approved() bool {
+	return d.Status == StatusApproved
+}
+
+// Codes returns the reject codes in rule order, never nil so it can be stored in a NOT NULL array
+func (d Decision) Codes() []string {
+	codes := make([]string, 0, len(d.Rejects))
+	for _, reject := range d.Rejects {
+		codes = append(codes, reject.Code)
+	}
+	return codes
+}
+
+// Reject adds a reject to the decision unless its code is already present
+func (d *Decision) Reject(reject Reject) {
+	for _, existing := range d.Rejects {
+		if existing.Code == reject.Code {
+			return
+		}
+	}
+	d.Status = StatusRejected
+	d.Rejects = append(d.Rejects, reject)
+}
+
+// DecisionFromClaim rebuilds the decision persisted with a claim
+func DecisionFromClaim(claim sqlc.Claim) Decision {
+	decision := Decision{Status: claim.Status}
+	for _, code := range claim.RejectCodes {
+		decision.Rejects = append(decision.Rejects, NewReject(code))
+	}
+	return decision
+}
+
+// Engine runs an ordered list of rules against each claim
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine creates an engine that evaluates the rules in the given order
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// NewEngineFromConfig builds the engine from the ADJUDICATION_* settings
+func NewEngineFromConfig(config util.Config) (*Engine, error) {
+	limits := Limits{
+		MaxQuantity:    config.AdjudicationMaxQuantity,
+		MaxPrice:       config.AdjudicationMaxPrice,
+		RefillInterval: config.AdjudicationRefillInterval,
+	}
+
+	var rules []Rule
+	for _, name := range strings.Split(config.AdjudicationRules, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		build, ok := ruleBuilders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown adjudication rule %q", name)
+		}
+		rules = append(rules, build(limits))
+	}
+
+	return NewEngine(rules...), nil
+}
+
+// Rules returns the names of the configured rules in evaluation order
+func (e *Engine) Rules() []string {
+	names := make([]string, 0, len(e.rules))
+	for _, rule := range e.rules {
+		names = append(names, rule.Name())
+	}
+	return names
+}
+
+// Adjudicate evaluates every rule and rejects the claim if any of them fails.
+// All failing rules are reported so the pharmacy can correct the claim in one pass.
+func (e *Engine) Adjudicate(ctx context.Context, source Source, claim Claim) (Decision, error) {
+	decision := Decision{Status: StatusApproved}
+
+	for _, rule := range e.rules {
+		reject, err := rule.Evaluate(ctx, source, claim)
+		if err != nil {
+			return Decision{}, fmt.Errorf("rule %s: %w", rule.Name(), err)
+		}
+
+		if reject != nil {
+			decision.Reject(*reject)
+		}
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,122 @@
+package adjudication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource serves reference data from maps
+type fakeSource struct {
+	pharmacies map[string]sqlc.Pharmacy
+	drugs      map[string]sqlc.Drug
+	fills      map[string]sqlc.Claim
+}
+
+func (f fakeSource) GetPharmacy(ctx context.Context, npi string) (sqlc.Pharmacy, error) {
+	if pharmacy, ok := f.pharmacies[npi]; ok {
+		return pharmacy, nil
+	}
+	return sqlc.Pharmacy{}, pgx.ErrNoRows
+}
+
+func (f fakeSource) GetDrug(ctx context.Context, ndc string) (sqlc.Drug, error) {
+	if drug, ok := f.drugs[ndc]; ok {
+		return drug, nil
+	}
+	return sqlc.Drug{}, pgx.ErrNoRows
+}
+
+func (f fakeSource) GetLastApprovedFill(ctx context.Context, arg sqlc.GetLastApprovedFillParams) (sqlc.Claim, error) {
+	if claim, ok := f.fills[arg.NPI+":"+arg.NDC]; ok {
+		return claim, nil
+	}
+	return sqlc.Claim{}, pgx.ErrNoRows
+}
+
+func TestAdjudicate(t *testing.T) {
+	now := time.Now()
+
+	source := fakeSource{
+		pharmacies: map[string]sqlc.Pharmacy{
+			"1111111111": {NPI: "1111111111", Active: true},
+			"2222222222": {NPI: "2222222222", Active: false},
+		},
+		drugs: map[string]sqlc.Drug{
+			"00002323401": {NDC: "00002323401", QuantityLimit: 90, Active: true},
+			"00002323402": {NDC: "00002323402", Active: false},
+		},
+		fills: map[string]sqlc.Claim{
+			"1111111111:00002323401": {Timestamp: now.Add(-5 * 24 * time.Hour)},
+		},
+	}
+
+	engine := NewEngine(
+		PharmacyActiveRule{},
+		FormularyRule{},
+		QuantityLimitRule{Max: 1000},
+		PriceCeilingRule{Max: 500},
+		RefillTooSoonRule{Interval: 20 * 24 * time.Hour},
+	)
+
+	testCases := []struct {
+		name  string
+		claim Claim
+		codes []string
+	}{
+		{
+			name:  "approved",
+			claim: Claim{NPI: "1111111111", NDC: "00002323401", Quantity: 30, Price: 10, ServiceDate: now.Add(30 * 24 * time.Hour)},
+			codes: []string{},
+		},
+		{
+			name:  "inactive pharmacy",
+			claim: Claim{NPI: "2222222222", NDC: "00002323401", Quantity: 30, Price: 10, ServiceDate: now},
+			codes: []string{RejectPharmacyNotContracted},
+		},
+		{
+			name:  "not covered",
+			claim: Claim{NPI: "1111111111", NDC: "00002323402", Quantity: 30, Price: 10, ServiceDate: now},
+			codes: []string{RejectProductNotCovered},
+		},
+		{
+			name:  "drug quantity limit",
+			claim: Claim{NPI: "1111111111", NDC: "00002323401", Quantity: 120, Price: 10, ServiceDate: now.Add(30 * 24 * time.Hour)},
+			codes: []string{RejectPlanLimitsExceeded},
+		},
+		{
+			name:  "every failing rule is reported in order",
+			claim: Claim{NPI: "1111111111", NDC: "00002323401", Quantity: 2000, Price: 900, ServiceDate: now},
+			codes: []string{RejectPlanLimitsExceeded, RejectCostExceedsMaximum, RejectRefillTooSoon},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision, err := engine.Adjudicate(context.Background(), source, tc.claim)
+			require.NoError(t, err)
+			require.Equal(t, tc.codes, decision.Codes())
+			require.Equal(t, len(tc.codes) == 0, decision.Approved())
+		})
+	}
+}
+
+func TestNewEngineFromConfig(t *testing.T) {
+	engine, err := NewEngineFromConfig(util.Config{AdjudicationRules: "pharmacy_active, price_ceiling"})
+	require.NoError(t, err)
+	require.Equal(t, []string{RulePharmacyActive, RulePriceCeiling}, engine.Rules())
+
+	_, err = NewEngineFromConfig(util.Config{AdjudicationRules: "pharmacy_active,unknown"})
+	require.Error(t, err)
+}
+
+func TestDecisionFromClaim(t *testing.T) {
+	decision := DecisionFromClaim(sqlc.Claim{Status: StatusRejected, RejectCodes: []string{RejectRefillTooSoon}})
+	require.False(t, decision.Approved())
+	require.Equal(t, []Reject{{Code: RejectRefillTooSoon, Message: "Refill Too Soon"}}, decision.Rejects)
+}
@@ -0,0 +1,183 @@
+package adjudication
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// NCPDP reject codes produced by the built-in rules
+const (
+	RejectPharmacyNotContracted = "40"
+	RejectNonMatchedPharmacy    = "50"
+	RejectProductNotCovered     = "70"
+	RejectPlanLimitsExceeded    = "76"
+	RejectCostExceedsMaximum    = "78"
+	RejectRefillTooSoon         = "79"
+)
+
+var rejectMessages = map[string]string{
+	RejectPharmacyNotContracted: "Pharmacy Not Contracted With Plan On Date Of Service",
+	RejectNonMatchedPharmacy:    "Non-Matched Pharmacy Number",
+	RejectProductNotCovered:     "Product/Service Not Covered",
+	RejectPlanLimitsExceeded:    "Plan Limitations Exceeded",
+	RejectCostExceedsMaximum:    "Cost Exceeds Maximum",
+	RejectRefillTooSoon:         "Refill Too Soon",
+}
+
+// NewReject builds a reject with the standard description of the code
+func NewReject(code string) Reject {
+	return Reject{Code: code, Message: rejectMessages[code]}
+}
+
+// Names of the built-in rules, as listed in ADJUDICATION_RULES
+const (
+	RulePharmacyActive = "pharmacy_active"
+	RuleFormulary      = "formulary"
+	RuleQuantityLimit  = "quantity_limit"
+	RulePriceCeiling   = "price_ceiling"
+	RuleRefillTooSoon  = "refill_too_soon"
+)
+
+// Limits holds the configurable thresholds of the built-in rules; zero disables a limit
+type Limits struct {
+	MaxQuantity    int64
+	MaxPrice       float64
+	RefillInterval time.Duration
+}
+
+var ruleBuilders = map[string]func(Limits) Rule{
+	RulePharmacyActive: func(Limits) Rule { return PharmacyActiveRule{} },
+	RuleFormulary:      func(Limits) Rule { return FormularyRule{} },
+	RuleQuantityLimit:  func(l Limits) Rule { return QuantityLimitRule{Max: l.MaxQuantity} },
+	RulePriceCeiling:   func(l Limits) Rule { return PriceCeilingRule{Max: l.MaxPrice} },
+	RuleRefillTooSoon:  func(l Limits) Rule { return RefillTooSoonRule{Interval: l.RefillInterval} },
+}
+
+// PharmacyActiveRule rejects claims from unknown or deactivated pharmacies
+type PharmacyActiveRule struct{}
+
+// Name returns the rule name
+func (PharmacyActiveRule) Name() string { return RulePharmacyActive }
+
+// Evaluate checks the pharmacy's active flag
+func (PharmacyActiveRule) Evaluate(ctx context.Context, source Source, claim Claim) (*Reject, error) {
+	pharmacy, err := source.GetPharmacy(ctx, claim.NPI)
+	if errors.Is(err, pgx.ErrNoRows) {
+		reject := NewReject(RejectNonMatchedPharmacy)
+		return &reject, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !pharmacy.Active {
+		reject := NewReject(RejectPharmacyNotContracted)
+		return &reject, nil
+	}
+
+	return nil, nil
+}
+
+// FormularyRule rejects NDCs that are not in the drug reference or have been retired from it
+type FormularyRule struct{}
+
+// Name returns the rule name
+func (FormularyRule) Name() string { return RuleFormulary }
+
+// Evaluate looks the NDC up in the drug reference
+func (FormularyRule) Evaluate(ctx context.Context, source Source, claim Claim) (*Reject, error) {
+	drug, err := source.GetDrug(ctx, claim.NDC)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !drug.Active) {
+		reject := NewReject(RejectProductNotCovered)
+		return &reject, nil
+	}
+
+	return nil, err
+}
+
+// QuantityLimitRule rejects claims above the plan-wide maximum or the drug's quantity limit
+type QuantityLimitRule struct {
+	Max int64
+}
+
+// Name returns the rule name
+func (QuantityLimitRule) Name() string { return RuleQuantityLimit }
+
+// Evaluate compares the quantity with the applicable limits
+func (r QuantityLimitRule) Evaluate(ctx context.Context, source Source, claim Claim) (*Reject, error) {
+	if r.Max > 0 && claim.Quantity > r.Max {
+		reject := NewReject(RejectPlanLimitsExceeded)
+		return &reject, nil
+	}
+
+	drug, err := source.GetDrug(ctx, claim.NDC)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if drug.QuantityLimit > 0 && claim.Quantity > drug.QuantityLimit {
+		reject := NewReject(RejectPlanLimitsExceeded)
+		return &reject, nil
+	}
+
+	return nil, nil
+}
+
+// PriceCeilingRule rejects claims whose submitted price is above the maximum
+type PriceCeilingRule struct {
+	Max float64
+}
+
+// Name returns the rule name
+func (PriceCeilingRule) Name() string { return RulePriceCeiling }
+
+// Evaluate compares the submitted price with the ceiling
+func (r PriceCeilingRule) Evaluate(ctx context.Context, source Source, claim Claim) (*Reject, error) {
+	if r.Max > 0 && claim.Price > r.Max {
+		reject := NewReject(RejectCostExceedsMaximum)
+		return &reject, nil
+	}
+
+	return nil, nil
+}
+
+// RefillTooSoonRule rejects a fill of the same NDC at the same pharmacy within the refill interval
+// of an earlier approved, unreversed fill. Claims carry no patient, so the pharmacy stands in for it.
+type RefillTooSoonRule struct {
+	Interval time.Duration
+}
+
+// Name returns the rule name
+func (RefillTooSoonRule) Name() string { return RuleRefillTooSoon }
+
+// Evaluate compares the service date with the most recent fill
+func (r RefillTooSoonRule) Evaluate(ctx context.Context, source Source, claim Claim) (*Reject, error) {
+	if r.Interval <= 0 {
+		return nil, nil
+	}
+
+	last, err := source.GetLastApprovedFill(ctx, sqlc.GetLastApprovedFillParams{
+		NPI: claim.NPI,
+		NDC: claim.NDC,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if claim.ServiceDate.Before(last.Timestamp.Add(r.Interval)) {
+		reject := NewReject(RejectRefillTooSoon)
+		return &reject, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Key is a verification key from a JSON Web Key Set
+type Key struct {
+	ID        string
+	Algorithm string
+
+	secret []byte
+	public *rsa.PublicKey
+}
+
+// KeySet holds the keys bearer tokens may be signed with
+type KeySet struct {
+	keys []Key
+}
+
+// jwk is a single key as it appears in a JWKS document
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	K         string `json:"k"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// LoadKeySet reads a JWKS file
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JWKS document. Symmetric keys (kty "oct") verify HS256 tokens and RSA
+// keys verify RS256 tokens; keys meant for encryption are skipped.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, raw := range document.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+
+		key, err := parseKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", i, err)
+		}
+		set.keys = append(set.keys, key)
+	}
+
+	if len(set.keys) == 0 {
+		return nil, errors.New("JWKS contains no signing keys")
+	}
+
+	return set, nil
+}
+
+// parseKey decodes the key material of a JWK
+func parseKey(raw jwk) (Key, error) {
+	key := Key{ID: raw.KeyID, Algorithm: raw.Algorithm}
+
+	switch raw.KeyType {
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(raw.K)
+		if err != nil || len(secret) == 0 {
+			return key, errors.New("invalid symmetric key")
+		}
+		key.secret = secret
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(raw.N)
+		if err != nil || len(n) == 0 {
+			return key, errors.New("invalid RSA modulus")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(raw.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return key, errors.New("invalid RSA exponent")
+		}
+		key.public = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	default:
+		return key, fmt.Errorf("unsupported key type %q", raw.KeyType)
+	}
+
+	return key, nil
+}
+
+// usableFor reports whether the key can verify signatures made with alg
+func (k *Key) usableFor(alg string) bool {
+	if k.Algorithm != "" && k.Algorithm != alg {
+		return false
+	}
+
+	switch alg {
+	case AlgorithmHS256:
+		return k.secret != nil
+	case AlgorithmRS256:
+		return k.public != nil
+	default:
+		return false
+	}
+}
+
+// lookup finds the key for a token header. Without a key ID the set must hold exactly one key
+// for the algorithm.
+func (s *KeySet) lookup(kid, alg string) (*Key, error) {
+	var found *Key
+	for i := range s.keys {
+		key := &s.keys[i]
+		if !key.usableFor(alg) {
+			continue
+		}
+		if kid != "" {
+			if key.ID == kid {
+				return key, nil
+			}
+			continue
+		}
+		if found != nil {
+			return nil, ErrUnknownKey
+		}
+		found = key
+	}
+
+	if found == nil {
+		return nil, ErrUnknownKey
+	}
+	return found, nil
+}
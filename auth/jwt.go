@@ -0,0 +1,255 @@
+// Package auth verifies the bearer tokens of internal users and maps their roles to the
+// permissions checked on each route.
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pharmacy_claims_application/util"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+var (
+	ErrMalformedToken       = errors.New("token is malformed")
+	ErrUnsupportedAlgorithm = errors.New("token algorithm is not supported")
+	ErrUnknownKey           = errors.New("token signing key is unknown")
+	ErrInvalidSignature     = errors.New("token signature is invalid")
+	ErrMissingExpiry        = errors.New("token has no expiry")
+	ErrTokenExpired         = errors.New("token has expired")
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrInvalidIssuer        = errors.New("token issuer is not accepted")
+	ErrInvalidAudience      = errors.New("token audience is not accepted")
+)
+
+// Claims are the verified claims of a bearer token
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	Roles     []string
+}
+
+// Verifier checks the signature and registered claims of bearer tokens
+type Verifier struct {
+	Keys *KeySet
+	// Issuer and Audience are only checked when set
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding the user's roles
+	RolesClaim string
+	// Leeway allows for clock skew when checking exp and nbf
+	Leeway time.Duration
+
+	now func() time.Time
+}
+
+// NewVerifierFromConfig builds the token verifier, or returns nil when no JWKS file is configured
+func NewVerifierFromConfig(config util.Config) (*Verifier, error) {
+	if config.JWTJWKSFile == "" {
+		return nil, nil
+	}
+
+	keys, err := LoadKeySet(config.JWTJWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load JWT_JWKS_FILE: %w", err)
+	}
+
+	return &Verifier{
+		Keys:       keys,
+		Issuer:     config.JWTIssuer,
+		Audience:   config.JWTAudience,
+		RolesClaim: config.JWTRolesClaim,
+		Leeway:     config.JWTLeeway,
+	}, nil
+}
+
+// Verify checks a compact-serialized token and returns its claims
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if header.Algorithm != AlgorithmHS256 && header.Algorithm != AlgorithmRS256 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	key, err := v.Keys.lookup(header.KeyID, header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := verifySignature(key, header.Algorithm, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var payload map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	claims, err := v.parseClaims(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// verifySignature checks the signature of the signing input with the given key
+func verifySignature(key *Key, alg, signingInput string, signature []byte) error {
+	switch alg {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, key.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidSignature
+		}
+	case AlgorithmRS256:
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key.public, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrInvalidSignature
+		}
+	}
+
+	return nil
+}
+
+// parseClaims reads the registered claims and roles from a token payload
+func (v *Verifier) parseClaims(payload map[string]json.RawMessage) (*Claims, error) {
+	claims := &Claims{}
+
+	fields := []struct {
+		name  string
+		value interface{}
+	}{
+		{"sub", &claims.Subject},
+		{"iss", &claims.Issuer},
+	}
+	for _, field := range fields {
+		if raw, ok := payload[field.name]; ok {
+			if err := json.Unmarshal(raw, field.value); err != nil {
+				return nil, ErrMalformedToken
+			}
+		}
+	}
+
+	var err error
+	if claims.Audience, err = stringOrList(payload["aud"]); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if claims.ExpiresAt, err = numericDate(payload["exp"]); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if claims.NotBefore, err = numericDate(payload["nbf"]); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	rolesClaim := v.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+	if claims.Roles, err = stringOrList(payload[rolesClaim]); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	return claims, nil
+}
+
+// validate checks the time, issuer and audience claims
+func (v *Verifier) validate(claims *Claims) error {
+	now := time.Now()
+	if v.now != nil {
+		now = v.now()
+	}
+
+	if claims.ExpiresAt.IsZero() {
+		return ErrMissingExpiry
+	}
+	if !now.Before(claims.ExpiresAt.Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.Leeway).Before(claims.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.Audience != "" && !slices.Contains(claims.Audience, v.Audience) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringOrList decodes a claim that may be a single string or a list of strings
+func stringOrList(raw json.RawMessage) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err != nil {
+		return nil, err
+	}
+	return []string{single}, nil
+}
+
+// numericDate decodes a claim holding seconds since the epoch
+func numericDate(raw json.RawMessage) (time.Time, error) {
+	if raw == nil {
+		return time.Time{}, nil
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
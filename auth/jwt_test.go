@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testNow    = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+)
+
+// sign builds a compact token with the given header and payload
+func sign(t *testing.T, header, payload map[string]interface{}, secret []byte, private *rsa.PrivateKey) string {
+	t.Helper()
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	input := encode(header) + "." + encode(payload)
+
+	var signature []byte
+	if private != nil {
+		digest := sha256.Sum256([]byte(input))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	} else {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testVerifier(t *testing.T, public *rsa.PublicKey) *Verifier {
+	t.Helper()
+
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "oct", "kid": "hmac-1", "alg": "HS256", "k": %q},
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": %q, "e": %q},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"}
+	]}`,
+		base64.RawURLEncoding.EncodeToString(testSecret),
+		base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+	)
+
+	keys, err := ParseKeySet([]byte(jwks))
+	require.NoError(t, err)
+
+	return &Verifier{
+		Keys:     keys,
+		Issuer:   "https://login.example.com",
+		Audience: "pharmacy-claims",
+		Leeway:   time.Minute,
+		now:      func() time.Time { return testNow },
+	}
+}
+
+func validPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":   "jane.doe",
+		"iss":   "https://login.example.com",
+		"aud":   []string{"pharmacy-claims", "other"},
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"nbf":   testNow.Add(-time.Minute).Unix(),
+		"roles": []string{RoleSupport, RoleFinance},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	verifier := testVerifier(t, &private.PublicKey)
+
+	t.Run("RS256", func(t *testing.T) {
+		token := sign(t, map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, validPayload(), nil, private)
+
+		claims, err := verifier.Verify(token)
+		require.NoError(t, err)
+		require.Equal(t, "jane.doe", claims.Subject)
+		require.Equal(t, []string{RoleSupport, RoleFinance}, claims.Roles)
+		require.True(t, testNow.Add(time.Hour).Equal(claims.ExpiresAt))
+	})
+
+	t.Run("HS256 without key ID", func(t *testing.T) {
+		payload := validPayload()
+		payload["aud"] = "pharmacy-claims"
+		payload["roles"] = RoleAdmin
+		token := sign(t, map[string]interface{}{"alg": "HS256"}, payload, testSecret, nil)
+
+		claims, err := verifier.Verify(token)
+		require.NoError(t, err)
+		require.Equal(t, []string{RoleAdmin}, claims.Roles)
+	})
+
+	testCases := []struct {
+		name   string
+		token  func() string
+		expect error
+	}{
+		{
+			name: "algorithm none",
+			token: func() string {
+				return sign(t, map[string]interface{}{"alg": "none"}, validPayload(), testSecret, nil)
+			},
+			expect: ErrUnsupportedAlgorithm,
+		},
+		{
+			name: "HS256 signed with the RSA key ID",
+			token: func() string {
+				return sign(t, map[string]interface{}{"alg": "HS256", "kid": "rsa-1"}, validPayload(), testSecret, nil)
+			},
+			expect: ErrUnknownKey,
+		},
+		{
+			name: "wrong secret",
+			token: func() string {
+				return sign(t, map[string]interface{}{"alg": "HS256", "kid": "hmac-1"}, validPayload(), []byte("not the secret"), nil)
+			},
+			expect: ErrInvalidSignature,
+		},
+		{
+			name: "tampered payload",
+			token: func() string {
+				token := sign(t, map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, validPayload(), nil, private)
+				parts := strings.Split(token, ".")
+				payload := validPayload()
+				payload["roles"] = []string{RoleAdmin}
+				data, _ := json.Marshal(payload)
+				parts[1] = base64.RawURLEncoding.EncodeToString(data)
+				return strings.Join(parts, ".")
+			},
+			expect: ErrInvalidSignature,
+		},
+		{
+			name: "expired beyond leeway",
+			token: func() string {
+				payload := validPayload()
+				payload["exp"] = testNow.Add(-2 * time.Minute).Unix()
+				return sign(t, map[string]interface{}{"alg": "HS256"}, payload, testSecret, nil)
+			},
+			expect: ErrTokenExpired,
+		},
+		{
+			name: "no expiry",
+			token: func() string {
+				payload := validPayload()
+				delete(payload, "exp")
+				return sign(t, map[string]interface{}{"alg": "HS256"}, payload, testSecret, nil)
+			},
+			expect: ErrMissingExpiry,
+		},
+		{
+			name: "not yet valid",
+			token: func() string {
+				payload := validPayload()
+				payload["nbf"] = testNow.Add(time.Hour).Unix()
+				return sign(t, map[string]interface{}{"alg": "HS256"}, payload, testSecret, nil)
+			},
+			expect: ErrTokenNotYetValid,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				payload := validPayload()
+				payload["iss"] = "https://attacker.example.com"
+				return sign(t, map[string]interface{}{"alg": "HS256"}, payload, testSecret, nil)
+			},
+			expect: ErrInvalidIssuer,
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				payload := validPayload()
+				payload["aud"] = "another-service"
+				return sign(t, map[string]interface{}{"alg": "HS256"}, payload, testSecret, nil)
+			},
+			expect: ErrInvalidAudience,
+		},
+		{
+			name:   "not a token",
+			token:  func() string { return "abc.def" },
+			expect: ErrMalformedToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := verifier.Verify(tc.token())
+			require.ErrorIs(t, err, tc.expect)
+		})
+	}
+}
+
+func TestParseKeySetRejectsBadKeys(t *testing.T) {
+	_, err := ParseKeySet([]byte(`{"keys": []}`))
+	require.Error(t, err)
+
+	_, err = ParseKeySet([]byte(`{"keys": [{"kty": "EC", "crv": "P-256"}]}`))
+	require.Error(t, err)
+
+	_, err = ParseKeySet([]byte(`{"keys": [{"kty": "oct", "k": ""}]}`))
+	require.Error(t, err)
+}
+
+func TestPermissionsFor(t *testing.T) {
+	permissions := PermissionsFor([]string{RoleSupport, "unknown"})
+	require.True(t, permissions[PermissionReadClaims])
+	require.True(t, permissions[PermissionReverseClaims])
+	require.False(t, permissions[PermissionViewReports])
+
+	permissions = PermissionsFor([]string{RoleFinance})
+	require.True(t, permissions[PermissionViewReports])
+	require.True(t, permissions[PermissionRunSettlements])
+	require.False(t, permissions[PermissionReverseClaims])
+}
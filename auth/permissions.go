@@ -0,0 +1,48 @@
+package auth
+
+// Permission is a right checked before a route is served
+type Permission string
+
+const (
+	PermissionReadClaims       Permission = "claims:read"
+	PermissionSubmitClaims     Permission = "claims:submit"
+	PermissionReverseClaims    Permission = "claims:reverse"
+	PermissionManagePharmacies Permission = "pharmacies:manage"
+	PermissionManageReference  Permission = "reference:manage"
+	PermissionViewReports      Permission = "reports:view"
+	PermissionRunSettlements   Permission = "settlements:run"
+)
+
+const (
+	RoleSupport = "support"
+	RoleFinance = "finance"
+	// RoleAdmin may call every route, including the ones no permission covers
+	RoleAdmin = "admin"
+)
+
+// RolePermissions maps each role to the permissions it grants. Unknown roles grant nothing.
+var RolePermissions = map[string][]Permission{
+	RoleSupport: {
+		PermissionReadClaims,
+		PermissionSubmitClaims,
+		PermissionReverseClaims,
+		PermissionManagePharmacies,
+	},
+	RoleFinance: {
+		PermissionReadClaims,
+		PermissionManageReference,
+		PermissionViewReports,
+		PermissionRunSettlements,
+	},
+}
+
+// PermissionsFor returns the union of the permissions granted by the given roles
+func PermissionsFor(roles []string) map[Permission]bool {
+	permissions := make(map[Permission]bool)
+	for _, role := range roles {
+		for _, permission := range RolePermissions[role] {
+			permissions[permission] = true
+		}
+	}
+	return permissions
+}
@@ -0,0 +1,160 @@
+// Package certs serves the server's TLS certificate and client CA bundle from files and reloads
+// them when the files change, so certificates can be rotated without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader holds the current certificate and client CA pool loaded from files
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	clientAuth tls.ClientAuthType
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamp     string
+}
+
+// NewReloader loads the certificate, its key and, if caFile is set, the client CA bundle. Client
+// certificates are verified when presented, and required when requireClientCert is set.
+func NewReloader(certFile, keyFile, caFile string, requireClientCert bool) (*Reloader, error) {
+	r := &Reloader{
+		certFile:   certFile,
+		keyFile:    keyFile,
+		caFile:     caFile,
+		clientAuth: tls.NoClientCert,
+	}
+
+	if caFile != "" {
+		r.clientAuth = tls.VerifyClientCertIfGiven
+		if requireClientCert {
+			r.clientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if requireClientCert {
+		return nil, errors.New("a client CA bundle is required to verify client certificates")
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the files again if any of them changed since the last load. On error the
+// previous certificate stays in use.
+func (r *Reloader) Reload() (bool, error) {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := stamp == r.stamp
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("cannot load certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return false, fmt.Errorf("cannot read client CA bundle: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return false, errors.New("client CA bundle contains no certificates")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.stamp = stamp
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// Watch checks the files for changes every interval. It never returns.
+func (r *Reloader) Watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		reloaded, err := r.Reload()
+		if err != nil {
+			log.Printf("Warning: failed to reload TLS certificates: %v", err)
+			continue
+		}
+		if reloaded {
+			log.Printf("Reloaded TLS certificates from %s", r.certFile)
+		}
+	}
+}
+
+// TLSConfig returns a server configuration that always uses the latest certificate and client
+// CA bundle
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetCertificate:     r.getCertificate,
+		GetConfigForClient: r.configForClient,
+	}
+}
+
+// getCertificate returns the current certificate
+func (r *Reloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// configForClient builds the configuration of one handshake from the current files
+func (r *Reloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		ClientAuth:   r.clientAuth,
+		ClientCAs:    r.clientCAs,
+	}, nil
+}
+
+// fileStamp summarises the modification times and sizes of the watched files
+func (r *Reloader) fileStamp() (string, error) {
+	var stamp string
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp, nil
+}
@@ -0,0 +1,196 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// issued is a certificate with its key, in PEM and parsed forms
+type issued struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// issue creates a certificate for commonName, self-signed when parent is nil
+func issue(t *testing.T, commonName string, parent *issued, isCA bool) issued {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		DNSNames:              []string{"localhost"},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return issued{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// writeFile writes data and moves the modification time forward so the change is noticed
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+// handshake connects to a TLS server using config and returns the server's certificate name,
+// the client certificate name the server saw, and the server's handshake error
+func handshake(t *testing.T, config *tls.Config, client *issued, roots *x509.CertPool) (string, string, error) {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	type result struct {
+		peer string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer conn.Close()
+
+		tlsConn := conn.(*tls.Conn)
+		err = tlsConn.Handshake()
+		var peer string
+		if state := tlsConn.ConnectionState(); len(state.PeerCertificates) > 0 {
+			peer = state.PeerCertificates[0].Subject.CommonName
+		}
+		done <- result{peer: peer, err: err}
+	}()
+
+	clientConfig := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if client != nil {
+		clientConfig.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{client.cert.Raw},
+			PrivateKey:  client.key,
+		}}
+	}
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	var serverName string
+	if err == nil {
+		serverName = conn.ConnectionState().PeerCertificates[0].Subject.CommonName
+		conn.Close()
+	}
+
+	server := <-done
+	return serverName, server.peer, server.err
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "clients.pem")
+
+	ca := issue(t, "Test CA", nil, true)
+	first := issue(t, "server-a", &ca, false)
+	second := issue(t, "server-b", &ca, false)
+	client := issue(t, "pos-0042", &ca, false)
+	stranger := issue(t, "stranger", nil, false)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	modTime := time.Now().Add(-time.Hour)
+	writeFile(t, certFile, first.certPEM, modTime)
+	writeFile(t, keyFile, first.keyPEM, modTime)
+	writeFile(t, caFile, ca.certPEM, modTime)
+
+	reloader, err := NewReloader(certFile, keyFile, caFile, true)
+	require.NoError(t, err)
+	config := reloader.TLSConfig()
+
+	serverName, peer, err := handshake(t, config, &client, roots)
+	require.NoError(t, err)
+	require.Equal(t, "server-a", serverName)
+	require.Equal(t, "pos-0042", peer)
+
+	// Client certificates are required and must chain to the CA bundle
+	_, _, err = handshake(t, config, nil, roots)
+	require.Error(t, err)
+	_, _, err = handshake(t, config, &stranger, roots)
+	require.Error(t, err)
+
+	// Nothing changed, so nothing is reloaded
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	// A rotated certificate is served without rebuilding the configuration
+	modTime = modTime.Add(time.Minute)
+	writeFile(t, certFile, second.certPEM, modTime)
+	writeFile(t, keyFile, second.keyPEM, modTime)
+
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	serverName, _, err = handshake(t, config, &client, roots)
+	require.NoError(t, err)
+	require.Equal(t, "server-b", serverName)
+
+	// A broken certificate keeps the previous one in use
+	modTime = modTime.Add(time.Minute)
+	writeFile(t, certFile, []byte("not a certificate"), modTime)
+
+	_, err = reloader.Reload()
+	require.Error(t, err)
+
+	serverName, _, err = handshake(t, config, &client, roots)
+	require.NoError(t, err)
+	require.Equal(t, "server-b", serverName)
+}
+
+func TestReloaderRequiresCAForClientCertificates(t *testing.T) {
+	_, err := NewReloader("server.crt", "server.key", "", true)
+	require.Error(t, err)
+}
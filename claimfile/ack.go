@@ -0,0 +1,81 @@
+package claimfile
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// AckResult is the outcome of one claim record
+type AckResult struct {
+	Line     int
+	Sequence string
+	// Accepted is set when the claim was stored; Status and RejectCodes are its adjudication
+	Accepted    bool
+	ClaimID     string
+	Status      string
+	RejectCodes []string
+	// Reason and Message explain why a record was not stored
+	Reason  string
+	Message string
+}
+
+// Ack is the acknowledgement of a claim file
+type Ack struct {
+	Header    Header
+	Processed time.Time
+	Results   []AckResult
+}
+
+// Accepted counts the stored records
+func (a Ack) Accepted() int {
+	accepted := 0
+	for _, result := range a.Results {
+		if result.Accepted {
+			accepted++
+		}
+	}
+	return accepted
+}
+
+// WriteAck writes the acknowledgement as pipe-delimited records:
+//
+//	AH|<sender id>|<batch id>|<processed YYYYMMDDhhmmss>|<total>|<accepted>|<rejected>
+//	AD|<sequence>|<line>|A|<claim id>|<adjudication status>|<reject codes, comma separated>
+//	AD|<sequence>|<line>|R|<reason>|<message>
+//	AT|<detail record count>
+func WriteAck(w io.Writer, ack Ack) error {
+	accepted := ack.Accepted()
+
+	lines := []string{
+		join("AH", ack.Header.SenderID, ack.Header.BatchID, ack.Processed.UTC().Format("20060102150405"),
+			fmt.Sprint(len(ack.Results)), fmt.Sprint(accepted), fmt.Sprint(len(ack.Results)-accepted)),
+	}
+
+	for _, result := range ack.Results {
+		if result.Accepted {
+			lines = append(lines, join("AD", result.Sequence, fmt.Sprint(result.Line), "A", result.ClaimID, result.Status, strings.Join(result.RejectCodes, ",")))
+		} else {
+			lines = append(lines, join("AD", result.Sequence, fmt.Sprint(result.Line), "R", result.Reason, result.Message))
+		}
+	}
+
+	lines = append(lines, join("AT", fmt.Sprint(len(ack.Results))))
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// join builds a pipe-delimited record, keeping pipes out of the values
+func join(values ...string) string {
+	for i, value := range values {
+		values[i] = strings.ReplaceAll(value, "|", "/")
+	}
+	return strings.Join(values, "|")
+}
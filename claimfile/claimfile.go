@@ -0,0 +1,301 @@
+// Package claimfile reads claim batch files sent by partner pharmacies and writes the
+// acknowledgement returned for them.
+//
+// A file is a header record, any number of claim records and a trailer record, one per line.
+// Records are either pipe-delimited or fixed-width; the format is detected from the header,
+// which is pipe-delimited when it contains a "|".
+//
+// Pipe-delimited:
+//
+//	HD|<sender id>|<batch id>|<created YYYYMMDD>
+//	CL|<sequence>|<npi>|<ndc>|<quantity>|<price>
+//	TR|<claim record count>
+//
+// Fixed-width, with 1-based inclusive columns. Text fields are space padded, numeric fields
+// zero padded, and the price carries two implied decimals:
+//
+//	HD  1-2 "HD", 3-17 sender id, 18-27 batch id, 28-35 created YYYYMMDD
+//	CL  1-2 "CL", 3-8 sequence, 9-18 npi, 19-29 ndc, 30-39 quantity, 40-49 price in cents
+//	TR  1-2 "TR", 3-11 claim record count
+//
+// Blank lines are ignored. A file without a header or trailer, or whose trailer count does not
+// match, is refused as a whole; a claim record that cannot be read is rejected on its own.
+package claimfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// Record types
+const (
+	recordHeader  = "HD"
+	recordClaim   = "CL"
+	recordTrailer = "TR"
+)
+
+// dateLayout is the layout of dates in claim files
+const dateLayout = "20060102"
+
+// Format is the record layout of a claim file
+type Format string
+
+const (
+	FormatPipe  Format = "pipe"
+	FormatFixed Format = "fixed"
+)
+
+var (
+	// ErrMissingHeader is returned when the first record of a file is not a header
+	ErrMissingHeader = errors.New("file does not start with a header record")
+	// ErrMissingTrailer is returned when a file ends without a trailer record
+	ErrMissingTrailer = errors.New("file does not end with a trailer record")
+	// ErrTooManyRecords is returned when a file holds more claim records than allowed
+	ErrTooManyRecords = errors.New("file exceeds the maximum number of claim records")
+	// ErrLineTooLong is returned for a line longer than MaxLineLength
+	ErrLineTooLong = fmt.Errorf("line exceeds %d bytes", MaxLineLength)
+)
+
+// MaxLineLength is the longest line a claim file may contain
+const MaxLineLength = bufio.MaxScanTokenSize
+
+// field is a fixed-width column range, 1-based and inclusive
+type field struct {
+	start, end int
+}
+
+var (
+	fixedHeader = []field{{3, 17}, {18, 27}, {28, 35}}
+	fixedClaim  = []field{{3, 8}, {9, 18}, {19, 29}, {30, 39}, {40, 49}}
+	fixedTrail  = []field{{3, 11}}
+)
+
+// Header identifies the sender and batch of a file
+type Header struct {
+	SenderID string
+	BatchID  string
+	Created  time.Time
+}
+
+// Record is one claim record of a file
+type Record struct {
+	// Line is the line number of the record in the file
+	Line     int
+	Sequence string
+	Claim    sqlc.CreateClaimParams
+	// Err is set when the record could not be read
+	Err *RecordError
+}
+
+// RecordError describes a claim record that could not be read
+type RecordError struct {
+	Field   string
+	Message string
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// File is a parsed claim file
+type File struct {
+	Format  Format
+	Header  Header
+	Records []Record
+}
+
+// LineError is returned for a structural problem at a given line
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Parse reads a claim file. maxRecords limits the number of claim records; zero means no limit.
+func Parse(r io.Reader, maxRecords int) (*File, error) {
+	file := &File{}
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+	headerSeen := false
+	trailerSeen := false
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if trailerSeen {
+			return nil, &LineError{Line: line, Err: errors.New("record after the trailer")}
+		}
+
+		if !headerSeen {
+			if !strings.HasPrefix(text, recordHeader) {
+				return nil, &LineError{Line: line, Err: ErrMissingHeader}
+			}
+			file.Format = FormatFixed
+			if strings.Contains(text, "|") {
+				file.Format = FormatPipe
+			}
+
+			header, err := file.parseHeader(text)
+			if err != nil {
+				return nil, &LineError{Line: line, Err: err}
+			}
+			file.Header = header
+			headerSeen = true
+			continue
+		}
+
+		switch recordType(text) {
+		case recordClaim:
+			if maxRecords > 0 && len(file.Records) == maxRecords {
+				return nil, ErrTooManyRecords
+			}
+			file.Records = append(file.Records, file.parseClaim(line, text))
+
+		case recordTrailer:
+			values, err := file.split(text, fixedTrail, 2)
+			if err != nil {
+				return nil, &LineError{Line: line, Err: err}
+			}
+			count, err := strconv.Atoi(values[0])
+			if err != nil {
+				return nil, &LineError{Line: line, Err: fmt.Errorf("invalid trailer count %q", values[0])}
+			}
+			if count != len(file.Records) {
+				return nil, &LineError{Line: line, Err: fmt.Errorf("trailer count %d does not match %d claim records", count, len(file.Records))}
+			}
+			trailerSeen = true
+
+		default:
+			return nil, &LineError{Line: line, Err: fmt.Errorf("unknown record type %q", recordType(text))}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		// The scanner stops at the line that did not fit
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &LineError{Line: line + 1, Err: ErrLineTooLong}
+		}
+		return nil, err
+	}
+
+	if !headerSeen {
+		return nil, ErrMissingHeader
+	}
+	if !trailerSeen {
+		return nil, ErrMissingTrailer
+	}
+
+	return file, nil
+}
+
+// parseHeader reads the header record
+func (f *File) parseHeader(text string) (Header, error) {
+	values, err := f.split(text, fixedHeader, 4)
+	if err != nil {
+		return Header{}, err
+	}
+
+	created, err := time.Parse(dateLayout, values[2])
+	if err != nil {
+		return Header{}, fmt.Errorf("invalid creation date %q", values[2])
+	}
+
+	return Header{
+		SenderID: values[0],
+		BatchID:  values[1],
+		Created:  created,
+	}, nil
+}
+
+// parseClaim maps a claim record to the parameters of a claim submission
+func (f *File) parseClaim(line int, text string) Record {
+	record := Record{Line: line}
+
+	values, err := f.split(text, fixedClaim, 6)
+	if err != nil {
+		record.Err = &RecordError{Field: "record", Message: err.Error()}
+		return record
+	}
+
+	record.Sequence = values[0]
+	record.Claim.NPI = values[1]
+	record.Claim.NDC = values[2]
+
+	quantity, err := strconv.ParseInt(values[3], 10, 64)
+	if err != nil {
+		record.Err = &RecordError{Field: "quantity", Message: fmt.Sprintf("%q is not a whole number", values[3])}
+		return record
+	}
+	record.Claim.Quantity = quantity
+
+	if f.Format == FormatFixed {
+		cents, err := strconv.ParseInt(values[4], 10, 64)
+		if err != nil {
+			record.Err = &RecordError{Field: "price", Message: fmt.Sprintf("%q is not a whole number of cents", values[4])}
+			return record
+		}
+		record.Claim.Price = float64(cents) / 100
+	} else {
+		price, err := strconv.ParseFloat(values[4], 64)
+		if err != nil {
+			record.Err = &RecordError{Field: "price", Message: fmt.Sprintf("%q is not a number", values[4])}
+			return record
+		}
+		record.Claim.Price = price
+	}
+
+	return record
+}
+
+// split returns the trimmed fields of a record after its type, either by pipe or by column
+func (f *File) split(text string, columns []field, pipeFields int) ([]string, error) {
+	var values []string
+
+	if f.Format == FormatPipe {
+		values = strings.Split(text, "|")
+		if len(values) != pipeFields {
+			return nil, fmt.Errorf("expected %d fields, got %d", pipeFields, len(values))
+		}
+		values = values[1:]
+	} else {
+		last := columns[len(columns)-1].end
+		if len(text) < last {
+			return nil, fmt.Errorf("record is %d characters, expected %d", len(text), last)
+		}
+		for _, column := range columns {
+			values = append(values, text[column.start-1:column.end])
+		}
+	}
+
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values, nil
+}
+
+// recordType returns the two-character record type
+func recordType(text string) string {
+	if len(text) < 2 {
+		return text
+	}
+	return text[:2]
+}
@@ -0,0 +1,108 @@
+package claimfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipe(t *testing.T) {
+	input := strings.Join([]string{
+		"HD|ACME|B0001|20250307",
+		"CL|000001|1234567890|00002323401|30|15.99",
+		"",
+		"CL|000002|1234567890|00002323401|thirty|15.99",
+		"TR|2",
+	}, "\r\n")
+
+	file, err := Parse(strings.NewReader(input), 0)
+	require.NoError(t, err)
+	require.Equal(t, FormatPipe, file.Format)
+	require.Equal(t, Header{SenderID: "ACME", BatchID: "B0001", Created: time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)}, file.Header)
+	require.Len(t, file.Records, 2)
+
+	require.Nil(t, file.Records[0].Err)
+	require.Equal(t, "000001", file.Records[0].Sequence)
+	require.Equal(t, sqlc.CreateClaimParams{NPI: "1234567890", NDC: "00002323401", Quantity: 30, Price: 15.99}, file.Records[0].Claim)
+
+	require.Equal(t, 4, file.Records[1].Line)
+	require.Equal(t, "quantity", file.Records[1].Err.Field)
+}
+
+func TestParseFixed(t *testing.T) {
+	input := strings.Join([]string{
+		"HDACME           B0001     20250307",
+		"CL00000112345678900000232340100000000300000001599",
+		"CL00000212345678900000232340100000000900000000",
+		"TR000000002",
+	}, "\n")
+
+	file, err := Parse(strings.NewReader(input), 0)
+	require.NoError(t, err)
+	require.Equal(t, FormatFixed, file.Format)
+	require.Equal(t, "ACME", file.Header.SenderID)
+	require.Equal(t, "B0001", file.Header.BatchID)
+
+	require.Nil(t, file.Records[0].Err)
+	require.Equal(t, sqlc.CreateClaimParams{NPI: "1234567890", NDC: "00002323401", Quantity: 30, Price: 15.99}, file.Records[0].Claim)
+
+	// A short record is rejected on its own
+	require.Equal(t, "record", file.Records[1].Err.Field)
+}
+
+func TestParseStructuralErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{name: "missing header", input: "CL|1|1234567890|00002323401|30|15.99\nTR|1", err: ErrMissingHeader},
+		{name: "missing trailer", input: "HD|ACME|B0001|20250307\nCL|1|1234567890|00002323401|30|15.99", err: ErrMissingTrailer},
+		{name: "too many records", input: "HD|ACME|B0001|20250307\nCL|1|1|1|1|1\nCL|2|1|1|1|1\nTR|2", err: ErrTooManyRecords},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.input), 1)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	// A trailer count that does not match refuses the file
+	_, err := Parse(strings.NewReader("HD|ACME|B0001|20250307\nCL|1|1234567890|00002323401|30|15.99\nTR|3"), 0)
+	var lineErr *LineError
+	require.True(t, errors.As(err, &lineErr))
+	require.Equal(t, 3, lineErr.Line)
+
+	// A line too long to read is reported with its number instead of failing the read
+	long := "HD|ACME|B0001|20250307\nCL|1|1234567890|00002323401|30|" + strings.Repeat("9", MaxLineLength) + "\nTR|1"
+	_, err = Parse(strings.NewReader(long), 0)
+	require.ErrorIs(t, err, ErrLineTooLong)
+	require.True(t, errors.As(err, &lineErr))
+	require.Equal(t, 2, lineErr.Line)
+}
+
+func TestWriteAck(t *testing.T) {
+	ack := Ack{
+		Header:    Header{SenderID: "ACME", BatchID: "B0001"},
+		Processed: time.Date(2025, 3, 7, 12, 30, 0, 0, time.UTC),
+		Results: []AckResult{
+			{Line: 2, Sequence: "000001", Accepted: true, ClaimID: "abc", Status: "rejected", RejectCodes: []string{"76", "79"}},
+			{Line: 3, Sequence: "000002", Reason: "missing_ndc", Message: "NDC is required | empty"},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, WriteAck(&out, ack))
+	require.Equal(t, strings.Join([]string{
+		"AH|ACME|B0001|20250307123000|2|1|1",
+		"AD|000001|2|A|abc|rejected|76,79",
+		"AD|000002|3|R|missing_ndc|NDC is required / empty",
+		"AT|2",
+		"",
+	}, "\n"), out.String())
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"fmt"
+	"math"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// Kinds of rows recorded in the reversals table
+const (
+	// ReversalKindReversal returns quantity or refunds money against a claim
+	ReversalKindReversal = "reversal"
+	// ReversalKindAdjustment adds quantity or money to a claim
+	ReversalKindAdjustment = "adjustment"
+)
+
+// ClaimBalance is the net position of a claim after its reversals and adjustments
+type ClaimBalance struct {
+	ReversedQuantity int64   `json:"reversed_quantity"`
+	ReversedAmount   float64 `json:"reversed_amount"`
+	AdjustedQuantity int64   `json:"adjusted_quantity"`
+	AdjustedAmount   float64 `json:"adjusted_amount"`
+	NetQuantity      int64   `json:"net_quantity"`
+	NetAmount        float64 `json:"net_amount"`
+}
+
+// NewClaimBalance computes the net balance of a claim from its reversal history
+func NewClaimBalance(claim sqlc.Claim, reversals []sqlc.Reversal) ClaimBalance {
+	var balance ClaimBalance
+
+	for _, reversal := range reversals {
+		switch reversal.Kind {
+		case ReversalKindAdjustment:
+			balance.AdjustedQuantity += reversal.Quantity
+			balance.AdjustedAmount += reversal.Amount
+		default:
+			balance.ReversedQuantity += reversal.Quantity
+			balance.ReversedAmount += reversal.Amount
+		}
+	}
+
+	balance.ReversedAmount = roundCents(balance.ReversedAmount)
+	balance.AdjustedAmount = roundCents(balance.AdjustedAmount)
+	balance.NetQuantity = claim.Quantity + balance.AdjustedQuantity - balance.ReversedQuantity
+	balance.NetAmount = roundCents(claim.Price + balance.AdjustedAmount - balance.ReversedAmount)
+
+	return balance
+}
+
+// FullyReversed reports whether nothing remains of the claim to reverse
+func (b ClaimBalance) FullyReversed() bool {
+	return b.NetQuantity <= 0 && b.NetAmount <= 0
+}
+
+// ReversalExceedsBalanceError is returned when a partial reversal would take a claim below zero
+type ReversalExceedsBalanceError struct {
+	Balance ClaimBalance
+}
+
+func (e *ReversalExceedsBalanceError) Error() string {
+	return fmt.Sprintf("reversal exceeds remaining claim balance (quantity %d, amount %.2f)", e.Balance.NetQuantity, e.Balance.NetAmount)
+}
+
+// roundCents rounds a monetary amount to two decimal places
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
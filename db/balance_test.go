@@ -0,0 +1,33 @@
+package db
+
+import (
+	"testing"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClaimBalance(t *testing.T) {
+	claim := sqlc.Claim{Quantity: 30, Price: 100}
+
+	balance := NewClaimBalance(claim, nil)
+	require.Equal(t, int64(30), balance.NetQuantity)
+	require.Equal(t, 100.0, balance.NetAmount)
+	require.False(t, balance.FullyReversed())
+
+	balance = NewClaimBalance(claim, []sqlc.Reversal{
+		{Kind: ReversalKindReversal, Quantity: 10, Amount: 33.33},
+		{Kind: ReversalKindAdjustment, Amount: 5.1},
+		{Kind: ReversalKindReversal, Amount: 0.1},
+	})
+	require.Equal(t, int64(10), balance.ReversedQuantity)
+	require.Equal(t, 33.43, balance.ReversedAmount)
+	require.Equal(t, 5.1, balance.AdjustedAmount)
+	require.Equal(t, int64(20), balance.NetQuantity)
+	require.Equal(t, 71.67, balance.NetAmount)
+
+	balance = NewClaimBalance(claim, []sqlc.Reversal{
+		{Kind: ReversalKindReversal, Quantity: 30, Amount: 100},
+	})
+	require.True(t, balance.FullyReversed())
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/adjudication"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/pricing"
+)
+
+var (
+	// ErrPharmacyNotFound is returned when a claim is submitted for an NPI that is not on file
+	ErrPharmacyNotFound = errors.New("pharmacy not found")
+	// ErrUnknownNDC is returned in strict mode when a claim's NDC is not in the drugs table
+	ErrUnknownNDC = errors.New("ndc is not in the drug reference table")
+	// ErrInactiveNDC is returned in strict mode when a claim's NDC has been deactivated
+	ErrInactiveNDC = errors.New("ndc is inactive")
+)
+
+// DuplicatePolicy decides what happens to a claim that matches a recent, unreversed fill
+type DuplicatePolicy string
+
+const (
+	// DuplicatePolicyReject refuses the claim with a DuplicateClaimError
+	DuplicatePolicyReject DuplicatePolicy = "reject"
+	// DuplicatePolicyFlag accepts the claim and marks it as a possible duplicate
+	DuplicatePolicyFlag DuplicatePolicy = "flag"
+)
+
+// ParseDuplicatePolicy checks a configured duplicate claim policy
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	policy := DuplicatePolicy(s)
+	if policy != DuplicatePolicyFlag && policy != DuplicatePolicyReject {
+		return "", fmt.Errorf("unknown duplicate claim policy %q", s)
+	}
+	return policy, nil
+}
+
+// DuplicateClaimError is returned when a claim is rejected as a duplicate of an earlier one
+type DuplicateClaimError struct {
+	ClaimID uuid.UUID
+}
+
+func (e *DuplicateClaimError) Error() string {
+	return fmt.Sprintf("claim duplicates existing claim %s", e.ClaimID)
+}
+
+// CreateClaimTxParams contains the input parameters of a claim submission
+type CreateClaimTxParams struct {
+	sqlc.CreateClaimParams
+	// DuplicateWindow is how far back to look for an identical fill; zero disables the check
+	DuplicateWindow time.Duration
+	DuplicatePolicy DuplicatePolicy
+	// Adjudicator decides whether the claim is approved; nil approves every claim
+	Adjudicator *adjudication.Engine
+	// StrictNDC refuses claims whose NDC is unknown or inactive in the drugs table
+	StrictNDC bool
+	// Pricer computes the allowed amount from the reference price table; nil skips pricing
+	Pricer *pricing.Pricer
+}
+
+// CreateClaimTxResult is the result of a claim submission
+type CreateClaimTxResult struct {
+	Claim sqlc.Claim
+	// Pharmacy is the submitting pharmacy as it was when the claim was stored
+	Pharmacy sqlc.Pharmacy
+	// DuplicateOf is the earlier claim this one was flagged against, if any
+	DuplicateOf uuid.UUID
+	// Decision is the adjudication outcome stored with the claim
+	Decision adjudication.Decision
+	// Quote is how the allowed amount was derived; nil when the NDC has no reference price
+	Quote *pricing.Quote
+}
+
+// CreateClaimTx creates a new claim within a database transaction, applying duplicate detection
+func (store *SQLStore) CreateClaimTx(ctx context.Context, arg CreateClaimTxParams) (CreateClaimTxResult, error) {
+	var result CreateClaimTxResult
+
+	err := store.execTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+
+		result, err = createClaim(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// createClaim inserts a claim after checking for an identical, unreversed fill within the
+// duplicate window, pricing it against the reference price and adjudicating it. Submissions for the same NPI and NDC are serialized
+// so that concurrent duplicates or early refills cannot both pass the checks.
+func createClaim(ctx context.Context, q *sqlc.Queries, arg CreateClaimTxParams) (CreateClaimTxResult, error) {
+	var result CreateClaimTxResult
+
+	// Claims for unknown pharmacies cannot be stored, so they are refused rather than rejected
+	pharmacy, err := q.GetPharmacy(ctx, arg.NPI)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, ErrPharmacyNotFound
+		}
+		return result, err
+	}
+
+	if arg.StrictNDC {
+		drug, err := q.GetDrug(ctx, arg.NDC)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return result, ErrUnknownNDC
+		case err != nil:
+			return result, err
+		case !drug.Active:
+			return result, ErrInactiveNDC
+		}
+	}
+
+	err = q.LockClaimFill(ctx, sqlc.LockClaimFillParams{
+		NPI: arg.NPI,
+		NDC: arg.NDC,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Prior fills only count while some of their quantity remains after reversals and adjustments
+	if arg.DuplicateWindow > 0 {
+		prior, err := q.FindDuplicateClaim(ctx, sqlc.FindDuplicateClaimParams{
+			NPI:      arg.NPI,
+			NDC:      arg.NDC,
+			Quantity: arg.Quantity,
+			Since:    time.Now().Add(-arg.DuplicateWindow),
+		})
+		switch {
+		case err == nil:
+			if arg.DuplicatePolicy == DuplicatePolicyReject {
+				return result, &DuplicateClaimError{ClaimID: prior.ID}
+			}
+			arg.PossibleDuplicate = true
+			result.DuplicateOf = prior.ID
+		case !errors.Is(err, pgx.ErrNoRows):
+			return result, err
+		}
+	}
+
+	serviceDate := time.Now()
+
+	if arg.Pricer != nil {
+		quote, ok, err := arg.Pricer.Price(ctx, q, arg.NDC, pharmacy.Chain, arg.Quantity, serviceDate)
+		if err != nil {
+			return result, err
+		}
+		if ok {
+			result.Quote = &quote
+			arg.AllowedAmount = pgtype.Float8{Float64: quote.AllowedAmount, Valid: true}
+			arg.PriceExceedsAllowed = arg.Pricer.Exceeds(arg.Price, quote)
+		}
+	}
+
+	result.Decision = adjudication.Decision{Status: adjudication.StatusApproved}
+	if arg.Adjudicator != nil {
+		result.Decision, err = arg.Adjudicator.Adjudicate(ctx, q, adjudication.Claim{
+			NDC:         arg.NDC,
+			NPI:         arg.NPI,
+			Quantity:    arg.Quantity,
+			Price:       arg.Price,
+			ServiceDate: serviceDate,
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	if arg.PriceExceedsAllowed && arg.Pricer.Policy == pricing.PolicyReject {
+		result.Decision.Reject(adjudication.NewReject(adjudication.RejectCostExceedsMaximum))
+	}
+	arg.Status = result.Decision.Status
+	arg.RejectCodes = result.Decision.Codes()
+
+	claim, err := q.CreateClaim(ctx, arg.CreateClaimParams)
+	if err != nil {
+		return result, err
+	}
+
+	result.Claim = claim
+	result.Pharmacy = pharmacy
+	return result, nil
+}
+
+// CreateClaimBatchItem is the outcome of one claim in a batch submission
+type CreateClaimBatchItem struct {
+	CreateClaimTxResult
+	// Err is set when this claim was rejected; the rest of the batch is unaffected
+	Err error
+}
+
+// CreateClaimBatchTx creates many claims in a single transaction. Each claim runs in its own
+// savepoint, so a rejected claim is rolled back without aborting the others.
+func (store *SQLStore) CreateClaimBatchTx(ctx context.Context, args []CreateClaimTxParams) ([]CreateClaimBatchItem, error) {
+	items := make([]CreateClaimBatchItem, len(args))
+
+	tx, err := store.connPool.Begin(ctx)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	for i, arg := range args {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+
+		items[i].CreateClaimTxResult, items[i].Err = translate(createClaim(ctx, sqlc.New(savepoint), arg))
+		if items[i].Err != nil {
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, TranslateError(err)
+			}
+			continue
+		}
+
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, TranslateError(err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	policy, err := ParseDuplicatePolicy("reject")
+	require.NoError(t, err)
+	require.Equal(t, DuplicatePolicyReject, policy)
+
+	policy, err = ParseDuplicatePolicy("flag")
+	require.NoError(t, err)
+	require.Equal(t, DuplicatePolicyFlag, policy)
+
+	for _, value := range []string{"", "ignore", "Reject"} {
+		_, err := ParseDuplicatePolicy(value)
+		require.Error(t, err, value)
+	}
+}
+
+// Duplicate detection and the refill-too-soon check agree on which fills are live: a fill
+// whose reversals are offset by adjustments still counts for both
+func TestLiveFillNetsAdjustments(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	claim := createTestClaims(t, store, 1)[0]
+
+	reverse := func(kind, reasonCode string, quantity int64) {
+		_, err := store.CreateReversalTx(ctx, CreateReversalTxParams{
+			CreateReversalParams: sqlc.CreateReversalParams{
+				ClaimID:    claim.ID,
+				Kind:       kind,
+				Quantity:   quantity,
+				ReasonCode: reasonCode,
+				Actor:      "test",
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	live := func() bool {
+		fill, err := store.GetLastApprovedFill(ctx, sqlc.GetLastApprovedFillParams{NPI: claim.NPI, NDC: claim.NDC})
+		if err != nil {
+			require.ErrorIs(t, err, pgx.ErrNoRows)
+		} else {
+			require.Equal(t, claim.ID, fill.ID)
+		}
+
+		duplicate, dupErr := store.FindDuplicateClaim(ctx, sqlc.FindDuplicateClaimParams{
+			NPI:      claim.NPI,
+			NDC:      claim.NDC,
+			Quantity: claim.Quantity,
+			Since:    time.Now().Add(-time.Hour),
+		})
+		if dupErr != nil {
+			require.ErrorIs(t, dupErr, pgx.ErrNoRows)
+		} else {
+			require.Equal(t, claim.ID, duplicate.ID)
+		}
+
+		require.Equal(t, err == nil, dupErr == nil)
+		return err == nil
+	}
+
+	require.True(t, live())
+
+	// The adjusted quantity outlives a reversal of the original quantity
+	reverse(ReversalKindAdjustment, "PAYMENT_ADJUSTMENT", 10)
+	reverse(ReversalKindReversal, "BILLED_IN_ERROR", claim.Quantity)
+	require.True(t, live())
+
+	reverse(ReversalKindReversal, "BILLED_IN_ERROR", 10)
+	require.False(t, live())
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// ContractOverlapError is returned when a contract's effective dates overlap another contract of
+// the same chain
+type ContractOverlapError struct {
+	Contract sqlc.Contract
+}
+
+func (e *ContractOverlapError) Error() string {
+	return fmt.Sprintf("contract dates overlap contract %s of chain %s", e.Contract.ID, e.Contract.Chain)
+}
+
+// CreateContractTx adds pricing terms for a chain unless they overlap another of its contracts.
+// Writes to a chain's contracts are serialized, so two overlapping contracts cannot both be saved.
+func (store *SQLStore) CreateContractTx(ctx context.Context, arg sqlc.CreateContractParams) (sqlc.Contract, error) {
+	var contract sqlc.Contract
+
+	err := store.execTx(ctx, func(q *sqlc.Queries) error {
+		if err := checkContractOverlap(ctx, q, arg.Chain, uuid.Nil, arg.EffectiveFrom, arg.EffectiveTo); err != nil {
+			return err
+		}
+
+		var err error
+		contract, err = q.CreateContract(ctx, arg)
+		return err
+	})
+
+	return contract, err
+}
+
+// UpdateContractTx replaces a contract's terms and effective dates unless they overlap another
+// contract of its chain. It returns ErrNotFound when the contract does not exist.
+func (store *SQLStore) UpdateContractTx(ctx context.Context, arg sqlc.UpdateContractParams) (sqlc.Contract, error) {
+	var contract sqlc.Contract
+
+	err := store.execTx(ctx, func(q *sqlc.Queries) error {
+		existing, err := q.GetContract(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkContractOverlap(ctx, q, existing.Chain, arg.ID, arg.EffectiveFrom, arg.EffectiveTo); err != nil {
+			return err
+		}
+
+		contract, err = q.UpdateContract(ctx, arg)
+		return err
+	})
+
+	return contract, err
+}
+
+// checkContractOverlap locks the chain's contracts for the rest of the transaction and fails with
+// a ContractOverlapError if another of them overlaps the date range
+func checkContractOverlap(ctx context.Context, q *sqlc.Queries, chain string, excludeID uuid.UUID, from time.Time, to pgtype.Timestamptz) error {
+	if err := q.LockChainContracts(ctx, chain); err != nil {
+		return err
+	}
+
+	overlap, err := q.FindOverlappingContract(ctx, sqlc.FindOverlappingContractParams{
+		Chain:         chain,
+		ExcludeID:     excludeID,
+		EffectiveFrom: from,
+		EffectiveTo:   to,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return &ContractOverlapError{Contract: overlap}
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateContractTxConcurrentOverlap(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	// Each run uses a chain of its own, as contracts cannot be deleted
+	chain := "Test " + util.RandomString(8)
+
+	const n = 5
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = store.CreateContractTx(ctx, sqlc.CreateContractParams{
+				Chain:           chain,
+				DiscountPercent: 10,
+				DispensingFee:   1.5,
+				EffectiveFrom:   time.Date(2025, time.January, 1+i, 0, 0, 0, 0, time.UTC),
+			})
+		}()
+	}
+	wg.Wait()
+
+	// Every contract is open-ended, so only one of them can be saved
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorAs(t, err, new(*ContractOverlapError))
+	}
+	require.Equal(t, 1, created)
+
+	contracts, err := store.ListContracts(ctx, pgtype.Text{String: chain, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, contracts, 1)
+}
+
+func TestUpdateContractTx(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	chain := "Test " + util.RandomString(8)
+
+	first, err := store.CreateContractTx(ctx, sqlc.CreateContractParams{
+		Chain:         chain,
+		EffectiveFrom: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EffectiveTo:   pgtype.Timestamptz{Time: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	})
+	require.NoError(t, err)
+
+	second, err := store.CreateContractTx(ctx, sqlc.CreateContractParams{
+		Chain:         chain,
+		EffectiveFrom: time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	// Moving the second contract into the first one's range is refused
+	_, err = store.UpdateContractTx(ctx, sqlc.UpdateContractParams{
+		ID:            second.ID,
+		EffectiveFrom: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	})
+	var overlap *ContractOverlapError
+	require.ErrorAs(t, err, &overlap)
+	require.Equal(t, first.ID, overlap.Contract.ID)
+
+	// A contract never overlaps itself
+	updated, err := store.UpdateContractTx(ctx, sqlc.UpdateContractParams{
+		ID:            second.ID,
+		DispensingFee: 2,
+		EffectiveFrom: second.EffectiveFrom,
+	})
+	require.NoError(t, err)
+	require.Equal(t, float64(2), updated.DispensingFee)
+
+	_, err = store.UpdateContractTx(ctx, sqlc.UpdateContractParams{ID: uuid.New()})
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kinds of store errors. Every error returned by SQLStore that comes from the database matches
+// one of these with errors.Is, or none when the failure is unexpected.
+var (
+	ErrNotFound             = errors.New("record not found")
+	ErrForeignKeyViolation  = errors.New("foreign key violation")
+	ErrUniqueViolation      = errors.New("unique violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrTimeout              = errors.New("database timeout")
+	ErrUnavailable          = errors.New("database unavailable")
+)
+
+// PostgreSQL error codes translated by TranslateError
+const (
+	foreignKeyViolation  = "23503"
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	lockNotAvailable     = "55P03"
+	queryCanceled        = "57014"
+	adminShutdown        = "57P01"
+	cannotConnectNow     = "57P03"
+	// connectionException is the class of every connection error code
+	connectionException = "08"
+)
+
+// Error is a database error translated to one of the store error kinds. It still wraps the
+// driver error, so errors.Is(err, pgx.ErrNoRows) keeps working.
+type Error struct {
+	Kind error
+	// Constraint is the violated constraint, if any
+	Constraint string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Constraint != "" {
+		return fmt.Sprintf("%v (%s): %v", e.Kind, e.Constraint, e.Err)
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+// Is reports whether target is the kind of the error
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// TranslateError wraps a pgx or pgconn error in an Error of the matching kind. Other errors,
+// including nil and the sentinel errors of the transactions, are returned unchanged.
+func TranslateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var translated *Error
+	if errors.As(err, &translated) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == foreignKeyViolation:
+			return &Error{Kind: ErrForeignKeyViolation, Constraint: pgErr.ConstraintName, Err: err}
+		case pgErr.Code == uniqueViolation:
+			return &Error{Kind: ErrUniqueViolation, Constraint: pgErr.ConstraintName, Err: err}
+		case pgErr.Code == serializationFailure, pgErr.Code == deadlockDetected:
+			return &Error{Kind: ErrSerializationFailure, Err: err}
+		case pgErr.Code == queryCanceled, pgErr.Code == lockNotAvailable:
+			return &Error{Kind: ErrTimeout, Err: err}
+		case pgErr.Code == adminShutdown, pgErr.Code == cannotConnectNow, strings.HasPrefix(pgErr.Code, connectionException):
+			return &Error{Kind: ErrUnavailable, Err: err}
+		}
+		return err
+	}
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return &Error{Kind: ErrNotFound, Err: err}
+	case errors.Is(err, context.DeadlineExceeded), pgconn.Timeout(err):
+		return &Error{Kind: ErrTimeout, Err: err}
+	case errors.As(err, new(*pgconn.ConnectError)):
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+
+	return err
+}
+
+// translate passes the result of a query through and translates its error
+func translate[T any](result T, err error) (T, error) {
+	return result, TranslateError(err)
+}
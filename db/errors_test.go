@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"no rows", pgx.ErrNoRows, ErrNotFound},
+		{"wrapped no rows", fmt.Errorf("get claim: %w", pgx.ErrNoRows), ErrNotFound},
+		{"foreign key", &pgconn.PgError{Code: "23503", ConstraintName: "claims_npi_fkey"}, ErrForeignKeyViolation},
+		{"unique", &pgconn.PgError{Code: "23505", ConstraintName: "drugs_pkey"}, ErrUniqueViolation},
+		{"serialization", &pgconn.PgError{Code: "40001"}, ErrSerializationFailure},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, ErrSerializationFailure},
+		{"statement timeout", &pgconn.PgError{Code: "57014"}, ErrTimeout},
+		{"lock timeout", &pgconn.PgError{Code: "55P03"}, ErrTimeout},
+		{"deadline", context.DeadlineExceeded, ErrTimeout},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, ErrUnavailable},
+		{"shutdown", &pgconn.PgError{Code: "57P01"}, ErrUnavailable},
+		{"connect", &pgconn.ConnectError{}, ErrUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := TranslateError(tc.err)
+
+			var translated *Error
+			require.ErrorAs(t, err, &translated)
+			require.ErrorIs(t, err, tc.kind)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, TranslateError(err), err)
+		})
+	}
+
+	translated := TranslateError(&pgconn.PgError{Code: "23505", ConstraintName: "drugs_pkey"}).(*Error)
+	require.Equal(t, "drugs_pkey", translated.Constraint)
+	require.NotErrorIs(t, translated, ErrForeignKeyViolation)
+
+	// Errors that are not from the database are left alone
+	require.NoError(t, TranslateError(nil))
+	require.Equal(t, ErrClaimNotFound, TranslateError(ErrClaimNotFound))
+	other := &pgconn.PgError{Code: "22P02"}
+	require.Same(t, other, TranslateError(other))
+	require.False(t, errors.Is(TranslateError(context.Canceled), ErrTimeout))
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is replayed with a different request
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+// CreateClaimIdempotentTxParams contains the input parameters of an idempotent claim submission
+type CreateClaimIdempotentTxParams struct {
+	Claim       CreateClaimTxParams
+	Key         string
+	RequestHash string
+	ExpiresAt   time.Time
+	// BuildResponse renders the response stored with the key for the newly created claim
+	BuildResponse func(result CreateClaimTxResult) (status int32, body []byte, err error)
+}
+
+// CreateClaimIdempotentTxResult is the result of an idempotent claim submission
+type CreateClaimIdempotentTxResult struct {
+	CreateClaimTxResult
+	IdempotencyKey sqlc.IdempotencyKey
+	// Replayed is true when the key had already been used and no new claim was created
+	Replayed bool
+}
+
+// CreateClaimIdempotentTx creates a claim and records its response under an idempotency key.
+// Concurrent submissions with the same key are serialized with an advisory lock, so only
+// the first creates a claim and the rest receive the stored response.
+func (store *SQLStore) CreateClaimIdempotentTx(ctx context.Context, arg CreateClaimIdempotentTxParams) (CreateClaimIdempotentTxResult, error) {
+	var result CreateClaimIdempotentTxResult
+
+	err := store.execTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.LockIdempotencyKey(ctx, arg.Key); err != nil {
+			return err
+		}
+
+		if err := q.DeleteExpiredIdempotencyKey(ctx, arg.Key); err != nil {
+			return err
+		}
+
+		existing, err := q.GetIdempotencyKey(ctx, arg.Key)
+		if err == nil {
+			if existing.RequestHash != arg.RequestHash {
+				return ErrIdempotencyKeyReused
+			}
+
+			result.IdempotencyKey = existing
+			result.Replayed = true
+			return nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		result.CreateClaimTxResult, err = createClaim(ctx, q, arg.Claim)
+		if err != nil {
+			return err
+		}
+
+		status, body, err := arg.BuildResponse(result.CreateClaimTxResult)
+		if err != nil {
+			return err
+		}
+
+		result.IdempotencyKey, err = q.CreateIdempotencyKey(ctx, sqlc.CreateIdempotencyKeyParams{
+			Key:            arg.Key,
+			RequestHash:    arg.RequestHash,
+			ClaimID:        result.Claim.ID,
+			ResponseStatus: status,
+			ResponseBody:   body,
+			ExpiresAt:      arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotentClaim returns the arguments of a claim submission at a new pharmacy under a new key
+func idempotentClaim(t *testing.T, store *SQLStore) CreateClaimIdempotentTxParams {
+	pharmacy, err := store.CreatePharmacy(context.Background(), sqlc.CreatePharmacyParams{
+		NPI:   util.RandomNumericString(10),
+		Chain: util.RandomString(10),
+	})
+	require.NoError(t, err)
+
+	return CreateClaimIdempotentTxParams{
+		Claim: CreateClaimTxParams{
+			CreateClaimParams: sqlc.CreateClaimParams{
+				NDC:         util.RandomNumericString(11),
+				Price:       util.RandomMoney(),
+				Quantity:    util.RandomInt(1, 1000),
+				NPI:         pharmacy.NPI,
+				RejectCodes: []string{},
+			},
+		},
+		Key:         util.RandomString(16),
+		RequestHash: util.RandomString(32),
+		ExpiresAt:   time.Now().Add(time.Hour),
+		BuildResponse: func(result CreateClaimTxResult) (int32, []byte, error) {
+			return 201, []byte(`{"claim_id":"` + result.Claim.ID.String() + `"}`), nil
+		},
+	}
+}
+
+func TestCreateClaimIdempotentTxReplay(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	arg := idempotentClaim(t, store)
+
+	first, err := store.CreateClaimIdempotentTx(ctx, arg)
+	require.NoError(t, err)
+	require.False(t, first.Replayed)
+	require.Equal(t, first.Claim.ID, first.IdempotencyKey.ClaimID)
+
+	replay, err := store.CreateClaimIdempotentTx(ctx, arg)
+	require.NoError(t, err)
+	require.True(t, replay.Replayed)
+	require.Equal(t, first.Claim.ID, replay.IdempotencyKey.ClaimID)
+	require.Equal(t, first.IdempotencyKey.ResponseStatus, replay.IdempotencyKey.ResponseStatus)
+	require.JSONEq(t, string(first.IdempotencyKey.ResponseBody), string(replay.IdempotencyKey.ResponseBody))
+
+	claim, err := store.GetClaim(ctx, replay.IdempotencyKey.ClaimID)
+	require.NoError(t, err)
+	require.Equal(t, first.Claim.Status, claim.Status)
+}
+
+func TestCreateClaimIdempotentTxReusedKey(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	arg := idempotentClaim(t, store)
+
+	_, err := store.CreateClaimIdempotentTx(ctx, arg)
+	require.NoError(t, err)
+
+	arg.RequestHash = util.RandomString(32)
+	_, err = store.CreateClaimIdempotentTx(ctx, arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestCreateClaimIdempotentTxExpiredKey(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	arg := idempotentClaim(t, store)
+	arg.ExpiresAt = time.Now().Add(-time.Minute)
+
+	first, err := store.CreateClaimIdempotentTx(ctx, arg)
+	require.NoError(t, err)
+	require.False(t, first.Replayed)
+
+	// Once expired, the key is free again, even for a different request
+	arg.RequestHash = util.RandomString(32)
+	arg.ExpiresAt = time.Now().Add(time.Hour)
+	second, err := store.CreateClaimIdempotentTx(ctx, arg)
+	require.NoError(t, err)
+	require.False(t, second.Replayed)
+	require.NotEqual(t, first.Claim.ID, second.Claim.ID)
+	require.Equal(t, second.Claim.ID, second.IdempotencyKey.ClaimID)
+}
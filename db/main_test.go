@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pharmacy_claims_application/util"
+)
+
+// testStore runs transactions against the test database. It is nil when no database is
+// configured, so that the tests which do not need one still run.
+var testStore *SQLStore
+
+func TestMain(m *testing.M) {
+	config, err := util.LoadConfig("../../pharmacy_claims_application")
+	if err != nil {
+		log.Printf("Skipping store tests, cannot load config: %v", err)
+		os.Exit(m.Run())
+	}
+
+	conn, err := pgxpool.New(context.Background(), config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db:", err)
+	}
+	testStore = NewStore(conn).(*SQLStore)
+
+	exitCode := m.Run()
+	conn.Close()
+
+	os.Exit(exitCode)
+}
+
+// requireStore skips a test that needs the test database when none is configured
+func requireStore(t *testing.T) *SQLStore {
+	if testStore == nil {
+		t.Skip("test database is not configured")
+	}
+	return testStore
+}
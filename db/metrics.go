@@ -0,0 +1,30 @@
+package db
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pharmacy_claims_application/metrics"
+)
+
+// RegisterPoolMetrics exposes connection pool statistics on the default metrics registry
+func RegisterPoolMetrics(pool *pgxpool.Pool) {
+	metrics.MustRegister(
+		metrics.NewGaugeFunc("pharmacy_db_pool_acquired_connections", "Number of connections currently checked out of the pool.", func() float64 {
+			return float64(pool.Stat().AcquiredConns())
+		}),
+		metrics.NewGaugeFunc("pharmacy_db_pool_idle_connections", "Number of idle connections in the pool.", func() float64 {
+			return float64(pool.Stat().IdleConns())
+		}),
+		metrics.NewGaugeFunc("pharmacy_db_pool_total_connections", "Total number of connections in the pool.", func() float64 {
+			return float64(pool.Stat().TotalConns())
+		}),
+		metrics.NewCounterFunc("pharmacy_db_pool_acquire_total", "Cumulative number of successful connection acquires.", func() float64 {
+			return float64(pool.Stat().AcquireCount())
+		}),
+		metrics.NewCounterFunc("pharmacy_db_pool_empty_acquire_total", "Cumulative number of acquires that waited because the pool was empty.", func() float64 {
+			return float64(pool.Stat().EmptyAcquireCount())
+		}),
+		metrics.NewCounterFunc("pharmacy_db_pool_acquire_wait_seconds_total", "Cumulative time spent waiting for a connection.", func() float64 {
+			return pool.Stat().AcquireDuration().Seconds()
+		}),
+	)
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idempotency_keys_expires_at_idx;
+
+DROP TABLE IF EXISTS idempotency_keys;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE idempotency_keys (
+  key VARCHAR PRIMARY KEY NOT NULL,
+  request_hash VARCHAR NOT NULL,
+  claim_id UUID NOT NULL REFERENCES claims(id) ON DELETE CASCADE,
+  response_status INTEGER NOT NULL,
+  response_body JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS claims_duplicate_lookup_idx;
+
+ALTER TABLE claims DROP COLUMN IF EXISTS possible_duplicate;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE claims ADD COLUMN possible_duplicate BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX claims_duplicate_lookup_idx ON claims (npi, ndc, quantity, timestamp);
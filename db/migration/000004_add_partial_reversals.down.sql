@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS reversals_claim_id_idx;
+
+ALTER TABLE reversals
+  DROP CONSTRAINT IF EXISTS reversals_amount_check,
+  DROP CONSTRAINT IF EXISTS reversals_quantity_check,
+  DROP CONSTRAINT IF EXISTS reversals_kind_check;
+
+ALTER TABLE reversals
+  DROP COLUMN IF EXISTS reason_code,
+  DROP COLUMN IF EXISTS amount,
+  DROP COLUMN IF EXISTS quantity,
+  DROP COLUMN IF EXISTS kind;
\ No newline at end of file
@@ -0,0 +1,18 @@
+ALTER TABLE reversals
+  ADD COLUMN kind VARCHAR NOT NULL DEFAULT 'reversal',
+  ADD COLUMN quantity BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+  ADD COLUMN reason_code VARCHAR NOT NULL DEFAULT '';
+
+ALTER TABLE reversals
+  ADD CONSTRAINT reversals_kind_check CHECK (kind IN ('reversal', 'adjustment')),
+  ADD CONSTRAINT reversals_quantity_check CHECK (quantity >= 0),
+  ADD CONSTRAINT reversals_amount_check CHECK (amount >= 0);
+
+-- Reversals recorded before partial reversals existed reversed the whole claim
+UPDATE reversals
+SET quantity = claims.quantity, amount = claims.price
+FROM claims
+WHERE reversals.claim_id = claims.id;
+
+CREATE INDEX reversals_claim_id_idx ON reversals (claim_id);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS reversals_actor_idx;
+DROP INDEX IF EXISTS reversals_reason_code_idx;
+
+ALTER TABLE reversals DROP CONSTRAINT IF EXISTS reversals_reason_code_fkey;
+
+UPDATE reversals SET reason_code = '' WHERE reason_code = 'LEGACY';
+
+ALTER TABLE reversals
+  DROP COLUMN IF EXISTS actor,
+  DROP COLUMN IF EXISTS notes;
+
+DROP TABLE IF EXISTS reversal_reason_codes;
\ No newline at end of file
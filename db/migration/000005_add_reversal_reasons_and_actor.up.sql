@@ -0,0 +1,28 @@
+CREATE TABLE reversal_reason_codes (
+  code VARCHAR PRIMARY KEY NOT NULL,
+  description VARCHAR NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO reversal_reason_codes (code, description, active) VALUES
+  ('BILLED_IN_ERROR', 'Claim was billed in error', TRUE),
+  ('DUPLICATE', 'Claim duplicates an earlier submission', TRUE),
+  ('NOT_DISPENSED', 'Prescription was not picked up and returned to stock', TRUE),
+  ('WRONG_QUANTITY', 'Incorrect quantity was billed', TRUE),
+  ('WRONG_PRICE', 'Incorrect price was billed', TRUE),
+  ('WRONG_DRUG', 'Incorrect NDC was billed', TRUE),
+  ('PAYMENT_ADJUSTMENT', 'Payment adjusted after adjudication', TRUE),
+  ('LEGACY', 'Recorded before reversal reasons were required', FALSE);
+
+ALTER TABLE reversals
+  ADD COLUMN notes TEXT NOT NULL DEFAULT '',
+  ADD COLUMN actor VARCHAR NOT NULL DEFAULT '';
+
+UPDATE reversals SET reason_code = 'LEGACY' WHERE reason_code = '';
+
+ALTER TABLE reversals
+  ADD CONSTRAINT reversals_reason_code_fkey FOREIGN KEY (reason_code) REFERENCES reversal_reason_codes(code);
+
+CREATE INDEX reversals_reason_code_idx ON reversals (reason_code);
+CREATE INDEX reversals_actor_idx ON reversals (actor);
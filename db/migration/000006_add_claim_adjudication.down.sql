@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS claims_status_idx;
+
+ALTER TABLE claims
+  DROP CONSTRAINT IF EXISTS claims_status_check,
+  DROP COLUMN IF EXISTS reject_codes,
+  DROP COLUMN IF EXISTS status;
+
+DROP TABLE IF EXISTS formulary;
+
+ALTER TABLE pharmacies DROP COLUMN IF EXISTS active;
\ No newline at end of file
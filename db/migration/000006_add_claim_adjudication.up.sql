@@ -0,0 +1,16 @@
+ALTER TABLE pharmacies ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE TABLE formulary (
+  ndc VARCHAR PRIMARY KEY NOT NULL,
+  quantity_limit BIGINT NOT NULL DEFAULT 0,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT formulary_quantity_limit_check CHECK (quantity_limit >= 0)
+);
+
+ALTER TABLE claims
+  ADD COLUMN status VARCHAR NOT NULL DEFAULT 'approved',
+  ADD COLUMN reject_codes TEXT[] NOT NULL DEFAULT '{}',
+  ADD CONSTRAINT claims_status_check CHECK (status IN ('approved', 'rejected'));
+
+CREATE INDEX claims_status_idx ON claims (status);
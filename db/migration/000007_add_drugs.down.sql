@@ -0,0 +1 @@
+DROP TABLE IF EXISTS drugs;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE drugs (
+  ndc VARCHAR PRIMARY KEY NOT NULL,
+  name VARCHAR NOT NULL,
+  strength VARCHAR NOT NULL DEFAULT '',
+  package_size DOUBLE PRECISION NOT NULL DEFAULT 0,
+  unit_of_measure VARCHAR NOT NULL DEFAULT '',
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT drugs_package_size_check CHECK (package_size >= 0)
+);
+
+CREATE INDEX drugs_name_idx ON drugs (lower(name));
@@ -0,0 +1,5 @@
+ALTER TABLE claims
+  DROP COLUMN IF EXISTS price_exceeds_allowed,
+  DROP COLUMN IF EXISTS allowed_amount;
+
+DROP TABLE IF EXISTS reference_prices;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE reference_prices (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  ndc VARCHAR NOT NULL REFERENCES drugs(ndc) ON DELETE CASCADE,
+  unit_price DOUBLE PRECISION NOT NULL,
+  effective_from TIMESTAMPTZ NOT NULL,
+  effective_to TIMESTAMPTZ,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT reference_prices_unit_price_check CHECK (unit_price >= 0),
+  CONSTRAINT reference_prices_effective_check CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX reference_prices_lookup_idx ON reference_prices (ndc, effective_from);
+
+ALTER TABLE claims
+  ADD COLUMN allowed_amount DOUBLE PRECISION,
+  ADD COLUMN price_exceeds_allowed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS contracts;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE contracts (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  chain VARCHAR NOT NULL,
+  discount_percent DOUBLE PRECISION NOT NULL DEFAULT 0,
+  dispensing_fee DOUBLE PRECISION NOT NULL DEFAULT 0,
+  effective_from TIMESTAMPTZ NOT NULL,
+  effective_to TIMESTAMPTZ,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT contracts_discount_percent_check CHECK (discount_percent >= 0 AND discount_percent <= 100),
+  CONSTRAINT contracts_dispensing_fee_check CHECK (dispensing_fee >= 0),
+  CONSTRAINT contracts_effective_check CHECK (effective_to IS NULL OR effective_to > effective_from)
+);
+
+CREATE INDEX contracts_chain_idx ON contracts (chain, effective_from);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS settlement_items;
+DROP TABLE IF EXISTS settlement_batches;
+DROP TABLE IF EXISTS settlement_cycles;
\ No newline at end of file
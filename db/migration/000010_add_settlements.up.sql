@@ -0,0 +1,32 @@
+CREATE TABLE settlement_cycles (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  cutoff TIMESTAMPTZ NOT NULL,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE settlement_batches (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  cycle_id UUID NOT NULL REFERENCES settlement_cycles(id) ON DELETE CASCADE,
+  npi VARCHAR NOT NULL REFERENCES pharmacies(npi) ON DELETE CASCADE,
+  claim_count BIGINT NOT NULL DEFAULT 0,
+  payment_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+  clawback_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+  net_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT settlement_batches_cycle_npi_key UNIQUE (cycle_id, npi)
+);
+
+CREATE TABLE settlement_items (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  batch_id UUID NOT NULL REFERENCES settlement_batches(id) ON DELETE CASCADE,
+  claim_id UUID NOT NULL REFERENCES claims(id) ON DELETE CASCADE,
+  reversal_id UUID REFERENCES reversals(id) ON DELETE CASCADE,
+  kind VARCHAR NOT NULL,
+  amount DOUBLE PRECISION NOT NULL,
+  CONSTRAINT settlement_items_kind_check CHECK (kind IN ('payment', 'reversal', 'adjustment', 'clawback'))
+);
+
+-- A claim is paid once, and every reversal or adjustment is settled once
+CREATE UNIQUE INDEX settlement_items_payment_idx ON settlement_items (claim_id) WHERE kind = 'payment';
+CREATE UNIQUE INDEX settlement_items_reversal_idx ON settlement_items (reversal_id) WHERE reversal_id IS NOT NULL;
+CREATE INDEX settlement_items_batch_idx ON settlement_items (batch_id);
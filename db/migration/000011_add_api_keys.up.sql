@@ -0,0 +1,11 @@
+CREATE TABLE api_keys (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  name VARCHAR NOT NULL,
+  prefix VARCHAR NOT NULL,
+  key_hash VARCHAR NOT NULL UNIQUE,
+  chain VARCHAR,
+  npis TEXT[] NOT NULL DEFAULT '{}',
+  revoked_at TIMESTAMPTZ,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT api_keys_scope_check CHECK ((chain IS NULL) <> (cardinality(npis) = 0))
+);
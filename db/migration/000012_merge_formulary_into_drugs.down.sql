@@ -0,0 +1,12 @@
+CREATE TABLE formulary (
+  ndc VARCHAR PRIMARY KEY NOT NULL,
+  quantity_limit BIGINT NOT NULL DEFAULT 0,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT formulary_quantity_limit_check CHECK (quantity_limit >= 0)
+);
+
+INSERT INTO formulary (ndc, quantity_limit, active)
+SELECT ndc, quantity_limit, active FROM drugs;
+
+ALTER TABLE drugs DROP COLUMN quantity_limit;
@@ -0,0 +1,12 @@
+ALTER TABLE drugs
+  ADD COLUMN quantity_limit BIGINT NOT NULL DEFAULT 0,
+  ADD CONSTRAINT drugs_quantity_limit_check CHECK (quantity_limit >= 0);
+
+-- Formulary entries become drugs; an NDC is only covered while both records were active
+INSERT INTO drugs (ndc, name, quantity_limit, active)
+SELECT ndc, ndc, quantity_limit, active FROM formulary
+ON CONFLICT (ndc) DO UPDATE
+SET quantity_limit = EXCLUDED.quantity_limit,
+    active = drugs.active AND EXCLUDED.active;
+
+DROP TABLE formulary;
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS claim_net_quantity(UUID, BIGINT);
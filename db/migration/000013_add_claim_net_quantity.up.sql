@@ -0,0 +1,8 @@
+-- A fill stays live while some of its quantity remains after reversals and adjustments.
+-- Duplicate detection and the refill-too-soon check share this definition.
+CREATE FUNCTION claim_net_quantity(claim_id UUID, quantity BIGINT) RETURNS BIGINT
+LANGUAGE SQL STABLE AS $$
+  SELECT $2 + COALESCE(SUM(CASE WHEN reversals.kind = 'adjustment' THEN reversals.quantity ELSE -reversals.quantity END), 0)::bigint
+  FROM reversals
+  WHERE reversals.claim_id = $1
+$$;
@@ -1,8 +1,8 @@
 -- name: CreateClaim :one
 INSERT INTO claims (
-  ndc, quantity, npi, price     
+  ndc, quantity, npi, price, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 RETURNING *;
 
@@ -10,6 +10,34 @@ RETURNING *;
 SELECT * FROM claims
 WHERE id = $1 LIMIT 1;
 
+-- name: GetClaimForUpdate :one
+SELECT * FROM claims
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
 -- name: DeleteClaim :exec
 DELETE FROM claims
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: LockClaimFill :exec
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(npi)::text || ':' || sqlc.arg(ndc)::text));
+
+-- name: FindDuplicateClaim :one
+SELECT * FROM claims
+WHERE npi = $1
+  AND ndc = $2
+  AND quantity = $3
+  AND timestamp >= sqlc.arg(since)
+  AND status = 'approved'
+  AND claim_net_quantity(claims.id, claims.quantity) > 0
+ORDER BY timestamp DESC
+LIMIT 1;
+
+-- name: GetLastApprovedFill :one
+SELECT * FROM claims
+WHERE npi = $1
+  AND ndc = $2
+  AND status = 'approved'
+  AND claim_net_quantity(claims.id, claims.quantity) > 0
+ORDER BY timestamp DESC
+LIMIT 1;
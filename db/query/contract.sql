@@ -0,0 +1,59 @@
+-- name: CreateContract :one
+INSERT INTO contracts (
+  chain, discount_percent, dispensing_fee, effective_from, effective_to
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetContract :one
+SELECT * FROM contracts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetEffectiveContract :one
+SELECT * FROM contracts
+WHERE chain = $1
+  AND effective_from <= sqlc.arg(service_date)
+  AND (effective_to IS NULL OR effective_to > sqlc.arg(service_date))
+ORDER BY effective_from DESC
+LIMIT 1;
+
+-- name: FindOverlappingContract :one
+SELECT * FROM contracts
+WHERE chain = $1
+  AND id <> sqlc.arg(exclude_id)
+  AND effective_from < COALESCE(sqlc.narg(effective_to)::timestamptz, 'infinity'::timestamptz)
+  AND (effective_to IS NULL OR effective_to > sqlc.arg(effective_from))
+LIMIT 1;
+
+-- name: LockChainContracts :exec
+SELECT pg_advisory_xact_lock(hashtext('contracts:' || sqlc.arg(chain)::text));
+
+-- name: ListContracts :many
+SELECT * FROM contracts
+WHERE (sqlc.narg(chain)::varchar IS NULL OR chain = sqlc.narg(chain))
+ORDER BY chain, effective_from DESC;
+
+-- name: UpdateContract :one
+UPDATE contracts
+SET discount_percent = $2, dispensing_fee = $3, effective_from = $4, effective_to = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ChainPricingReport :many
+SELECT
+  p.chain,
+  COUNT(*) AS claim_count,
+  COUNT(c.allowed_amount) AS priced_count,
+  COUNT(*) FILTER (WHERE c.price_exceeds_allowed) AS exceeding_count,
+  COALESCE(SUM(c.price), 0)::double precision AS submitted_total,
+  COALESCE(SUM(c.price) FILTER (WHERE c.allowed_amount IS NOT NULL), 0)::double precision AS priced_submitted_total,
+  COALESCE(SUM(c.allowed_amount), 0)::double precision AS contracted_total
+FROM claims c
+JOIN pharmacies p ON p.npi = c.npi
+WHERE c.status = 'approved'
+  AND (sqlc.narg(chain)::varchar IS NULL OR p.chain = sqlc.narg(chain))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR c.timestamp >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR c.timestamp < sqlc.narg(until))
+GROUP BY p.chain
+ORDER BY p.chain;
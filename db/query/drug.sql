@@ -0,0 +1,43 @@
+-- name: CreateDrug :one
+INSERT INTO drugs (
+  ndc, name, strength, package_size, unit_of_measure, active, quantity_limit
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetDrug :one
+SELECT * FROM drugs
+WHERE ndc = $1 LIMIT 1;
+
+-- name: ListDrugs :many
+SELECT * FROM drugs
+WHERE (sqlc.narg(active)::boolean IS NULL OR active = sqlc.narg(active))
+  AND (sqlc.narg(name)::varchar IS NULL OR lower(name) LIKE '%' || lower(sqlc.narg(name)) || '%')
+ORDER BY ndc
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: UpdateDrug :one
+UPDATE drugs
+SET name = $2, strength = $3, package_size = $4, unit_of_measure = $5, active = $6, quantity_limit = $7
+WHERE ndc = $1
+RETURNING *;
+
+-- name: UpsertDrug :one
+INSERT INTO drugs (
+  ndc, name, strength, package_size, unit_of_measure, active, quantity_limit
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (ndc) DO UPDATE
+SET name = EXCLUDED.name,
+    strength = EXCLUDED.strength,
+    package_size = EXCLUDED.package_size,
+    unit_of_measure = EXCLUDED.unit_of_measure,
+    active = EXCLUDED.active,
+    quantity_limit = EXCLUDED.quantity_limit
+RETURNING *;
+
+-- name: DeleteDrug :execrows
+DELETE FROM drugs
+WHERE ndc = $1;
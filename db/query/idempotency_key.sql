@@ -0,0 +1,22 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  key, request_hash, claim_id, response_status, response_body, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE key = $1 AND expires_at > NOW() LIMIT 1;
+
+-- name: LockIdempotencyKey :exec
+SELECT pg_advisory_xact_lock(hashtext($1));
+
+-- name: DeleteExpiredIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND expires_at <= NOW();
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= NOW();
@@ -11,4 +11,9 @@ SELECT * FROM pharmacies
 WHERE npi = $1 LIMIT 1;
 
 -- name: CountPharmacies :one
-SELECT COUNT(*) FROM pharmacies;
\ No newline at end of file
+SELECT COUNT(*) FROM pharmacies;
+-- name: UpdatePharmacyActive :one
+UPDATE pharmacies
+SET active = $2
+WHERE npi = $1
+RETURNING *;
@@ -0,0 +1,20 @@
+-- name: CreateReferencePrice :one
+INSERT INTO reference_prices (
+  ndc, unit_price, effective_from, effective_to
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetReferencePrice :one
+SELECT * FROM reference_prices
+WHERE ndc = $1
+  AND effective_from <= sqlc.arg(service_date)
+  AND (effective_to IS NULL OR effective_to > sqlc.arg(service_date))
+ORDER BY effective_from DESC
+LIMIT 1;
+
+-- name: ListReferencePricesByNDC :many
+SELECT * FROM reference_prices
+WHERE ndc = $1
+ORDER BY effective_from DESC;
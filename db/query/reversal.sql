@@ -1,8 +1,8 @@
 -- name: CreateReversal :one
 INSERT INTO reversals (
-  claim_id  
+  claim_id, kind, quantity, amount, reason_code, notes, actor
 ) VALUES (
-  $1
+  $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING *;
 
@@ -10,6 +10,21 @@ RETURNING *;
 SELECT * FROM reversals
 WHERE claim_id = $1 LIMIT 1;
 
+-- name: ListReversalsByClaimID :many
+SELECT * FROM reversals
+WHERE claim_id = $1
+ORDER BY timestamp, id;
+
+-- name: ListReversals :many
+SELECT * FROM reversals
+WHERE (sqlc.narg(reason_code)::varchar IS NULL OR reason_code = sqlc.narg(reason_code))
+  AND (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(claim_id)::uuid IS NULL OR claim_id = sqlc.narg(claim_id))
+  AND (sqlc.narg(since)::timestamptz IS NULL OR timestamp >= sqlc.narg(since))
+  AND (sqlc.narg(until)::timestamptz IS NULL OR timestamp < sqlc.narg(until))
+ORDER BY timestamp DESC, id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
 -- name: DeleteReversal :exec
 DELETE FROM reversals
 WHERE id = $1;
\ No newline at end of file
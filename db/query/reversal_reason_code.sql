@@ -0,0 +1,21 @@
+-- name: CreateReversalReasonCode :one
+INSERT INTO reversal_reason_codes (
+  code, description, active
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetReversalReasonCode :one
+SELECT * FROM reversal_reason_codes
+WHERE code = $1 LIMIT 1;
+
+-- name: ListReversalReasonCodes :many
+SELECT * FROM reversal_reason_codes
+ORDER BY code;
+
+-- name: UpdateReversalReasonCode :one
+UPDATE reversal_reason_codes
+SET description = $2, active = $3
+WHERE code = $1
+RETURNING *;
\ No newline at end of file
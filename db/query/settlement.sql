@@ -0,0 +1,106 @@
+-- name: LockSettlement :exec
+SELECT pg_advisory_xact_lock(hashtext('settlement'));
+
+-- name: CreateSettlementCycle :one
+INSERT INTO settlement_cycles (
+  cutoff
+) VALUES (
+  $1
+)
+RETURNING *;
+
+-- name: GetSettlementCycle :one
+SELECT * FROM settlement_cycles
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSettlementCycles :many
+SELECT * FROM settlement_cycles
+ORDER BY cutoff DESC, id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CreateSettlementBatch :one
+INSERT INTO settlement_batches (
+  cycle_id, npi, claim_count, payment_amount, clawback_amount, net_amount
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetSettlementBatch :one
+SELECT * FROM settlement_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSettlementBatchesByCycle :many
+SELECT * FROM settlement_batches
+WHERE cycle_id = $1
+ORDER BY npi;
+
+-- name: ListSettlementBatchesByNPI :many
+SELECT * FROM settlement_batches
+WHERE npi = $1
+ORDER BY timestamp DESC, id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: CreateSettlementItem :one
+INSERT INTO settlement_items (
+  batch_id, claim_id, reversal_id, kind, amount
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListSettlementItemsByBatch :many
+SELECT settlement_items.*, claims.ndc, claims.quantity, claims.price, claims.timestamp AS claim_timestamp
+FROM settlement_items
+JOIN claims ON claims.id = settlement_items.claim_id
+WHERE settlement_items.batch_id = $1
+ORDER BY claims.timestamp, settlement_items.claim_id, settlement_items.reversal_id NULLS FIRST;
+
+-- name: ListUnsettledClaims :many
+SELECT * FROM claims
+WHERE status = 'approved'
+  AND timestamp < sqlc.arg(cutoff)
+  AND NOT EXISTS (
+    SELECT 1 FROM settlement_items
+    WHERE settlement_items.claim_id = claims.id AND settlement_items.kind = 'payment'
+  )
+  -- Claims fully reversed before they were ever paid are left out for good
+  AND NOT EXISTS (
+    SELECT 1 FROM reversals AS history
+    WHERE history.claim_id = claims.id AND history.timestamp < sqlc.arg(cutoff)
+    HAVING claims.quantity + SUM(CASE WHEN history.kind = 'adjustment' THEN history.quantity ELSE -history.quantity END) <= 0
+      AND ROUND((claims.price + SUM(CASE WHEN history.kind = 'adjustment' THEN history.amount ELSE -history.amount END))::numeric, 2) <= 0
+  )
+ORDER BY npi, timestamp, id;
+
+-- name: ListUnsettledReversals :many
+SELECT
+  reversals.*,
+  claims.npi,
+  claims.price AS claim_price,
+  claims.allowed_amount AS claim_allowed_amount,
+  EXISTS (
+    SELECT 1 FROM settlement_items
+    WHERE settlement_items.claim_id = claims.id AND settlement_items.kind = 'payment'
+  ) AS claim_settled
+FROM reversals
+JOIN claims ON claims.id = reversals.claim_id
+WHERE claims.status = 'approved'
+  AND reversals.timestamp < sqlc.arg(cutoff)
+  AND NOT EXISTS (
+    SELECT 1 FROM settlement_items WHERE settlement_items.reversal_id = reversals.id
+  )
+  AND (
+    EXISTS (
+      SELECT 1 FROM settlement_items
+      WHERE settlement_items.claim_id = claims.id AND settlement_items.kind = 'payment'
+    )
+    -- and so are the reversals of claims that were fully reversed before they were paid
+    OR NOT EXISTS (
+      SELECT 1 FROM reversals AS history
+      WHERE history.claim_id = claims.id AND history.timestamp < sqlc.arg(cutoff)
+      HAVING claims.quantity + SUM(CASE WHEN history.kind = 'adjustment' THEN history.quantity ELSE -history.quantity END) <= 0
+        AND ROUND((claims.price + SUM(CASE WHEN history.kind = 'adjustment' THEN history.amount ELSE -history.amount END))::numeric, 2) <= 0
+    )
+  )
+ORDER BY reversals.timestamp, reversals.id;
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pharmacy_claims_application/adjudication"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+var (
+	// ErrClaimNotFound is returned when a reversal references a claim that does not exist
+	ErrClaimNotFound = errors.New("claim not found")
+	// ErrClaimRejected is returned when reversing a claim that was rejected at adjudication
+	ErrClaimRejected = errors.New("claim was rejected at adjudication")
+	// ErrClaimAlreadyReversed is returned when nothing remains of a claim to reverse or adjust
+	ErrClaimAlreadyReversed = errors.New("claim has already been reversed")
+	// ErrInvalidReasonCode is returned when a reversal uses an unknown or inactive reason code
+	ErrInvalidReasonCode = errors.New("reason code is unknown or inactive")
+	// ErrEmptyAdjustment is returned when an adjustment adds neither quantity nor amount
+	ErrEmptyAdjustment = errors.New("adjustment must add quantity or amount")
+	// ErrBatchRolledBack is returned by all-or-nothing batches when any item failed
+	ErrBatchRolledBack = errors.New("batch rolled back because at least one item failed")
+)
+
+// CreateReversalTxParams contains the input of a reversal and the window it must respect
+type CreateReversalTxParams struct {
+	sqlc.CreateReversalParams
+	Window ReversalWindowPolicy
+	// OverrideWindow allows the reversal after the window has closed
+	OverrideWindow bool
+}
+
+// CreateReversalTxResult is the result of a reversal or adjustment
+type CreateReversalTxResult struct {
+	Reversal sqlc.Reversal
+	// Balance is the claim balance after the reversal was recorded
+	Balance ClaimBalance
+	// WindowOverridden is set when the reversal was only allowed by OverrideWindow
+	WindowOverridden bool
+}
+
+// CreateReversalTx records a reversal or adjustment within a database transaction.
+// A reversal with zero quantity and amount reverses whatever remains of the claim.
+func (store *SQLStore) CreateReversalTx(ctx context.Context, arg CreateReversalTxParams) (CreateReversalTxResult, error) {
+	var result CreateReversalTxResult
+
+	err := store.execTx(ctx, func(q *sqlc.Queries) error {
+		var err error
+
+		result, err = reverseClaim(ctx, q, arg)
+		return err
+	})
+
+	return result, err
+}
+
+// CreateReversalBatchItem is the outcome of one reversal in a batch
+type CreateReversalBatchItem struct {
+	CreateReversalTxResult
+	ClaimID uuid.UUID
+	Err     error
+}
+
+// CreateReversalBatchTx reverses many claims in a single transaction, each in its own savepoint.
+// In all-or-nothing mode every item is still attempted so that all outcomes can be reported,
+// but the transaction is rolled back and ErrBatchRolledBack returned if any item failed.
+func (store *SQLStore) CreateReversalBatchTx(ctx context.Context, args []CreateReversalTxParams, allOrNothing bool) ([]CreateReversalBatchItem, error) {
+	items := make([]CreateReversalBatchItem, len(args))
+	failed := false
+
+	tx, err := store.connPool.Begin(ctx)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	defer tx.Rollback(ctx)
+
+	for i, arg := range args {
+		items[i].ClaimID = arg.ClaimID
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, TranslateError(err)
+		}
+
+		items[i].CreateReversalTxResult, items[i].Err = translate(reverseClaim(ctx, sqlc.New(savepoint), arg))
+		if items[i].Err != nil {
+			failed = true
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, TranslateError(err)
+			}
+			continue
+		}
+
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, TranslateError(err)
+		}
+	}
+
+	if allOrNothing && failed {
+		return items, ErrBatchRolledBack
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, TranslateError(err)
+	}
+
+	return items, nil
+}
+
+// reverseClaim locks the claim and records a reversal or adjustment against its balance.
+// Cumulative reversals may never take the claim's net quantity or amount below zero.
+func reverseClaim(ctx context.Context, q *sqlc.Queries, txArg CreateReversalTxParams) (CreateReversalTxResult, error) {
+	arg := txArg.CreateReversalParams
+	var result CreateReversalTxResult
+
+	claim, err := q.GetClaimForUpdate(ctx, arg.ClaimID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, ErrClaimNotFound
+		}
+		return result, err
+	}
+
+	if claim.Status == adjudication.StatusRejected {
+		return result, ErrClaimRejected
+	}
+
+	result.WindowOverridden, err = checkReversalWindow(ctx, q, claim, txArg.Window, txArg.OverrideWindow)
+	if err != nil {
+		return result, err
+	}
+
+	history, err := q.ListReversalsByClaimID(ctx, arg.ClaimID)
+	if err != nil {
+		return result, err
+	}
+
+	balance := NewClaimBalance(claim, history)
+	if balance.FullyReversed() {
+		return result, ErrClaimAlreadyReversed
+	}
+
+	reason, err := q.GetReversalReasonCode(ctx, arg.ReasonCode)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !reason.Active) {
+		return result, ErrInvalidReasonCode
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if arg.Kind == "" {
+		arg.Kind = ReversalKindReversal
+	}
+
+	switch arg.Kind {
+	case ReversalKindAdjustment:
+		if arg.Quantity == 0 && arg.Amount == 0 {
+			return result, ErrEmptyAdjustment
+		}
+	default:
+		if arg.Quantity == 0 && arg.Amount == 0 {
+			// Full reversal of whatever remains
+			arg.Quantity = max(balance.NetQuantity, 0)
+			arg.Amount = max(balance.NetAmount, 0)
+		}
+
+		if arg.Quantity > balance.NetQuantity || roundCents(arg.Amount) > balance.NetAmount {
+			return result, &ReversalExceedsBalanceError{Balance: balance}
+		}
+	}
+
+	result.Reversal, err = q.CreateReversal(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	result.Balance = NewClaimBalance(claim, append(history, result.Reversal))
+	return result, nil
+}
+
+// checkReversalWindow enforces the reversal window for the claim's chain.
+// It reports whether the window had closed but was bypassed by override.
+func checkReversalWindow(ctx context.Context, q *sqlc.Queries, claim sqlc.Claim, policy ReversalWindowPolicy, override bool) (bool, error) {
+	var chain string
+	if len(policy.ChainWindows) > 0 {
+		pharmacy, err := q.GetPharmacy(ctx, claim.NPI)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return false, err
+		}
+		chain = pharmacy.Chain
+	}
+
+	deadline, ok := policy.Deadline(claim.Timestamp, chain)
+	if !ok || !time.Now().After(deadline) {
+		return false, nil
+	}
+
+	if override {
+		return true, nil
+	}
+
+	return false, &ReversalWindowExpiredError{Deadline: deadline, Window: policy.WindowFor(chain)}
+}
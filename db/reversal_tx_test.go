@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestClaims stores an approved claim for each of n fills at a new pharmacy
+func createTestClaims(t *testing.T, store *SQLStore, n int) []sqlc.Claim {
+	ctx := context.Background()
+
+	pharmacy, err := store.CreatePharmacy(ctx, sqlc.CreatePharmacyParams{
+		NPI:   util.RandomNumericString(10),
+		Chain: util.RandomString(10),
+	})
+	require.NoError(t, err)
+
+	claims := make([]sqlc.Claim, n)
+	for i := range claims {
+		claims[i], err = store.CreateClaim(ctx, sqlc.CreateClaimParams{
+			NDC:         util.RandomNumericString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		})
+		require.NoError(t, err)
+	}
+
+	return claims
+}
+
+// fullReversals returns the batch arguments that fully reverse each claim
+func fullReversals(claimIDs ...uuid.UUID) []CreateReversalTxParams {
+	args := make([]CreateReversalTxParams, len(claimIDs))
+	for i, claimID := range claimIDs {
+		args[i] = CreateReversalTxParams{
+			CreateReversalParams: sqlc.CreateReversalParams{
+				ClaimID:    claimID,
+				Kind:       ReversalKindReversal,
+				ReasonCode: "BILLED_IN_ERROR",
+				Actor:      "test",
+			},
+		}
+	}
+	return args
+}
+
+func TestCreateReversalBatchTxBestEffort(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	claims := createTestClaims(t, store, 2)
+	missing := uuid.New()
+
+	items, err := store.CreateReversalBatchTx(ctx, fullReversals(claims[0].ID, missing, claims[1].ID), false)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	require.NoError(t, items[0].Err)
+	require.Equal(t, claims[0].ID, items[0].Reversal.ClaimID)
+	require.True(t, items[0].Balance.FullyReversed())
+
+	require.Equal(t, missing, items[1].ClaimID)
+	require.ErrorIs(t, items[1].Err, ErrClaimNotFound)
+
+	require.NoError(t, items[2].Err)
+
+	// The successful reversals are kept despite the failed item
+	for _, claim := range claims {
+		reversals, err := store.ListReversalsByClaimID(ctx, claim.ID)
+		require.NoError(t, err)
+		require.Len(t, reversals, 1)
+	}
+}
+
+func TestCreateReversalBatchTxAllOrNothing(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	claims := createTestClaims(t, store, 2)
+
+	_, err := store.CreateReversalTx(ctx, fullReversals(claims[1].ID)[0])
+	require.NoError(t, err)
+
+	// Every item is attempted and reported, then the batch is rolled back
+	items, err := store.CreateReversalBatchTx(ctx, fullReversals(claims[0].ID, claims[1].ID), true)
+	require.ErrorIs(t, err, ErrBatchRolledBack)
+	require.Len(t, items, 2)
+	require.NoError(t, items[0].Err)
+	require.ErrorIs(t, items[1].Err, ErrClaimAlreadyReversed)
+
+	reversals, err := store.ListReversalsByClaimID(ctx, claims[0].ID)
+	require.NoError(t, err)
+	require.Empty(t, reversals)
+
+	// Without failures the whole batch is committed
+	items, err = store.CreateReversalBatchTx(ctx, fullReversals(claims[0].ID), true)
+	require.NoError(t, err)
+	require.NoError(t, items[0].Err)
+
+	reversals, err = store.ListReversalsByClaimID(ctx, claims[0].ID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 1)
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReversalWindowPolicy limits how long after adjudication a claim may be reversed or adjusted
+type ReversalWindowPolicy struct {
+	// Window applies to every chain without an override; zero disables the check
+	Window time.Duration
+	// ChainWindows overrides Window for specific pharmacy chains; a zero entry disables the check for that chain
+	ChainWindows map[string]time.Duration
+}
+
+// WindowFor returns the window that applies to the given chain
+func (p ReversalWindowPolicy) WindowFor(chain string) time.Duration {
+	if window, ok := p.ChainWindows[chain]; ok {
+		return window
+	}
+	return p.Window
+}
+
+// Deadline returns the last moment a claim adjudicated at claimTime may be reversed.
+// The second result is false when no window applies to the chain.
+func (p ReversalWindowPolicy) Deadline(claimTime time.Time, chain string) (time.Time, bool) {
+	window := p.WindowFor(chain)
+	if window <= 0 {
+		return time.Time{}, false
+	}
+	return claimTime.Add(window), true
+}
+
+// ReversalWindowExpiredError is returned when a reversal arrives after the claim's reversal window closed
+type ReversalWindowExpiredError struct {
+	Deadline time.Time
+	Window   time.Duration
+}
+
+func (e *ReversalWindowExpiredError) Error() string {
+	return fmt.Sprintf("reversal window of %s closed at %s", e.Window, e.Deadline.Format(time.RFC3339))
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReversalWindowPolicy(t *testing.T) {
+	claimTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	policy := ReversalWindowPolicy{
+		Window: 90 * 24 * time.Hour,
+		ChainWindows: map[string]time.Duration{
+			"short":     30 * 24 * time.Hour,
+			"unlimited": 0,
+		},
+	}
+
+	deadline, ok := policy.Deadline(claimTime, "other")
+	require.True(t, ok)
+	require.Equal(t, claimTime.Add(90*24*time.Hour), deadline)
+
+	deadline, ok = policy.Deadline(claimTime, "short")
+	require.True(t, ok)
+	require.Equal(t, claimTime.Add(30*24*time.Hour), deadline)
+
+	_, ok = policy.Deadline(claimTime, "unlimited")
+	require.False(t, ok)
+
+	_, ok = ReversalWindowPolicy{}.Deadline(claimTime, "other")
+	require.False(t, ok)
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// Kinds of settlement items
+const (
+	// SettlementItemPayment pays a claim for the first time
+	SettlementItemPayment = "payment"
+	// SettlementItemReversal nets a reversal against a claim paid in the same batch
+	SettlementItemReversal = "reversal"
+	// SettlementItemAdjustment pays an adjustment, whether or not the claim was settled earlier
+	SettlementItemAdjustment = "adjustment"
+	// SettlementItemClawback recovers a reversal of a claim paid in an earlier cycle
+	SettlementItemClawback = "clawback"
+)
+
+// SettlementBatchPlan is the payment batch of one pharmacy before it is stored
+type SettlementBatchPlan struct {
+	NPI            string
+	ClaimCount     int64
+	PaymentAmount  float64
+	ClawbackAmount float64
+	NetAmount      float64
+	Items          []SettlementItemPlan
+}
+
+// SettlementItemPlan is one line of a planned batch; amounts are negative when money is recovered
+type SettlementItemPlan struct {
+	ClaimID    uuid.UUID
+	ReversalID pgtype.UUID
+	Kind       string
+	Amount     float64
+}
+
+// PayableAmount is what a claim is paid: the lesser of the submitted price and the allowed amount
+func PayableAmount(price float64, allowed pgtype.Float8) float64 {
+	if allowed.Valid && allowed.Float64 < price {
+		return allowed.Float64
+	}
+	return price
+}
+
+// payableRatio scales reversal and adjustment amounts, which are in submitted price terms, to
+// what was actually paid for the claim
+func payableRatio(price float64, allowed pgtype.Float8) float64 {
+	if price <= 0 {
+		return 1
+	}
+	return PayableAmount(price, allowed) / price
+}
+
+// PlanSettlement groups unsettled claims and reversals into one batch per pharmacy.
+// Claims are paid net of their reversals and adjustments up to the cutoff; claims that were
+// fully reversed before they were ever paid are left out together with their reversals.
+// Reversals of claims paid in an earlier cycle become clawbacks. Batches are ordered by NPI.
+func PlanSettlement(claims []sqlc.Claim, reversals []sqlc.ListUnsettledReversalsRow) []SettlementBatchPlan {
+	batches := make(map[string]*SettlementBatchPlan)
+	batchFor := func(npi string) *SettlementBatchPlan {
+		batch, ok := batches[npi]
+		if !ok {
+			batch = &SettlementBatchPlan{NPI: npi}
+			batches[npi] = batch
+		}
+		return batch
+	}
+
+	byClaim := make(map[uuid.UUID][]sqlc.ListUnsettledReversalsRow)
+	for _, reversal := range reversals {
+		byClaim[reversal.ClaimID] = append(byClaim[reversal.ClaimID], reversal)
+	}
+
+	for _, claim := range claims {
+		history := byClaim[claim.ID]
+
+		rows := make([]sqlc.Reversal, 0, len(history))
+		for _, reversal := range history {
+			rows = append(rows, sqlc.Reversal{Kind: reversal.Kind, Quantity: reversal.Quantity, Amount: reversal.Amount})
+		}
+		if NewClaimBalance(claim, rows).FullyReversed() {
+			continue
+		}
+
+		batch := batchFor(claim.NPI)
+		batch.ClaimCount++
+		batch.add(SettlementItemPlan{
+			ClaimID: claim.ID,
+			Kind:    SettlementItemPayment,
+			Amount:  roundCents(PayableAmount(claim.Price, claim.AllowedAmount)),
+		})
+
+		ratio := payableRatio(claim.Price, claim.AllowedAmount)
+		for _, reversal := range history {
+			kind := SettlementItemReversal
+			if reversal.Kind == ReversalKindAdjustment {
+				kind = SettlementItemAdjustment
+			}
+			batch.add(newSettlementItem(reversal, kind, ratio))
+		}
+	}
+
+	for _, reversal := range reversals {
+		// Reversals of claims paid in this cycle were netted above; those of claims that were
+		// never paid stay unsettled
+		if !reversal.ClaimSettled {
+			continue
+		}
+
+		kind := SettlementItemClawback
+		if reversal.Kind == ReversalKindAdjustment {
+			kind = SettlementItemAdjustment
+		}
+		batchFor(reversal.NPI).add(newSettlementItem(reversal, kind, payableRatio(reversal.ClaimPrice, reversal.ClaimAllowedAmount)))
+	}
+
+	plans := make([]SettlementBatchPlan, 0, len(batches))
+	for _, batch := range batches {
+		batch.PaymentAmount = roundCents(batch.PaymentAmount)
+		batch.ClawbackAmount = roundCents(batch.ClawbackAmount)
+		batch.NetAmount = roundCents(batch.PaymentAmount - batch.ClawbackAmount)
+		plans = append(plans, *batch)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].NPI < plans[j].NPI })
+
+	return plans
+}
+
+// newSettlementItem converts a reversal or adjustment into a signed settlement item
+func newSettlementItem(reversal sqlc.ListUnsettledReversalsRow, kind string, ratio float64) SettlementItemPlan {
+	amount := roundCents(reversal.Amount * ratio)
+	if reversal.Kind != ReversalKindAdjustment {
+		amount = -amount
+	}
+
+	return SettlementItemPlan{
+		ClaimID:    reversal.ClaimID,
+		ReversalID: pgtype.UUID{Bytes: reversal.ID, Valid: true},
+		Kind:       kind,
+		Amount:     amount,
+	}
+}
+
+// add appends an item and updates the batch totals
+func (b *SettlementBatchPlan) add(item SettlementItemPlan) {
+	b.Items = append(b.Items, item)
+	if item.Kind == SettlementItemClawback {
+		b.ClawbackAmount -= item.Amount
+	} else {
+		b.PaymentAmount += item.Amount
+	}
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanSettlement(t *testing.T) {
+	paid := sqlc.Claim{ID: uuid.New(), NPI: "1111111111", Quantity: 30, Price: 100, AllowedAmount: pgtype.Float8{Float64: 80, Valid: true}}
+	partial := sqlc.Claim{ID: uuid.New(), NPI: "1111111111", Quantity: 10, Price: 50}
+	reversed := sqlc.Claim{ID: uuid.New(), NPI: "2222222222", Quantity: 10, Price: 20}
+	earlier := uuid.New()
+
+	reversals := []sqlc.ListUnsettledReversalsRow{
+		{ID: uuid.New(), ClaimID: partial.ID, NPI: partial.NPI, Kind: ReversalKindReversal, Amount: 10, ClaimPrice: partial.Price},
+		{ID: uuid.New(), ClaimID: reversed.ID, NPI: reversed.NPI, Kind: ReversalKindReversal, Quantity: 10, Amount: 20, ClaimPrice: reversed.Price},
+		// A reversal of a claim paid in an earlier cycle, priced at half its submitted price
+		{ID: uuid.New(), ClaimID: earlier, NPI: "2222222222", Kind: ReversalKindReversal, Amount: 30, ClaimPrice: 60, ClaimAllowedAmount: pgtype.Float8{Float64: 30, Valid: true}, ClaimSettled: true},
+		{ID: uuid.New(), ClaimID: earlier, NPI: "2222222222", Kind: ReversalKindAdjustment, Amount: 4, ClaimPrice: 60, ClaimAllowedAmount: pgtype.Float8{Float64: 30, Valid: true}, ClaimSettled: true},
+	}
+
+	plans := PlanSettlement([]sqlc.Claim{paid, partial, reversed}, reversals)
+	require.Len(t, plans, 2)
+
+	// The first pharmacy is paid the allowed amount of one claim and the net of the other
+	require.Equal(t, "1111111111", plans[0].NPI)
+	require.Equal(t, int64(2), plans[0].ClaimCount)
+	require.Equal(t, 120.0, plans[0].PaymentAmount)
+	require.Zero(t, plans[0].ClawbackAmount)
+	require.Equal(t, 120.0, plans[0].NetAmount)
+	require.Equal(t, []string{SettlementItemPayment, SettlementItemPayment, SettlementItemReversal}, itemKinds(plans[0].Items))
+
+	// The fully reversed claim is left out, and the earlier claim's reversal is clawed back at
+	// the rate it was paid
+	require.Equal(t, "2222222222", plans[1].NPI)
+	require.Zero(t, plans[1].ClaimCount)
+	require.Equal(t, 2.0, plans[1].PaymentAmount)
+	require.Equal(t, 15.0, plans[1].ClawbackAmount)
+	require.Equal(t, -13.0, plans[1].NetAmount)
+	require.Equal(t, []string{SettlementItemClawback, SettlementItemAdjustment}, itemKinds(plans[1].Items))
+}
+
+func TestPayableAmount(t *testing.T) {
+	require.Equal(t, 100.0, PayableAmount(100, pgtype.Float8{}))
+	require.Equal(t, 80.0, PayableAmount(100, pgtype.Float8{Float64: 80, Valid: true}))
+	require.Equal(t, 100.0, PayableAmount(100, pgtype.Float8{Float64: 120, Valid: true}))
+}
+
+func itemKinds(items []SettlementItemPlan) []string {
+	kinds := make([]string, 0, len(items))
+	for _, item := range items {
+		kinds = append(kinds, item.Kind)
+	}
+	return kinds
+}
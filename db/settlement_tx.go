@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// CreateSettlementCycleTxResult is a stored settlement cycle and its payment batches
+type CreateSettlementCycleTxResult struct {
+	Cycle   sqlc.SettlementCycle
+	Batches []sqlc.SettlementBatch
+}
+
+// CreateSettlementCycleTx settles every claim, reversal and adjustment recorded before the cutoff
+// that has not been settled yet, storing one payment batch per pharmacy. Cycles are serialized so
+// that nothing is paid or clawed back twice.
+func (store *SQLStore) CreateSettlementCycleTx(ctx context.Context, cutoff time.Time) (CreateSettlementCycleTxResult, error) {
+	var result CreateSettlementCycleTxResult
+
+	err := store.execTx(ctx, func(q *sqlc.Queries) error {
+		if err := q.LockSettlement(ctx); err != nil {
+			return err
+		}
+
+		claims, err := q.ListUnsettledClaims(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+
+		reversals, err := q.ListUnsettledReversals(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+
+		result.Cycle, err = q.CreateSettlementCycle(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+
+		result.Batches = make([]sqlc.SettlementBatch, 0)
+		for _, plan := range PlanSettlement(claims, reversals) {
+			batch, err := q.CreateSettlementBatch(ctx, sqlc.CreateSettlementBatchParams{
+				CycleID:        result.Cycle.ID,
+				NPI:            plan.NPI,
+				ClaimCount:     plan.ClaimCount,
+				PaymentAmount:  plan.PaymentAmount,
+				ClawbackAmount: plan.ClawbackAmount,
+				NetAmount:      plan.NetAmount,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, item := range plan.Items {
+				_, err := q.CreateSettlementItem(ctx, sqlc.CreateSettlementItemParams{
+					BatchID:    batch.ID,
+					ClaimID:    item.ClaimID,
+					ReversalID: item.ReversalID,
+					Kind:       item.Kind,
+					Amount:     item.Amount,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			result.Batches = append(result.Batches, batch)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
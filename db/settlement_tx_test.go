@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsettledSkipsClaimsReversedBeforePayment(t *testing.T) {
+	store := requireStore(t)
+	ctx := context.Background()
+	claims := createTestClaims(t, store, 2)
+
+	_, err := store.CreateReversalTx(ctx, fullReversals(claims[0].ID)[0])
+	require.NoError(t, err)
+
+	cutoff := time.Now().Add(time.Minute)
+
+	unsettled, err := store.ListUnsettledClaims(ctx, cutoff)
+	require.NoError(t, err)
+
+	claimIDs := make(map[uuid.UUID]bool)
+	for _, claim := range unsettled {
+		claimIDs[claim.ID] = true
+	}
+	require.False(t, claimIDs[claims[0].ID])
+	require.True(t, claimIDs[claims[1].ID])
+
+	reversals, err := store.ListUnsettledReversals(ctx, cutoff)
+	require.NoError(t, err)
+	for _, reversal := range reversals {
+		require.NotEqual(t, claims[0].ID, reversal.ClaimID)
+	}
+
+	// A reversal recorded after the cutoff does not keep the claim from being paid
+	unsettled, err = store.ListUnsettledClaims(ctx, claims[1].Timestamp.Add(time.Microsecond))
+	require.NoError(t, err)
+	claimIDs = make(map[uuid.UUID]bool)
+	for _, claim := range unsettled {
+		claimIDs[claim.ID] = true
+	}
+	require.True(t, claimIDs[claims[0].ID])
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		arg := CreateAPIKeyParams{
+			Name:    "Test " + util.RandomString(8),
+			Prefix:  "pck_" + util.RandomString(8),
+			KeyHash: util.RandomString(64),
+			NPIs:    []string{util.RandomNumericString(10), util.RandomNumericString(10)},
+		}
+
+		key, err := txQueries.CreateAPIKey(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, arg.NPIs, key.NPIs)
+		require.False(t, key.Chain.Valid)
+		require.False(t, key.RevokedAt.Valid)
+
+		found, err := txQueries.GetAPIKeyByHash(context.Background(), arg.KeyHash)
+		require.NoError(t, err)
+		require.Equal(t, key.ID, found.ID)
+
+		revoked, err := txQueries.RevokeAPIKey(context.Background(), key.ID)
+		require.NoError(t, err)
+		require.True(t, revoked.RevokedAt.Valid)
+
+		// A revoked key cannot be revoked again
+		_, err = txQueries.RevokeAPIKey(context.Background(), key.ID)
+		require.Error(t, err)
+	})
+}
+
+func TestAPIKeyScope(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		chainKey, err := txQueries.CreateAPIKey(context.Background(), CreateAPIKeyParams{
+			Name:    "Test " + util.RandomString(8),
+			Prefix:  "pck_" + util.RandomString(8),
+			KeyHash: util.RandomString(64),
+			Chain:   pgtype.Text{String: "CVS", Valid: true},
+			NPIs:    []string{},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "CVS", chainKey.Chain.String)
+
+		keys, err := txQueries.ListAPIKeys(context.Background())
+		require.NoError(t, err)
+		require.NotEmpty(t, keys)
+
+		// A key must be scoped to either NPIs or a chain, not neither
+		_, err = txQueries.CreateAPIKey(context.Background(), CreateAPIKeyParams{
+			Name:    "Test " + util.RandomString(8),
+			Prefix:  "pck_" + util.RandomString(8),
+			KeyHash: util.RandomString(64),
+			NPIs:    []string{},
+		})
+		require.Error(t, err)
+	})
+}
@@ -7,24 +7,31 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createClaim = `-- name: CreateClaim :one
 INSERT INTO claims (
-  ndc, quantity, npi, price     
+  ndc, quantity, npi, price, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
-RETURNING id, ndc, quantity, npi, price, timestamp
+RETURNING id, ndc, quantity, npi, price, timestamp, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed
 `
 
 type CreateClaimParams struct {
-	NDC      string  `json:"ndc"`
-	Quantity int64   `json:"quantity"`
-	NPI      string  `json:"npi"`
-	Price    float64 `json:"price"`
+	NDC                 string        `json:"ndc"`
+	Quantity            int64         `json:"quantity"`
+	NPI                 string        `json:"npi"`
+	Price               float64       `json:"price"`
+	PossibleDuplicate   bool          `json:"possible_duplicate"`
+	Status              string        `json:"status"`
+	RejectCodes         []string      `json:"reject_codes"`
+	AllowedAmount       pgtype.Float8 `json:"allowed_amount"`
+	PriceExceedsAllowed bool          `json:"price_exceeds_allowed"`
 }
 
 func (q *Queries) CreateClaim(ctx context.Context, arg CreateClaimParams) (Claim, error) {
@@ -33,6 +40,11 @@ func (q *Queries) CreateClaim(ctx context.Context, arg CreateClaimParams) (Claim
 		arg.Quantity,
 		arg.NPI,
 		arg.Price,
+		arg.PossibleDuplicate,
+		arg.Status,
+		arg.RejectCodes,
+		arg.AllowedAmount,
+		arg.PriceExceedsAllowed,
 	)
 	var i Claim
 	err := row.Scan(
@@ -42,6 +54,11 @@ func (q *Queries) CreateClaim(ctx context.Context, arg CreateClaimParams) (Claim
 		&i.NPI,
 		&i.Price,
 		&i.Timestamp,
+		&i.PossibleDuplicate,
+		&i.Status,
+		&i.RejectCodes,
+		&i.AllowedAmount,
+		&i.PriceExceedsAllowed,
 	)
 	return i, err
 }
@@ -56,8 +73,51 @@ func (q *Queries) DeleteClaim(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const findDuplicateClaim = `-- name: FindDuplicateClaim :one
+SELECT id, ndc, quantity, npi, price, timestamp, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed FROM claims
+WHERE npi = $1
+  AND ndc = $2
+  AND quantity = $3
+  AND timestamp >= $4
+  AND status = 'approved'
+  AND claim_net_quantity(claims.id, claims.quantity) > 0
+ORDER BY timestamp DESC
+LIMIT 1
+`
+
+type FindDuplicateClaimParams struct {
+	NPI      string    `json:"npi"`
+	NDC      string    `json:"ndc"`
+	Quantity int64     `json:"quantity"`
+	Since    time.Time `json:"since"`
+}
+
+func (q *Queries) FindDuplicateClaim(ctx context.Context, arg FindDuplicateClaimParams) (Claim, error) {
+	row := q.db.QueryRow(ctx, findDuplicateClaim,
+		arg.NPI,
+		arg.NDC,
+		arg.Quantity,
+		arg.Since,
+	)
+	var i Claim
+	err := row.Scan(
+		&i.ID,
+		&i.NDC,
+		&i.Quantity,
+		&i.NPI,
+		&i.Price,
+		&i.Timestamp,
+		&i.PossibleDuplicate,
+		&i.Status,
+		&i.RejectCodes,
+		&i.AllowedAmount,
+		&i.PriceExceedsAllowed,
+	)
+	return i, err
+}
+
 const getClaim = `-- name: GetClaim :one
-SELECT id, ndc, quantity, npi, price, timestamp FROM claims
+SELECT id, ndc, quantity, npi, price, timestamp, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed FROM claims
 WHERE id = $1 LIMIT 1
 `
 
@@ -71,6 +131,84 @@ func (q *Queries) GetClaim(ctx context.Context, id uuid.UUID) (Claim, error) {
 		&i.NPI,
 		&i.Price,
 		&i.Timestamp,
+		&i.PossibleDuplicate,
+		&i.Status,
+		&i.RejectCodes,
+		&i.AllowedAmount,
+		&i.PriceExceedsAllowed,
+	)
+	return i, err
+}
+
+const getClaimForUpdate = `-- name: GetClaimForUpdate :one
+SELECT id, ndc, quantity, npi, price, timestamp, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed FROM claims
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetClaimForUpdate(ctx context.Context, id uuid.UUID) (Claim, error) {
+	row := q.db.QueryRow(ctx, getClaimForUpdate, id)
+	var i Claim
+	err := row.Scan(
+		&i.ID,
+		&i.NDC,
+		&i.Quantity,
+		&i.NPI,
+		&i.Price,
+		&i.Timestamp,
+		&i.PossibleDuplicate,
+		&i.Status,
+		&i.RejectCodes,
+		&i.AllowedAmount,
+		&i.PriceExceedsAllowed,
 	)
 	return i, err
 }
+
+const getLastApprovedFill = `-- name: GetLastApprovedFill :one
+SELECT id, ndc, quantity, npi, price, timestamp, possible_duplicate, status, reject_codes, allowed_amount, price_exceeds_allowed FROM claims
+WHERE npi = $1
+  AND ndc = $2
+  AND status = 'approved'
+  AND claim_net_quantity(claims.id, claims.quantity) > 0
+ORDER BY timestamp DESC
+LIMIT 1
+`
+
+type GetLastApprovedFillParams struct {
+	NPI string `json:"npi"`
+	NDC string `json:"ndc"`
+}
+
+func (q *Queries) GetLastApprovedFill(ctx context.Context, arg GetLastApprovedFillParams) (Claim, error) {
+	row := q.db.QueryRow(ctx, getLastApprovedFill, arg.NPI, arg.NDC)
+	var i Claim
+	err := row.Scan(
+		&i.ID,
+		&i.NDC,
+		&i.Quantity,
+		&i.NPI,
+		&i.Price,
+		&i.Timestamp,
+		&i.PossibleDuplicate,
+		&i.Status,
+		&i.RejectCodes,
+		&i.AllowedAmount,
+		&i.PriceExceedsAllowed,
+	)
+	return i, err
+}
+
+const lockClaimFill = `-- name: LockClaimFill :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text || ':' || $2::text))
+`
+
+type LockClaimFillParams struct {
+	NPI string `json:"npi"`
+	NDC string `json:"ndc"`
+}
+
+func (q *Queries) LockClaimFill(ctx context.Context, arg LockClaimFillParams) error {
+	_, err := q.db.Exec(ctx, lockClaimFill, arg.NPI, arg.NDC)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/pharmacy_claims_application/util"
 	"github.com/stretchr/testify/require"
 )
@@ -14,10 +15,12 @@ func createRandomClaim(t *testing.T) Claim {
 	pharmacy := createRandomPharmacy(t)
 
 	arg := CreateClaimParams{
-		NDC:      util.RandomString(11),
-		Price:    util.RandomMoney(),
-		Quantity: util.RandomInt(1, 1000),
-		NPI:      pharmacy.NPI, // Use the pharmacy's NPI
+		NDC:         util.RandomString(11),
+		Price:       util.RandomMoney(),
+		Quantity:    util.RandomInt(1, 1000),
+		NPI:         pharmacy.NPI, // Use the pharmacy's NPI
+		Status:      "approved",
+		RejectCodes: []string{},
 	}
 	claim, err := testQueries.CreateClaim(context.Background(), arg)
 	require.NoError(t, err)
@@ -47,10 +50,12 @@ func TestGetClaim(t *testing.T) {
 
 		// Create claim within transaction
 		claimArg := CreateClaimParams{
-			NDC:      util.RandomString(11),
-			Price:    util.RandomMoney(),
-			Quantity: util.RandomInt(1, 1000),
-			NPI:      pharmacy.NPI,
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
 		}
 		claim1, err := txQueries.CreateClaim(context.Background(), claimArg)
 		require.NoError(t, err)
@@ -85,10 +90,12 @@ func TestCreateClaimWithTransaction(t *testing.T) {
 
 		// Create claim within transaction
 		claimArg := CreateClaimParams{
-			NDC:      util.RandomString(11),
-			Price:    util.RandomMoney(),
-			Quantity: util.RandomInt(1, 1000),
-			NPI:      pharmacy.NPI,
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
 		}
 		claim, err := txQueries.CreateClaim(context.Background(), claimArg)
 		require.NoError(t, err)
@@ -110,3 +117,148 @@ func TestCreateClaimWithTransaction(t *testing.T) {
 	})
 	// Transaction is automatically rolled back here, so no data persists
 }
+
+func TestFindDuplicateClaim(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		// Create pharmacy within transaction
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claimArg := CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		}
+		claim, err := txQueries.CreateClaim(context.Background(), claimArg)
+		require.NoError(t, err)
+		require.False(t, claim.PossibleDuplicate)
+
+		findArg := FindDuplicateClaimParams{
+			NPI:      claimArg.NPI,
+			NDC:      claimArg.NDC,
+			Quantity: claimArg.Quantity,
+			Since:    time.Now().Add(-time.Hour),
+		}
+
+		// The same fill is found within the window
+		duplicate, err := txQueries.FindDuplicateClaim(context.Background(), findArg)
+		require.NoError(t, err)
+		require.Equal(t, claim.ID, duplicate.ID)
+
+		// A different quantity is not a duplicate
+		otherQuantity := findArg
+		otherQuantity.Quantity++
+		_, err = txQueries.FindDuplicateClaim(context.Background(), otherQuantity)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		// Reversed claims are not duplicates
+		_, err = txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "reversal",
+			Quantity:   claim.Quantity,
+			Amount:     claim.Price,
+			ReasonCode: "BILLED_IN_ERROR",
+		})
+		require.NoError(t, err)
+
+		_, err = txQueries.FindDuplicateClaim(context.Background(), findArg)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestFindDuplicateClaimRemainingQuantity(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claim, err := txQueries.CreateClaim(context.Background(), CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       100,
+			Quantity:    util.RandomInt(2, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		})
+		require.NoError(t, err)
+
+		findArg := FindDuplicateClaimParams{
+			NPI:      claim.NPI,
+			NDC:      claim.NDC,
+			Quantity: claim.Quantity,
+			Since:    time.Now().Add(-time.Hour),
+		}
+		reverse := func(kind string, quantity int64, amount float64) {
+			_, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+				ClaimID:    claim.ID,
+				Kind:       kind,
+				Quantity:   quantity,
+				Amount:     amount,
+				ReasonCode: "BILLED_IN_ERROR",
+			})
+			require.NoError(t, err)
+		}
+
+		// Partial and amount-only reversals leave quantity on the claim
+		reverse("reversal", claim.Quantity-1, 10)
+		reverse("reversal", 0, 5)
+		duplicate, err := txQueries.FindDuplicateClaim(context.Background(), findArg)
+		require.NoError(t, err)
+		require.Equal(t, claim.ID, duplicate.ID)
+
+		// An adjustment adds quantity, so reversing the original remainder is not enough
+		reverse("adjustment", 1, 0)
+		reverse("reversal", 1, 0)
+		_, err = txQueries.FindDuplicateClaim(context.Background(), findArg)
+		require.NoError(t, err)
+
+		// Nothing remains once the adjusted quantity is reversed too
+		reverse("reversal", 1, 0)
+		_, err = txQueries.FindDuplicateClaim(context.Background(), findArg)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestGetLastApprovedFill(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claimArg := CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		}
+		approved, err := txQueries.CreateClaim(context.Background(), claimArg)
+		require.NoError(t, err)
+
+		// Rejected claims are never the last fill
+		rejectedArg := claimArg
+		rejectedArg.Status = "rejected"
+		rejectedArg.RejectCodes = []string{"79"}
+		rejected, err := txQueries.CreateClaim(context.Background(), rejectedArg)
+		require.NoError(t, err)
+		require.Equal(t, []string{"79"}, rejected.RejectCodes)
+
+		last, err := txQueries.GetLastApprovedFill(context.Background(), GetLastApprovedFillParams{
+			NPI: claimArg.NPI,
+			NDC: claimArg.NDC,
+		})
+		require.NoError(t, err)
+		require.Equal(t, approved.ID, last.ID)
+	})
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEffectiveContract(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		chain := "Test " + util.RandomString(8)
+		renewal := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		first, err := txQueries.CreateContract(context.Background(), CreateContractParams{
+			Chain:           chain,
+			DiscountPercent: 10,
+			DispensingFee:   1.5,
+			EffectiveFrom:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EffectiveTo:     pgtype.Timestamptz{Time: renewal, Valid: true},
+		})
+		require.NoError(t, err)
+
+		second, err := txQueries.CreateContract(context.Background(), CreateContractParams{
+			Chain:           chain,
+			DiscountPercent: 12,
+			DispensingFee:   1.25,
+			EffectiveFrom:   renewal,
+		})
+		require.NoError(t, err)
+
+		contract, err := txQueries.GetEffectiveContract(context.Background(), GetEffectiveContractParams{
+			Chain:       chain,
+			ServiceDate: renewal.Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		require.Equal(t, first.ID, contract.ID)
+
+		contract, err = txQueries.GetEffectiveContract(context.Background(), GetEffectiveContractParams{
+			Chain:       chain,
+			ServiceDate: renewal.Add(24 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, second.ID, contract.ID)
+
+		// Adjacent contracts do not overlap, but an open-ended one from the same date does
+		_, err = txQueries.FindOverlappingContract(context.Background(), FindOverlappingContractParams{
+			Chain:         chain,
+			ExcludeID:     uuid.Nil,
+			EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EffectiveTo:   pgtype.Timestamptz{Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		})
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		overlap, err := txQueries.FindOverlappingContract(context.Background(), FindOverlappingContractParams{
+			Chain:         chain,
+			ExcludeID:     uuid.Nil,
+			EffectiveFrom: renewal,
+		})
+		require.NoError(t, err)
+		require.Equal(t, second.ID, overlap.ID)
+
+		// A contract never overlaps itself
+		_, err = txQueries.FindOverlappingContract(context.Background(), FindOverlappingContractParams{
+			Chain:         chain,
+			ExcludeID:     second.ID,
+			EffectiveFrom: renewal,
+		})
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestChainPricingReport(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: "Test " + util.RandomString(8),
+		})
+		require.NoError(t, err)
+
+		claimArg := CreateClaimParams{
+			NDC:           util.RandomNumericString(11),
+			Price:         12,
+			Quantity:      30,
+			NPI:           pharmacy.NPI,
+			Status:        "approved",
+			RejectCodes:   []string{},
+			AllowedAmount: pgtype.Float8{Float64: 10, Valid: true},
+		}
+		_, err = txQueries.CreateClaim(context.Background(), claimArg)
+		require.NoError(t, err)
+
+		// Unpriced claims count towards the submitted total only
+		unpriced := claimArg
+		unpriced.AllowedAmount = pgtype.Float8{}
+		_, err = txQueries.CreateClaim(context.Background(), unpriced)
+		require.NoError(t, err)
+
+		// Rejected claims are left out
+		rejected := claimArg
+		rejected.Status = "rejected"
+		rejected.RejectCodes = []string{"78"}
+		_, err = txQueries.CreateClaim(context.Background(), rejected)
+		require.NoError(t, err)
+
+		rows, err := txQueries.ChainPricingReport(context.Background(), ChainPricingReportParams{
+			Chain: pgtype.Text{String: pharmacy.Chain, Valid: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		require.Equal(t, int64(2), rows[0].ClaimCount)
+		require.Equal(t, int64(1), rows[0].PricedCount)
+		require.Equal(t, 24.0, rows[0].SubmittedTotal)
+		require.Equal(t, 12.0, rows[0].PricedSubmittedTotal)
+		require.Equal(t, 10.0, rows[0].ContractedTotal)
+	})
+}
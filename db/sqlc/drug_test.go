@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrugCRUD(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		arg := CreateDrugParams{
+			NDC:           util.RandomNumericString(11),
+			Name:          "Test " + util.RandomString(8),
+			Strength:      "500 mg",
+			PackageSize:   100,
+			UnitOfMeasure: "EA",
+			Active:        true,
+		}
+
+		drug, err := txQueries.CreateDrug(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, arg.NDC, drug.NDC)
+		require.Equal(t, arg.PackageSize, drug.PackageSize)
+
+		// Upsert replaces the existing row
+		_, err = txQueries.UpsertDrug(context.Background(), UpsertDrugParams{
+			NDC:           arg.NDC,
+			Name:          arg.Name,
+			Strength:      "250 mg",
+			PackageSize:   30,
+			UnitOfMeasure: "EA",
+			Active:        false,
+			QuantityLimit: 90,
+		})
+		require.NoError(t, err)
+
+		drug, err = txQueries.GetDrug(context.Background(), arg.NDC)
+		require.NoError(t, err)
+		require.Equal(t, "250 mg", drug.Strength)
+		require.False(t, drug.Active)
+		require.Equal(t, int64(90), drug.QuantityLimit)
+
+		drugs, err := txQueries.ListDrugs(context.Background(), ListDrugsParams{
+			Active:   pgtype.Bool{Bool: false, Valid: true},
+			Name:     pgtype.Text{String: arg.Name, Valid: true},
+			RowLimit: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, drugs, 1)
+
+		drug, err = txQueries.UpdateDrug(context.Background(), UpdateDrugParams{
+			NDC:    arg.NDC,
+			Name:   arg.Name,
+			Active: true,
+		})
+		require.NoError(t, err)
+		require.True(t, drug.Active)
+		require.Zero(t, drug.QuantityLimit)
+
+		deleted, err := txQueries.DeleteDrug(context.Background(), arg.NDC)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		_, err = txQueries.GetDrug(context.Background(), arg.NDC)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		// Create pharmacy within transaction
+		pharmacyArg := CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		}
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), pharmacyArg)
+		require.NoError(t, err)
+
+		// Create claim within transaction
+		claimArg := CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		}
+		claim, err := txQueries.CreateClaim(context.Background(), claimArg)
+		require.NoError(t, err)
+
+		// Record the response under a key
+		keyArg := CreateIdempotencyKeyParams{
+			Key:            util.RandomString(32),
+			RequestHash:    util.RandomString(64),
+			ClaimID:        claim.ID,
+			ResponseStatus: 201,
+			ResponseBody:   []byte(`{"status":"claim submitted"}`),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		key, err := txQueries.CreateIdempotencyKey(context.Background(), keyArg)
+		require.NoError(t, err)
+		require.Equal(t, keyArg.Key, key.Key)
+		require.Equal(t, claim.ID, key.ClaimID)
+		require.NotZero(t, key.CreatedAt)
+
+		// Test retrieval within the same transaction
+		retrieved, err := txQueries.GetIdempotencyKey(context.Background(), key.Key)
+		require.NoError(t, err)
+		require.Equal(t, key.RequestHash, retrieved.RequestHash)
+		require.Equal(t, key.ResponseStatus, retrieved.ResponseStatus)
+		require.JSONEq(t, string(keyArg.ResponseBody), string(retrieved.ResponseBody))
+	})
+}
+
+func TestGetIdempotencyKeyExpired(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claim, err := txQueries.CreateClaim(context.Background(), CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		})
+		require.NoError(t, err)
+
+		key, err := txQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+			Key:            util.RandomString(32),
+			RequestHash:    util.RandomString(64),
+			ClaimID:        claim.ID,
+			ResponseStatus: 201,
+			ResponseBody:   []byte(`{}`),
+			ExpiresAt:      time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+
+		// Expired keys are not returned
+		_, err = txQueries.GetIdempotencyKey(context.Background(), key.Key)
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		// and are removed by the purge
+		deleted, err := txQueries.DeleteExpiredIdempotencyKeys(context.Background())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, deleted, int64(1))
+	})
+}
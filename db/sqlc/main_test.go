@@ -72,10 +72,12 @@ func createTestData(t *testing.T) (Pharmacy, Claim, Reversal) {
 // createRandomClaimWithPharmacy creates a claim using the provided pharmacy
 func createRandomClaimWithPharmacy(t *testing.T, pharmacy Pharmacy) Claim {
 	arg := CreateClaimParams{
-		NDC:      util.RandomString(11),
-		Price:    util.RandomMoney(),
-		Quantity: util.RandomInt(1, 1000),
-		NPI:      pharmacy.NPI,
+		NDC:         util.RandomString(11),
+		Price:       util.RandomMoney(),
+		Quantity:    util.RandomInt(1, 1000),
+		NPI:         pharmacy.NPI,
+		Status:      "approved",
+		RejectCodes: []string{},
 	}
 	claim, err := testQueries.CreateClaim(context.Background(), arg)
 	require.NoError(t, err)
@@ -94,7 +96,13 @@ func createRandomClaimWithPharmacy(t *testing.T, pharmacy Pharmacy) Claim {
 
 // createRandomReversalWithClaim creates a reversal using the provided claim
 func createRandomReversalWithClaim(t *testing.T, claim Claim) Reversal {
-	reversal, err := testQueries.CreateReversal(context.Background(), claim.ID)
+	reversal, err := testQueries.CreateReversal(context.Background(), CreateReversalParams{
+		ClaimID:    claim.ID,
+		Kind:       "reversal",
+		Quantity:   claim.Quantity,
+		Amount:     claim.Price,
+		ReasonCode: "BILLED_IN_ERROR",
+	})
 	require.NoError(t, err)
 	require.NotEmpty(t, reversal)
 
@@ -8,25 +8,122 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type APIKey struct {
+	ID        uuid.UUID          `json:"id"`
+	Name      string             `json:"name"`
+	Prefix    string             `json:"prefix"`
+	KeyHash   string             `json:"key_hash"`
+	Chain     pgtype.Text        `json:"chain"`
+	NPIs      []string           `json:"npis"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
 type Claim struct {
-	ID        uuid.UUID `json:"id"`
-	NDC       string    `json:"ndc"`
-	Quantity  int64     `json:"quantity"`
-	NPI       string    `json:"npi"`
-	Price     float64   `json:"price"`
-	Timestamp time.Time `json:"timestamp"`
+	ID                  uuid.UUID     `json:"id"`
+	NDC                 string        `json:"ndc"`
+	Quantity            int64         `json:"quantity"`
+	NPI                 string        `json:"npi"`
+	Price               float64       `json:"price"`
+	Timestamp           time.Time     `json:"timestamp"`
+	PossibleDuplicate   bool          `json:"possible_duplicate"`
+	Status              string        `json:"status"`
+	RejectCodes         []string      `json:"reject_codes"`
+	AllowedAmount       pgtype.Float8 `json:"allowed_amount"`
+	PriceExceedsAllowed bool          `json:"price_exceeds_allowed"`
+}
+
+type Contract struct {
+	ID              uuid.UUID          `json:"id"`
+	Chain           string             `json:"chain"`
+	DiscountPercent float64            `json:"discount_percent"`
+	DispensingFee   float64            `json:"dispensing_fee"`
+	EffectiveFrom   time.Time          `json:"effective_from"`
+	EffectiveTo     pgtype.Timestamptz `json:"effective_to"`
+	Timestamp       time.Time          `json:"timestamp"`
+}
+
+type Drug struct {
+	NDC           string    `json:"ndc"`
+	Name          string    `json:"name"`
+	Strength      string    `json:"strength"`
+	PackageSize   float64   `json:"package_size"`
+	UnitOfMeasure string    `json:"unit_of_measure"`
+	Active        bool      `json:"active"`
+	Timestamp     time.Time `json:"timestamp"`
+	QuantityLimit int64     `json:"quantity_limit"`
+}
+
+type IdempotencyKey struct {
+	Key            string    `json:"key"`
+	RequestHash    string    `json:"request_hash"`
+	ClaimID        uuid.UUID `json:"claim_id"`
+	ResponseStatus int32     `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
 }
 
 type Pharmacy struct {
 	NPI       string    `json:"npi"`
 	Chain     string    `json:"chain"`
 	Timestamp time.Time `json:"timestamp"`
+	Active    bool      `json:"active"`
+}
+
+type ReferencePrice struct {
+	ID            uuid.UUID          `json:"id"`
+	NDC           string             `json:"ndc"`
+	UnitPrice     float64            `json:"unit_price"`
+	EffectiveFrom time.Time          `json:"effective_from"`
+	EffectiveTo   pgtype.Timestamptz `json:"effective_to"`
+	Timestamp     time.Time          `json:"timestamp"`
 }
 
 type Reversal struct {
+	ID         uuid.UUID `json:"id"`
+	ClaimID    uuid.UUID `json:"claim_id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Kind       string    `json:"kind"`
+	Quantity   int64     `json:"quantity"`
+	Amount     float64   `json:"amount"`
+	ReasonCode string    `json:"reason_code"`
+	Notes      string    `json:"notes"`
+	Actor      string    `json:"actor"`
+}
+
+type ReversalReasonCode struct {
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type SettlementBatch struct {
+	ID             uuid.UUID `json:"id"`
+	CycleID        uuid.UUID `json:"cycle_id"`
+	NPI            string    `json:"npi"`
+	ClaimCount     int64     `json:"claim_count"`
+	PaymentAmount  float64   `json:"payment_amount"`
+	ClawbackAmount float64   `json:"clawback_amount"`
+	NetAmount      float64   `json:"net_amount"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+type SettlementCycle struct {
 	ID        uuid.UUID `json:"id"`
-	ClaimID   uuid.UUID `json:"claim_id"`
+	Cutoff    time.Time `json:"cutoff"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+type SettlementItem struct {
+	ID         uuid.UUID   `json:"id"`
+	BatchID    uuid.UUID   `json:"batch_id"`
+	ClaimID    uuid.UUID   `json:"claim_id"`
+	ReversalID pgtype.UUID `json:"reversal_id"`
+	Kind       string      `json:"kind"`
+	Amount     float64     `json:"amount"`
+}
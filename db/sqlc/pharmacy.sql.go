@@ -26,7 +26,7 @@ INSERT INTO pharmacies (
 ) VALUES (
   $1, $2
 )
-RETURNING npi, chain, timestamp
+RETURNING npi, chain, timestamp, active
 `
 
 type CreatePharmacyParams struct {
@@ -37,18 +37,52 @@ type CreatePharmacyParams struct {
 func (q *Queries) CreatePharmacy(ctx context.Context, arg CreatePharmacyParams) (Pharmacy, error) {
 	row := q.db.QueryRow(ctx, createPharmacy, arg.NPI, arg.Chain)
 	var i Pharmacy
-	err := row.Scan(&i.NPI, &i.Chain, &i.Timestamp)
+	err := row.Scan(
+		&i.NPI,
+		&i.Chain,
+		&i.Timestamp,
+		&i.Active,
+	)
 	return i, err
 }
 
 const getPharmacy = `-- name: GetPharmacy :one
-SELECT npi, chain, timestamp FROM pharmacies
+SELECT npi, chain, timestamp, active FROM pharmacies
 WHERE npi = $1 LIMIT 1
 `
 
 func (q *Queries) GetPharmacy(ctx context.Context, npi string) (Pharmacy, error) {
 	row := q.db.QueryRow(ctx, getPharmacy, npi)
 	var i Pharmacy
-	err := row.Scan(&i.NPI, &i.Chain, &i.Timestamp)
+	err := row.Scan(
+		&i.NPI,
+		&i.Chain,
+		&i.Timestamp,
+		&i.Active,
+	)
+	return i, err
+}
+
+const updatePharmacyActive = `-- name: UpdatePharmacyActive :one
+UPDATE pharmacies
+SET active = $2
+WHERE npi = $1
+RETURNING npi, chain, timestamp, active
+`
+
+type UpdatePharmacyActiveParams struct {
+	NPI    string `json:"npi"`
+	Active bool   `json:"active"`
+}
+
+func (q *Queries) UpdatePharmacyActive(ctx context.Context, arg UpdatePharmacyActiveParams) (Pharmacy, error) {
+	row := q.db.QueryRow(ctx, updatePharmacyActive, arg.NPI, arg.Active)
+	var i Pharmacy
+	err := row.Scan(
+		&i.NPI,
+		&i.Chain,
+		&i.Timestamp,
+		&i.Active,
+	)
 	return i, err
 }
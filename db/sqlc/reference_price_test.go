@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReferencePrice(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		drug, err := txQueries.CreateDrug(context.Background(), CreateDrugParams{
+			NDC:    util.RandomNumericString(11),
+			Name:   "Test " + util.RandomString(8),
+			Active: true,
+		})
+		require.NoError(t, err)
+
+		cutover := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		old, err := txQueries.CreateReferencePrice(context.Background(), CreateReferencePriceParams{
+			NDC:           drug.NDC,
+			UnitPrice:     0.10,
+			EffectiveFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EffectiveTo:   pgtype.Timestamptz{Time: cutover, Valid: true},
+		})
+		require.NoError(t, err)
+
+		current, err := txQueries.CreateReferencePrice(context.Background(), CreateReferencePriceParams{
+			NDC:           drug.NDC,
+			UnitPrice:     0.12,
+			EffectiveFrom: cutover,
+		})
+		require.NoError(t, err)
+
+		// The price in effect depends on the service date
+		price, err := txQueries.GetReferencePrice(context.Background(), GetReferencePriceParams{
+			NDC:         drug.NDC,
+			ServiceDate: cutover.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, old.ID, price.ID)
+
+		price, err = txQueries.GetReferencePrice(context.Background(), GetReferencePriceParams{
+			NDC:         drug.NDC,
+			ServiceDate: cutover,
+		})
+		require.NoError(t, err)
+		require.Equal(t, current.ID, price.ID)
+
+		// Nothing is in effect before the first price
+		_, err = txQueries.GetReferencePrice(context.Background(), GetReferencePriceParams{
+			NDC:         drug.NDC,
+			ServiceDate: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		})
+		require.ErrorIs(t, err, pgx.ErrNoRows)
+
+		prices, err := txQueries.ListReferencePricesByNDC(context.Background(), drug.NDC)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		require.Equal(t, current.ID, prices[0].ID)
+	})
+}
@@ -9,21 +9,50 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createReversal = `-- name: CreateReversal :one
 INSERT INTO reversals (
-  claim_id  
+  claim_id, kind, quantity, amount, reason_code, notes, actor
 ) VALUES (
-  $1
+  $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, claim_id, timestamp
+RETURNING id, claim_id, timestamp, kind, quantity, amount, reason_code, notes, actor
 `
 
-func (q *Queries) CreateReversal(ctx context.Context, claimID uuid.UUID) (Reversal, error) {
-	row := q.db.QueryRow(ctx, createReversal, claimID)
+type CreateReversalParams struct {
+	ClaimID    uuid.UUID `json:"claim_id"`
+	Kind       string    `json:"kind"`
+	Quantity   int64     `json:"quantity"`
+	Amount     float64   `json:"amount"`
+	ReasonCode string    `json:"reason_code"`
+	Notes      string    `json:"notes"`
+	Actor      string    `json:"actor"`
+}
+
+func (q *Queries) CreateReversal(ctx context.Context, arg CreateReversalParams) (Reversal, error) {
+	row := q.db.QueryRow(ctx, createReversal,
+		arg.ClaimID,
+		arg.Kind,
+		arg.Quantity,
+		arg.Amount,
+		arg.ReasonCode,
+		arg.Notes,
+		arg.Actor,
+	)
 	var i Reversal
-	err := row.Scan(&i.ID, &i.ClaimID, &i.Timestamp)
+	err := row.Scan(
+		&i.ID,
+		&i.ClaimID,
+		&i.Timestamp,
+		&i.Kind,
+		&i.Quantity,
+		&i.Amount,
+		&i.ReasonCode,
+		&i.Notes,
+		&i.Actor,
+	)
 	return i, err
 }
 
@@ -38,13 +67,118 @@ func (q *Queries) DeleteReversal(ctx context.Context, id uuid.UUID) error {
 }
 
 const getReversalByClaimID = `-- name: GetReversalByClaimID :one
-SELECT id, claim_id, timestamp FROM reversals
+SELECT id, claim_id, timestamp, kind, quantity, amount, reason_code, notes, actor FROM reversals
 WHERE claim_id = $1 LIMIT 1
 `
 
 func (q *Queries) GetReversalByClaimID(ctx context.Context, claimID uuid.UUID) (Reversal, error) {
 	row := q.db.QueryRow(ctx, getReversalByClaimID, claimID)
 	var i Reversal
-	err := row.Scan(&i.ID, &i.ClaimID, &i.Timestamp)
+	err := row.Scan(
+		&i.ID,
+		&i.ClaimID,
+		&i.Timestamp,
+		&i.Kind,
+		&i.Quantity,
+		&i.Amount,
+		&i.ReasonCode,
+		&i.Notes,
+		&i.Actor,
+	)
 	return i, err
 }
+
+const listReversals = `-- name: ListReversals :many
+SELECT id, claim_id, timestamp, kind, quantity, amount, reason_code, notes, actor FROM reversals
+WHERE ($1::varchar IS NULL OR reason_code = $1)
+  AND ($2::varchar IS NULL OR actor = $2)
+  AND ($3::uuid IS NULL OR claim_id = $3)
+  AND ($4::timestamptz IS NULL OR timestamp >= $4)
+  AND ($5::timestamptz IS NULL OR timestamp < $5)
+ORDER BY timestamp DESC, id
+LIMIT $6 OFFSET $7
+`
+
+type ListReversalsParams struct {
+	ReasonCode pgtype.Text        `json:"reason_code"`
+	Actor      pgtype.Text        `json:"actor"`
+	ClaimID    pgtype.UUID        `json:"claim_id"`
+	Since      pgtype.Timestamptz `json:"since"`
+	Until      pgtype.Timestamptz `json:"until"`
+	RowLimit   int32              `json:"row_limit"`
+	RowOffset  int32              `json:"row_offset"`
+}
+
+func (q *Queries) ListReversals(ctx context.Context, arg ListReversalsParams) ([]Reversal, error) {
+	rows, err := q.db.Query(ctx, listReversals,
+		arg.ReasonCode,
+		arg.Actor,
+		arg.ClaimID,
+		arg.Since,
+		arg.Until,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reversal
+	for rows.Next() {
+		var i Reversal
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClaimID,
+			&i.Timestamp,
+			&i.Kind,
+			&i.Quantity,
+			&i.Amount,
+			&i.ReasonCode,
+			&i.Notes,
+			&i.Actor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReversalsByClaimID = `-- name: ListReversalsByClaimID :many
+SELECT id, claim_id, timestamp, kind, quantity, amount, reason_code, notes, actor FROM reversals
+WHERE claim_id = $1
+ORDER BY timestamp, id
+`
+
+func (q *Queries) ListReversalsByClaimID(ctx context.Context, claimID uuid.UUID) ([]Reversal, error) {
+	rows, err := q.db.Query(ctx, listReversalsByClaimID, claimID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reversal
+	for rows.Next() {
+		var i Reversal
+		if err := rows.Scan(
+			&i.ID,
+			&i.ClaimID,
+			&i.Timestamp,
+			&i.Kind,
+			&i.Quantity,
+			&i.Amount,
+			&i.ReasonCode,
+			&i.Notes,
+			&i.Actor,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAndUpdateReversalReasonCode(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		code := "TEST_" + strings.ToUpper(util.RandomString(8))
+
+		created, err := txQueries.CreateReversalReasonCode(context.Background(), CreateReversalReasonCodeParams{
+			Code:        code,
+			Description: "Created by test",
+			Active:      true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, code, created.Code)
+		require.True(t, created.Active)
+
+		// Deactivate and reword the code
+		updated, err := txQueries.UpdateReversalReasonCode(context.Background(), UpdateReversalReasonCodeParams{
+			Code:        code,
+			Description: "Retired by test",
+			Active:      false,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Retired by test", updated.Description)
+		require.False(t, updated.Active)
+
+		codes, err := txQueries.ListReversalReasonCodes(context.Background())
+		require.NoError(t, err)
+
+		found := false
+		for _, c := range codes {
+			if c.Code == code {
+				found = true
+				require.False(t, c.Active)
+			}
+		}
+		require.True(t, found)
+	})
+}
+
+func TestListReversalsFilters(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claim, err := txQueries.CreateClaim(context.Background(), CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		})
+		require.NoError(t, err)
+
+		actor := util.RandomString(12)
+		reversal, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "reversal",
+			Quantity:   claim.Quantity,
+			Amount:     claim.Price,
+			ReasonCode: "NOT_DISPENSED",
+			Notes:      "Patient never picked up",
+			Actor:      actor,
+		})
+		require.NoError(t, err)
+
+		reversals, err := txQueries.ListReversals(context.Background(), ListReversalsParams{
+			ReasonCode: pgtype.Text{String: "NOT_DISPENSED", Valid: true},
+			Actor:      pgtype.Text{String: actor, Valid: true},
+			ClaimID:    pgtype.UUID{Bytes: claim.ID, Valid: true},
+			RowLimit:   10,
+		})
+		require.NoError(t, err)
+		require.Len(t, reversals, 1)
+		require.Equal(t, reversal.ID, reversals[0].ID)
+		require.Equal(t, "Patient never picked up", reversals[0].Notes)
+
+		// A different reason code excludes the reversal
+		reversals, err = txQueries.ListReversals(context.Background(), ListReversalsParams{
+			ReasonCode: pgtype.Text{String: "WRONG_DRUG", Valid: true},
+			ClaimID:    pgtype.UUID{Bytes: claim.ID, Valid: true},
+			RowLimit:   10,
+		})
+		require.NoError(t, err)
+		require.Empty(t, reversals)
+	})
+}
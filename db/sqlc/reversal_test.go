@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/pharmacy_claims_application/util"
 	"github.com/stretchr/testify/require"
 )
@@ -13,7 +14,13 @@ func createRandomReversal(t *testing.T) Reversal {
 	// First create a claim
 	claim := createRandomClaim(t)
 
-	reversal, err := testQueries.CreateReversal(context.Background(), claim.ID)
+	reversal, err := testQueries.CreateReversal(context.Background(), CreateReversalParams{
+		ClaimID:    claim.ID,
+		Kind:       "reversal",
+		Quantity:   claim.Quantity,
+		Amount:     claim.Price,
+		ReasonCode: "BILLED_IN_ERROR",
+	})
 	require.NoError(t, err)
 	require.NotEmpty(t, reversal)
 
@@ -38,17 +45,25 @@ func TestCreateReversal(t *testing.T) {
 
 		// Create claim within transaction
 		claimArg := CreateClaimParams{
-			NDC:      util.RandomString(11),
-			Price:    util.RandomMoney(),
-			Quantity: util.RandomInt(1, 1000),
-			NPI:      pharmacy.NPI,
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
 		}
 		claim, err := txQueries.CreateClaim(context.Background(), claimArg)
 		require.NoError(t, err)
 		require.NotEmpty(t, claim)
 
 		// Create reversal within transaction
-		reversal, err := txQueries.CreateReversal(context.Background(), claim.ID)
+		reversal, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "reversal",
+			Quantity:   claim.Quantity,
+			Amount:     claim.Price,
+			ReasonCode: "BILLED_IN_ERROR",
+		})
 		require.NoError(t, err)
 		require.NotEmpty(t, reversal)
 
@@ -71,17 +86,25 @@ func TestGetReversalByClaimID(t *testing.T) {
 
 		// Create claim within transaction
 		claimArg := CreateClaimParams{
-			NDC:      util.RandomString(11),
-			Price:    util.RandomMoney(),
-			Quantity: util.RandomInt(1, 1000),
-			NPI:      pharmacy.NPI,
+			NDC:         util.RandomString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
 		}
 		claim, err := txQueries.CreateClaim(context.Background(), claimArg)
 		require.NoError(t, err)
 		require.NotEmpty(t, claim)
 
 		// Create reversal within transaction
-		reversal1, err := txQueries.CreateReversal(context.Background(), claim.ID)
+		reversal1, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "reversal",
+			Quantity:   claim.Quantity,
+			Amount:     claim.Price,
+			ReasonCode: "BILLED_IN_ERROR",
+		})
 		require.NoError(t, err)
 		require.NotEmpty(t, reversal1)
 
@@ -94,3 +117,55 @@ func TestGetReversalByClaimID(t *testing.T) {
 		require.WithinDuration(t, reversal1.Timestamp, reversal2.Timestamp, time.Second)
 	})
 }
+
+func TestListReversalsByClaimID(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claim, err := txQueries.CreateClaim(context.Background(), CreateClaimParams{
+			NDC:         util.RandomString(11),
+			Price:       100,
+			Quantity:    30,
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		})
+		require.NoError(t, err)
+
+		// Partial reversal followed by an adjustment
+		partial, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "reversal",
+			Quantity:   10,
+			Amount:     33.33,
+			ReasonCode: "WRONG_QUANTITY",
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(10), partial.Quantity)
+
+		adjustment, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "adjustment",
+			Amount:     5,
+			ReasonCode: "BILLED_IN_ERROR",
+		})
+		require.NoError(t, err)
+
+		reversals, err := txQueries.ListReversalsByClaimID(context.Background(), claim.ID)
+		require.NoError(t, err)
+		require.Len(t, reversals, 2)
+
+		// Both rows share the transaction timestamp, so compare by ID
+		byID := map[uuid.UUID]Reversal{}
+		for _, reversal := range reversals {
+			byID[reversal.ID] = reversal
+		}
+		require.Equal(t, partial.ReasonCode, byID[partial.ID].ReasonCode)
+		require.Equal(t, "adjustment", byID[adjustment.ID].Kind)
+		require.Equal(t, 5.0, byID[adjustment.ID].Amount)
+	})
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettlementTracking(t *testing.T) {
+	runTestWithTransaction(t, func(t *testing.T, txQueries *Queries) {
+		pharmacy, err := txQueries.CreatePharmacy(context.Background(), CreatePharmacyParams{
+			NPI:   util.RandomNumericString(10),
+			Chain: util.RandomString(10),
+		})
+		require.NoError(t, err)
+
+		claim, err := txQueries.CreateClaim(context.Background(), CreateClaimParams{
+			NDC:         util.RandomNumericString(11),
+			Price:       util.RandomMoney(),
+			Quantity:    util.RandomInt(1, 1000),
+			NPI:         pharmacy.NPI,
+			Status:      "approved",
+			RejectCodes: []string{},
+		})
+		require.NoError(t, err)
+
+		cutoff := time.Now().Add(time.Minute)
+
+		claims, err := txQueries.ListUnsettledClaims(context.Background(), cutoff)
+		require.NoError(t, err)
+		require.Contains(t, claimIDs(claims), claim.ID)
+
+		cycle, err := txQueries.CreateSettlementCycle(context.Background(), cutoff)
+		require.NoError(t, err)
+
+		batch, err := txQueries.CreateSettlementBatch(context.Background(), CreateSettlementBatchParams{
+			CycleID:       cycle.ID,
+			NPI:           pharmacy.NPI,
+			ClaimCount:    1,
+			PaymentAmount: claim.Price,
+			NetAmount:     claim.Price,
+		})
+		require.NoError(t, err)
+
+		_, err = txQueries.CreateSettlementItem(context.Background(), CreateSettlementItemParams{
+			BatchID: batch.ID,
+			ClaimID: claim.ID,
+			Kind:    "payment",
+			Amount:  claim.Price,
+		})
+		require.NoError(t, err)
+
+		// A paid claim is no longer unsettled
+		claims, err = txQueries.ListUnsettledClaims(context.Background(), cutoff)
+		require.NoError(t, err)
+		require.NotContains(t, claimIDs(claims), claim.ID)
+
+		// A later reversal is unsettled against a settled claim
+		reversal, err := txQueries.CreateReversal(context.Background(), CreateReversalParams{
+			ClaimID:    claim.ID,
+			Kind:       "reversal",
+			Quantity:   claim.Quantity,
+			Amount:     claim.Price,
+			ReasonCode: "BILLED_IN_ERROR",
+		})
+		require.NoError(t, err)
+
+		reversals, err := txQueries.ListUnsettledReversals(context.Background(), cutoff)
+		require.NoError(t, err)
+
+		found := false
+		for _, row := range reversals {
+			if row.ID == reversal.ID {
+				found = true
+				require.True(t, row.ClaimSettled)
+				require.Equal(t, pharmacy.NPI, row.NPI)
+			}
+		}
+		require.True(t, found)
+
+		items, err := txQueries.ListSettlementItemsByBatch(context.Background(), batch.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		require.Equal(t, claim.NDC, items[0].NDC)
+	})
+}
+
+func claimIDs(claims []Claim) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(claims))
+	for _, claim := range claims {
+		ids = append(ids, claim.ID)
+	}
+	return ids
+}
@@ -2,24 +2,68 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	sqlc "github.com/pharmacy_claims_application/db/sqlc"
 )
 
-// Store defines all functions to execute database queries and transactions
+// Store defines all functions to execute database queries and transactions. Database errors
+// are returned as an *Error matching one of the store error kinds, such as ErrNotFound.
 type Store interface {
 	CreateClaim(ctx context.Context, arg sqlc.CreateClaimParams) (sqlc.Claim, error)
 	GetClaim(ctx context.Context, id uuid.UUID) (sqlc.Claim, error)
-	CreateReversal(ctx context.Context, claimID uuid.UUID) (sqlc.Reversal, error)
+	CreateReversal(ctx context.Context, arg sqlc.CreateReversalParams) (sqlc.Reversal, error)
+	ListReversalsByClaimID(ctx context.Context, claimID uuid.UUID) ([]sqlc.Reversal, error)
+	ListReversals(ctx context.Context, arg sqlc.ListReversalsParams) ([]sqlc.Reversal, error)
+	CreateReversalReasonCode(ctx context.Context, arg sqlc.CreateReversalReasonCodeParams) (sqlc.ReversalReasonCode, error)
+	GetReversalReasonCode(ctx context.Context, code string) (sqlc.ReversalReasonCode, error)
+	ListReversalReasonCodes(ctx context.Context) ([]sqlc.ReversalReasonCode, error)
+	UpdateReversalReasonCode(ctx context.Context, arg sqlc.UpdateReversalReasonCodeParams) (sqlc.ReversalReasonCode, error)
 	CreatePharmacy(ctx context.Context, arg sqlc.CreatePharmacyParams) (sqlc.Pharmacy, error)
 	GetPharmacy(ctx context.Context, npi string) (sqlc.Pharmacy, error)
 	CountPharmacies(ctx context.Context) (int64, error)
-	CreateClaimTx(ctx context.Context, arg sqlc.CreateClaimParams) (sqlc.Claim, error)
-	CreateReversalTx(ctx context.Context, claimID uuid.UUID) (sqlc.Reversal, error)
+	UpdatePharmacyActive(ctx context.Context, arg sqlc.UpdatePharmacyActiveParams) (sqlc.Pharmacy, error)
+	CreateDrug(ctx context.Context, arg sqlc.CreateDrugParams) (sqlc.Drug, error)
+	GetDrug(ctx context.Context, ndc string) (sqlc.Drug, error)
+	ListDrugs(ctx context.Context, arg sqlc.ListDrugsParams) ([]sqlc.Drug, error)
+	UpdateDrug(ctx context.Context, arg sqlc.UpdateDrugParams) (sqlc.Drug, error)
+	UpsertDrug(ctx context.Context, arg sqlc.UpsertDrugParams) (sqlc.Drug, error)
+	DeleteDrug(ctx context.Context, ndc string) (int64, error)
+	CreateReferencePrice(ctx context.Context, arg sqlc.CreateReferencePriceParams) (sqlc.ReferencePrice, error)
+	ListReferencePricesByNDC(ctx context.Context, ndc string) ([]sqlc.ReferencePrice, error)
+	GetContract(ctx context.Context, id uuid.UUID) (sqlc.Contract, error)
+	ListContracts(ctx context.Context, chain pgtype.Text) ([]sqlc.Contract, error)
+	ChainPricingReport(ctx context.Context, arg sqlc.ChainPricingReportParams) ([]sqlc.ChainPricingReportRow, error)
+	GetSettlementCycle(ctx context.Context, id uuid.UUID) (sqlc.SettlementCycle, error)
+	ListSettlementCycles(ctx context.Context, arg sqlc.ListSettlementCyclesParams) ([]sqlc.SettlementCycle, error)
+	GetSettlementBatch(ctx context.Context, id uuid.UUID) (sqlc.SettlementBatch, error)
+	ListSettlementBatchesByCycle(ctx context.Context, cycleID uuid.UUID) ([]sqlc.SettlementBatch, error)
+	ListSettlementBatchesByNPI(ctx context.Context, arg sqlc.ListSettlementBatchesByNPIParams) ([]sqlc.SettlementBatch, error)
+	ListSettlementItemsByBatch(ctx context.Context, batchID uuid.UUID) ([]sqlc.ListSettlementItemsByBatchRow, error)
+	CreateAPIKey(ctx context.Context, arg sqlc.CreateAPIKeyParams) (sqlc.APIKey, error)
+	GetAPIKey(ctx context.Context, id uuid.UUID) (sqlc.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (sqlc.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]sqlc.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (sqlc.APIKey, error)
+	CreateClaimTx(ctx context.Context, arg CreateClaimTxParams) (CreateClaimTxResult, error)
+	CreateReversalTx(ctx context.Context, arg CreateReversalTxParams) (CreateReversalTxResult, error)
+	CreateReversalBatchTx(ctx context.Context, args []CreateReversalTxParams, allOrNothing bool) ([]CreateReversalBatchItem, error)
+	CreateClaimBatchTx(ctx context.Context, args []CreateClaimTxParams) ([]CreateClaimBatchItem, error)
+	CreateSettlementCycleTx(ctx context.Context, cutoff time.Time) (CreateSettlementCycleTxResult, error)
+	CreateContractTx(ctx context.Context, arg sqlc.CreateContractParams) (sqlc.Contract, error)
+	UpdateContractTx(ctx context.Context, arg sqlc.UpdateContractParams) (sqlc.Contract, error)
+	CreateClaimIdempotentTx(ctx context.Context, arg CreateClaimIdempotentTxParams) (CreateClaimIdempotentTxResult, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, bool, error)
 }
 
+// SchemaVersion is the migration version this build of the application expects
+const SchemaVersion int64 = 13
+
 // SQLStore provides all functions to execute SQL queries and transactions
 type SQLStore struct {
 	connPool *pgxpool.Pool
@@ -34,79 +78,215 @@ func NewStore(connPool *pgxpool.Pool) Store {
 	}
 }
 
-// CreateClaimTx creates a new claim within a database transaction
-func (store *SQLStore) CreateClaimTx(ctx context.Context, arg sqlc.CreateClaimParams) (sqlc.Claim, error) {
-	var result sqlc.Claim
-
-	err := store.execTx(ctx, func(q *sqlc.Queries) error {
-		var err error
-
-		result, err = q.CreateClaim(ctx, arg)
-		return err
-	})
+// CreateClaim creates a new claim
+func (store *SQLStore) CreateClaim(ctx context.Context, arg sqlc.CreateClaimParams) (sqlc.Claim, error) {
+	return translate(store.Queries.CreateClaim(ctx, arg))
+}
 
-	return result, err
+// GetClaim gets a claim by ID
+func (store *SQLStore) GetClaim(ctx context.Context, id uuid.UUID) (sqlc.Claim, error) {
+	return translate(store.Queries.GetClaim(ctx, id))
 }
 
-// CreateReversalTx creates a new reversal within a database transaction
-func (store *SQLStore) CreateReversalTx(ctx context.Context, claimID uuid.UUID) (sqlc.Reversal, error) {
-	var result sqlc.Reversal
+// CreateReversal creates a new reversal
+func (store *SQLStore) CreateReversal(ctx context.Context, arg sqlc.CreateReversalParams) (sqlc.Reversal, error) {
+	return translate(store.Queries.CreateReversal(ctx, arg))
+}
 
-	err := store.execTx(ctx, func(q *sqlc.Queries) error {
-		var err error
+// ListReversalsByClaimID lists the reversals and adjustments of a claim, oldest first
+func (store *SQLStore) ListReversalsByClaimID(ctx context.Context, claimID uuid.UUID) ([]sqlc.Reversal, error) {
+	return translate(store.Queries.ListReversalsByClaimID(ctx, claimID))
+}
 
-		result, err = q.CreateReversal(ctx, claimID)
-		return err
-	})
+// ListReversals lists reversals matching the optional filters, newest first
+func (store *SQLStore) ListReversals(ctx context.Context, arg sqlc.ListReversalsParams) ([]sqlc.Reversal, error) {
+	return translate(store.Queries.ListReversals(ctx, arg))
+}
 
-	return result, err
+// CreateReversalReasonCode adds a reason code to the managed list
+func (store *SQLStore) CreateReversalReasonCode(ctx context.Context, arg sqlc.CreateReversalReasonCodeParams) (sqlc.ReversalReasonCode, error) {
+	return translate(store.Queries.CreateReversalReasonCode(ctx, arg))
 }
 
-// CreateClaim creates a new claim
-func (store *SQLStore) CreateClaim(ctx context.Context, arg sqlc.CreateClaimParams) (sqlc.Claim, error) {
-	return store.Queries.CreateClaim(ctx, arg)
+// GetReversalReasonCode gets a reason code
+func (store *SQLStore) GetReversalReasonCode(ctx context.Context, code string) (sqlc.ReversalReasonCode, error) {
+	return translate(store.Queries.GetReversalReasonCode(ctx, code))
 }
 
-// GetClaim gets a claim by ID
-func (store *SQLStore) GetClaim(ctx context.Context, id uuid.UUID) (sqlc.Claim, error) {
-	return store.Queries.GetClaim(ctx, id)
+// ListReversalReasonCodes lists every reason code, including inactive ones
+func (store *SQLStore) ListReversalReasonCodes(ctx context.Context) ([]sqlc.ReversalReasonCode, error) {
+	return translate(store.Queries.ListReversalReasonCodes(ctx))
 }
 
-// CreateReversal creates a new reversal
-func (store *SQLStore) CreateReversal(ctx context.Context, claimID uuid.UUID) (sqlc.Reversal, error) {
-	return store.Queries.CreateReversal(ctx, claimID)
+// UpdateReversalReasonCode changes the description or active flag of a reason code
+func (store *SQLStore) UpdateReversalReasonCode(ctx context.Context, arg sqlc.UpdateReversalReasonCodeParams) (sqlc.ReversalReasonCode, error) {
+	return translate(store.Queries.UpdateReversalReasonCode(ctx, arg))
 }
 
 // CreatePharmacy creates a new pharmacy
 func (store *SQLStore) CreatePharmacy(ctx context.Context, arg sqlc.CreatePharmacyParams) (sqlc.Pharmacy, error) {
-	return store.Queries.CreatePharmacy(ctx, arg)
+	return translate(store.Queries.CreatePharmacy(ctx, arg))
 }
 
 // GetPharmacy gets a pharmacy by NPI
 func (store *SQLStore) GetPharmacy(ctx context.Context, npi string) (sqlc.Pharmacy, error) {
-	return store.Queries.GetPharmacy(ctx, npi)
+	return translate(store.Queries.GetPharmacy(ctx, npi))
 }
 
 // CountPharmacies counts the total number of pharmacies
 func (store *SQLStore) CountPharmacies(ctx context.Context) (int64, error) {
-	return store.Queries.CountPharmacies(ctx)
+	return translate(store.Queries.CountPharmacies(ctx))
+}
+
+// UpdatePharmacyActive activates or deactivates a pharmacy
+func (store *SQLStore) UpdatePharmacyActive(ctx context.Context, arg sqlc.UpdatePharmacyActiveParams) (sqlc.Pharmacy, error) {
+	return translate(store.Queries.UpdatePharmacyActive(ctx, arg))
+}
+
+// CreateDrug adds a product to the drug reference table
+func (store *SQLStore) CreateDrug(ctx context.Context, arg sqlc.CreateDrugParams) (sqlc.Drug, error) {
+	return translate(store.Queries.CreateDrug(ctx, arg))
+}
+
+// GetDrug gets a product by NDC
+func (store *SQLStore) GetDrug(ctx context.Context, ndc string) (sqlc.Drug, error) {
+	return translate(store.Queries.GetDrug(ctx, ndc))
+}
+
+// ListDrugs lists products, optionally filtered by active flag and name
+func (store *SQLStore) ListDrugs(ctx context.Context, arg sqlc.ListDrugsParams) ([]sqlc.Drug, error) {
+	return translate(store.Queries.ListDrugs(ctx, arg))
+}
+
+// UpdateDrug replaces the details of a product
+func (store *SQLStore) UpdateDrug(ctx context.Context, arg sqlc.UpdateDrugParams) (sqlc.Drug, error) {
+	return translate(store.Queries.UpdateDrug(ctx, arg))
+}
+
+// UpsertDrug adds a product or replaces its details
+func (store *SQLStore) UpsertDrug(ctx context.Context, arg sqlc.UpsertDrugParams) (sqlc.Drug, error) {
+	return translate(store.Queries.UpsertDrug(ctx, arg))
+}
+
+// DeleteDrug removes a product and reports how many rows were deleted
+func (store *SQLStore) DeleteDrug(ctx context.Context, ndc string) (int64, error) {
+	return translate(store.Queries.DeleteDrug(ctx, ndc))
+}
+
+// CreateReferencePrice adds a unit price for an NDC over an effective date range
+func (store *SQLStore) CreateReferencePrice(ctx context.Context, arg sqlc.CreateReferencePriceParams) (sqlc.ReferencePrice, error) {
+	return translate(store.Queries.CreateReferencePrice(ctx, arg))
+}
+
+// ListReferencePricesByNDC lists the reference prices of an NDC, most recent first
+func (store *SQLStore) ListReferencePricesByNDC(ctx context.Context, ndc string) ([]sqlc.ReferencePrice, error) {
+	return translate(store.Queries.ListReferencePricesByNDC(ctx, ndc))
+}
+
+// GetContract gets a contract by ID
+func (store *SQLStore) GetContract(ctx context.Context, id uuid.UUID) (sqlc.Contract, error) {
+	return translate(store.Queries.GetContract(ctx, id))
+}
+
+// ListContracts lists contracts, optionally for one chain
+func (store *SQLStore) ListContracts(ctx context.Context, chain pgtype.Text) ([]sqlc.Contract, error) {
+	return translate(store.Queries.ListContracts(ctx, chain))
+}
+
+// ChainPricingReport totals submitted and contracted amounts of approved claims per chain
+func (store *SQLStore) ChainPricingReport(ctx context.Context, arg sqlc.ChainPricingReportParams) ([]sqlc.ChainPricingReportRow, error) {
+	return translate(store.Queries.ChainPricingReport(ctx, arg))
+}
+
+// GetSettlementCycle gets a settlement cycle by ID
+func (store *SQLStore) GetSettlementCycle(ctx context.Context, id uuid.UUID) (sqlc.SettlementCycle, error) {
+	return translate(store.Queries.GetSettlementCycle(ctx, id))
+}
+
+// ListSettlementCycles lists settlement cycles, latest cutoff first
+func (store *SQLStore) ListSettlementCycles(ctx context.Context, arg sqlc.ListSettlementCyclesParams) ([]sqlc.SettlementCycle, error) {
+	return translate(store.Queries.ListSettlementCycles(ctx, arg))
+}
+
+// GetSettlementBatch gets a payment batch by ID
+func (store *SQLStore) GetSettlementBatch(ctx context.Context, id uuid.UUID) (sqlc.SettlementBatch, error) {
+	return translate(store.Queries.GetSettlementBatch(ctx, id))
+}
+
+// ListSettlementBatchesByCycle lists the payment batches of a cycle by NPI
+func (store *SQLStore) ListSettlementBatchesByCycle(ctx context.Context, cycleID uuid.UUID) ([]sqlc.SettlementBatch, error) {
+	return translate(store.Queries.ListSettlementBatchesByCycle(ctx, cycleID))
+}
+
+// ListSettlementBatchesByNPI lists the payment batches of a pharmacy, newest first
+func (store *SQLStore) ListSettlementBatchesByNPI(ctx context.Context, arg sqlc.ListSettlementBatchesByNPIParams) ([]sqlc.SettlementBatch, error) {
+	return translate(store.Queries.ListSettlementBatchesByNPI(ctx, arg))
+}
+
+// ListSettlementItemsByBatch lists the items of a payment batch with their claims
+func (store *SQLStore) ListSettlementItemsByBatch(ctx context.Context, batchID uuid.UUID) ([]sqlc.ListSettlementItemsByBatchRow, error) {
+	return translate(store.Queries.ListSettlementItemsByBatch(ctx, batchID))
+}
+
+// CreateAPIKey stores a new API key
+func (store *SQLStore) CreateAPIKey(ctx context.Context, arg sqlc.CreateAPIKeyParams) (sqlc.APIKey, error) {
+	return translate(store.Queries.CreateAPIKey(ctx, arg))
+}
+
+// GetAPIKey retrieves an API key by ID
+func (store *SQLStore) GetAPIKey(ctx context.Context, id uuid.UUID) (sqlc.APIKey, error) {
+	return translate(store.Queries.GetAPIKey(ctx, id))
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret
+func (store *SQLStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (sqlc.APIKey, error) {
+	return translate(store.Queries.GetAPIKeyByHash(ctx, keyHash))
+}
+
+// ListAPIKeys retrieves every API key, newest first
+func (store *SQLStore) ListAPIKeys(ctx context.Context) ([]sqlc.APIKey, error) {
+	return translate(store.Queries.ListAPIKeys(ctx))
+}
+
+// RevokeAPIKey revokes an API key that is still active
+func (store *SQLStore) RevokeAPIKey(ctx context.Context, id uuid.UUID) (sqlc.APIKey, error) {
+	return translate(store.Queries.RevokeAPIKey(ctx, id))
+}
+
+// DeleteExpiredIdempotencyKeys removes idempotency keys past their expiry
+func (store *SQLStore) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return translate(store.Queries.DeleteExpiredIdempotencyKeys(ctx))
+}
+
+// Ping verifies that a connection to the database can be acquired
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return TranslateError(store.connPool.Ping(ctx))
+}
+
+// MigrationVersion returns the version and dirty flag recorded by golang-migrate
+func (store *SQLStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	var version int64
+	var dirty bool
+
+	err := store.connPool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return version, dirty, TranslateError(err)
 }
 
-// execTx executes a function within a database transaction
+// execTx executes a function within a database transaction and translates its error
 func (store *SQLStore) execTx(ctx context.Context, fn func(*sqlc.Queries) error) error {
 	tx, err := store.connPool.Begin(ctx)
 	if err != nil {
-		return err
+		return TranslateError(err)
 	}
 
 	q := sqlc.New(tx)
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
-			return rbErr
+			return TranslateError(rbErr)
 		}
-		return err
+		return TranslateError(err)
 	}
 
-	return tx.Commit(ctx)
+	return TranslateError(tx.Commit(ctx))
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pharmacy_claims_application/claimfile"
+	"github.com/pharmacy_claims_application/server"
+)
+
+// runIngest implements the ingest subcommand:
+//
+//	pharmacy_claims_application ingest [-ack path] <claim file>
+//
+// The acknowledgement is written to -ack, or to standard output when -ack is "-".
+func runIngest(server *server.Server, args []string) error {
+	flags := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	ackPath := flags.String("ack", "", "acknowledgement file to write (default: <claim file>.ack, - for stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: ingest [-ack path] <claim file>")
+	}
+
+	path := flags.Arg(0)
+	if *ackPath == "" {
+		*ackPath = path + ".ack"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ack, err := server.IngestClaimFile(context.Background(), file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *ackPath != "-" {
+		ackFile, err := os.Create(*ackPath)
+		if err != nil {
+			return err
+		}
+		defer ackFile.Close()
+		out = ackFile
+	}
+
+	if err := claimfile.WriteAck(out, ack); err != nil {
+		return err
+	}
+
+	accepted := ack.Accepted()
+	log.Printf("Ingested %s: %d accepted, %d rejected, acknowledgement written to %s", path, accepted, len(ack.Results)-accepted, *ackPath)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/metrics"
 )
 
 // EventType represents the type of event
@@ -19,6 +20,24 @@ const (
 	EventClaimReversed  EventType = "claim_reversed"
 )
 
+var (
+	eventWriteDuration = metrics.NewHistogramVec(
+		"pharmacy_event_log_write_duration_seconds",
+		"Time taken to append an event to the event log.",
+		metrics.DefaultBuckets,
+		"type",
+	)
+	eventWriteFailures = metrics.NewCounterVec(
+		"pharmacy_event_log_write_failures_total",
+		"Number of events that could not be written to the event log.",
+		"type",
+	)
+)
+
+func init() {
+	metrics.MustRegister(eventWriteDuration, eventWriteFailures)
+}
+
 // Event represents a logged event
 type Event struct {
 	ID        string                 `json:"id"`
@@ -49,38 +68,120 @@ func NewLogger(logDir string) (*Logger, error) {
 
 // LogClaimSubmission logs a claim submission event
 func (l *Logger) LogClaimSubmission(claimID uuid.UUID, ndc, npi string, quantity int, price float64) error {
-	event := Event{
+	return l.logEvents(EventClaimSubmitted, []Event{claimSubmittedEvent(ClaimSubmission{
+		ClaimID:  claimID,
+		NDC:      ndc,
+		NPI:      npi,
+		Quantity: quantity,
+		Price:    price,
+	})})
+}
+
+// ClaimSubmission describes a submitted claim written to the event log
+type ClaimSubmission struct {
+	ClaimID  uuid.UUID
+	NDC      string
+	NPI      string
+	Quantity int
+	Price    float64
+}
+
+// LogClaimSubmissions logs the submission events of a batch of claims in a single write
+func (l *Logger) LogClaimSubmissions(submissions []ClaimSubmission) error {
+	events := make([]Event, len(submissions))
+	for i, submission := range submissions {
+		events[i] = claimSubmittedEvent(submission)
+	}
+
+	return l.logEvents(EventClaimSubmitted, events)
+}
+
+// claimSubmittedEvent builds the event of a claim submission
+func claimSubmittedEvent(submission ClaimSubmission) Event {
+	return Event{
 		ID:        uuid.New().String(),
 		Type:      EventClaimSubmitted,
 		Timestamp: time.Now().UTC(),
 		Data: map[string]interface{}{
-			"claim_id": claimID.String(),
-			"ndc":      ndc,
-			"npi":      npi,
-			"quantity": quantity,
-			"price":    price,
+			"claim_id": submission.ClaimID.String(),
+			"ndc":      submission.NDC,
+			"npi":      submission.NPI,
+			"quantity": submission.Quantity,
+			"price":    submission.Price,
 		},
 	}
+}
+
+// ReversalEvent describes a reversal or adjustment written to the event log
+type ReversalEvent struct {
+	ClaimID    uuid.UUID
+	ReversalID uuid.UUID
+	Kind       string
+	Quantity   int64
+	Amount     float64
+	ReasonCode string
+	Notes      string
+	Actor      string
+	// WindowOverride is set when the reversal was accepted after its reversal window had closed
+	WindowOverride bool
+}
+
+// LogClaimReversal logs a claim reversal or adjustment event
+func (l *Logger) LogClaimReversal(reversal ReversalEvent) error {
+	return l.logEvents(EventClaimReversed, []Event{claimReversedEvent(reversal)})
+}
+
+// LogClaimReversals logs the events of a batch of reversals in a single write
+func (l *Logger) LogClaimReversals(reversals []ReversalEvent) error {
+	events := make([]Event, len(reversals))
+	for i, reversal := range reversals {
+		events[i] = claimReversedEvent(reversal)
+	}
 
-	return l.logEvent(event)
+	return l.logEvents(EventClaimReversed, events)
 }
 
-// LogClaimReversal logs a claim reversal event
-func (l *Logger) LogClaimReversal(claimID uuid.UUID) error {
-	event := Event{
+// claimReversedEvent builds the event of a reversal or adjustment
+func claimReversedEvent(reversal ReversalEvent) Event {
+	return Event{
 		ID:        uuid.New().String(),
 		Type:      EventClaimReversed,
 		Timestamp: time.Now().UTC(),
 		Data: map[string]interface{}{
-			"claim_id": claimID.String(),
+			"claim_id":        reversal.ClaimID.String(),
+			"reversal_id":     reversal.ReversalID.String(),
+			"kind":            reversal.Kind,
+			"quantity":        reversal.Quantity,
+			"amount":          reversal.Amount,
+			"reason_code":     reversal.ReasonCode,
+			"notes":           reversal.Notes,
+			"actor":           reversal.Actor,
+			"window_override": reversal.WindowOverride,
 		},
 	}
+}
+
+// logEvents writes events of one type to the log file and records write metrics. The file is
+// rewritten once however many events there are, so batches should be logged in one call.
+func (l *Logger) logEvents(eventType EventType, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+
+	err := l.appendEvents(events)
+
+	eventWriteDuration.Observe(time.Since(start).Seconds(), string(eventType))
+	if err != nil {
+		eventWriteFailures.Add(float64(len(events)), string(eventType))
+	}
 
-	return l.logEvent(event)
+	return err
 }
 
-// logEvent writes an event to the log file
-func (l *Logger) logEvent(event Event) error {
+// appendEvents adds events to the end of the log file
+func (l *Logger) appendEvents(newEvents []Event) error {
 	l.mutex.Lock()
 	defer l.mutex.Unlock()
 
@@ -90,8 +191,8 @@ func (l *Logger) logEvent(event Event) error {
 		return fmt.Errorf("failed to read existing events: %w", err)
 	}
 
-	// Add new event
-	events = append(events, event)
+	// Add new events
+	events = append(events, newEvents...)
 
 	// Write back to file
 	return l.writeEvents(events)
@@ -138,6 +239,23 @@ func (l *Logger) writeEvents(events []Event) error {
 	return nil
 }
 
+// CheckWritable verifies that the log directory accepts new files
+func (l *Logger) CheckWritable() error {
+	file, err := os.CreateTemp(filepath.Dir(l.filePath), ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("log directory is not writable: %w", err)
+	}
+
+	name := file.Name()
+	file.Close()
+
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove write check file: %w", err)
+	}
+
+	return nil
+}
+
 // GetEvents retrieves all logged events
 func (l *Logger) GetEvents() ([]Event, error) {
 	l.mutex.Lock()
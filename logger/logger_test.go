@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogClaimSubmissions(t *testing.T) {
+	l, err := NewLogger(t.TempDir())
+	require.NoError(t, err)
+
+	// An empty batch does not touch the log
+	require.NoError(t, l.LogClaimSubmissions(nil))
+	_, err = os.Stat(l.filePath)
+	require.True(t, os.IsNotExist(err))
+
+	first := uuid.New()
+	require.NoError(t, l.LogClaimSubmission(first, "00002323401", "1234567890", 30, 15.99))
+
+	submissions := make([]ClaimSubmission, 3)
+	for i := range submissions {
+		submissions[i] = ClaimSubmission{ClaimID: uuid.New(), NDC: "00002323401", NPI: "9876543210", Quantity: i + 1, Price: 10}
+	}
+	require.NoError(t, l.LogClaimSubmissions(submissions))
+
+	// Batches are appended after the existing events, in order
+	events, err := l.GetEventsByType(EventClaimSubmitted)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, first.String(), events[0].Data["claim_id"])
+	for i, submission := range submissions {
+		require.Equal(t, submission.ClaimID.String(), events[i+1].Data["claim_id"])
+		require.Equal(t, float64(i+1), events[i+1].Data["quantity"])
+	}
+}
+
+func TestLogClaimReversals(t *testing.T) {
+	l, err := NewLogger(t.TempDir())
+	require.NoError(t, err)
+
+	reversals := []ReversalEvent{
+		{ClaimID: uuid.New(), ReversalID: uuid.New(), Kind: "reversal", ReasonCode: "DUPLICATE", Actor: "admin"},
+		{ClaimID: uuid.New(), ReversalID: uuid.New(), Kind: "reversal", ReasonCode: "DUPLICATE", Actor: "admin", WindowOverride: true},
+	}
+	require.NoError(t, l.LogClaimReversals(reversals))
+
+	events, err := l.GetEventsByType(EventClaimReversed)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, reversals[1].ReversalID.String(), events[1].Data["reversal_id"])
+	require.Equal(t, true, events[1].Data["window_override"])
+}
@@ -8,8 +8,11 @@ import (
 	"syscall"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/auth"
 	"github.com/pharmacy_claims_application/db"
 	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/pricing"
 	"github.com/pharmacy_claims_application/seeder"
 	"github.com/pharmacy_claims_application/server"
 	"github.com/pharmacy_claims_application/util"
@@ -29,6 +32,9 @@ func main() {
 	}
 	defer conn.Close()
 
+	// Expose connection pool statistics on /metrics
+	db.RegisterPoolMetrics(conn)
+
 	// Create database store
 	store := db.NewStore(conn)
 
@@ -43,12 +49,48 @@ func main() {
 		log.Printf("Warning: failed to seed pharmacies: %v", err)
 	}
 
+	// Import drug product files
+	if err := seeder.SeedDrugs(store, "data"); err != nil {
+		log.Printf("Warning: failed to import drugs: %v", err)
+	}
+
+	// Build the adjudication rules
+	adjudicator, err := adjudication.NewEngineFromConfig(config)
+	if err != nil {
+		log.Fatal("cannot configure adjudication:", err)
+	}
+
+	// Check what happens to duplicate claims
+	if _, err := db.ParseDuplicatePolicy(config.DuplicateClaimPolicy); err != nil {
+		log.Fatal("cannot configure duplicate detection:", err)
+	}
+
+	// Build the reference pricer
+	pricer, err := pricing.NewPricerFromConfig(config)
+	if err != nil {
+		log.Fatal("cannot configure pricing:", err)
+	}
+
+	// Load the keys bearer tokens are verified with
+	verifier, err := auth.NewVerifierFromConfig(config)
+	if err != nil {
+		log.Fatal("cannot configure token verification:", err)
+	}
+
 	// Create and start server
-	server := server.NewServer(store, eventLogger)
+	server := server.NewServer(config, store, eventLogger, adjudicator, pricer, verifier)
+
+	// Ingest a claim file instead of serving when asked to
+	if len(os.Args) > 1 && os.Args[1] == "ingest" {
+		if err := runIngest(server, os.Args[2:]); err != nil {
+			log.Fatal("cannot ingest claim file:", err)
+		}
+		return
+	}
 
 	// Start server in a goroutine
 	go func() {
-		if err := server.Start(config); err != nil {
+		if err := server.Start(); err != nil {
 			log.Fatal("cannot start server:", err)
 		}
 	}()
@@ -0,0 +1,366 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Collector is a metric family that can render itself in Prometheus text format
+type Collector interface {
+	Name() string
+	Write(w io.Writer) error
+}
+
+// Registry holds a set of collectors exposed together
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []Collector
+	names      map[string]bool
+}
+
+// DefaultRegistry is the registry served by the application's /metrics endpoint
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]bool),
+	}
+}
+
+// Register adds collectors to the registry, rejecting duplicate metric names
+func (r *Registry) Register(collectors ...Collector) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, c := range collectors {
+		if r.names[c.Name()] {
+			return fmt.Errorf("metric %s is already registered", c.Name())
+		}
+		r.names[c.Name()] = true
+		r.collectors = append(r.collectors, c)
+	}
+
+	return nil
+}
+
+// MustRegister adds collectors to the registry and panics on duplicates
+func (r *Registry) MustRegister(collectors ...Collector) {
+	if err := r.Register(collectors...); err != nil {
+		panic(err)
+	}
+}
+
+// Write renders every registered collector in Prometheus text format
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	collectors := make([]Collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mutex.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool {
+		return collectors[i].Name() < collectors[j].Name()
+	})
+
+	for _, c := range collectors {
+		if err := c.Write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler serves the registry in Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		buf := bufio.NewWriter(w)
+		if err := r.Write(buf); err != nil {
+			http.Error(w, "Failed to encode metrics", http.StatusInternalServerError)
+			return
+		}
+		buf.Flush()
+	})
+}
+
+// MustRegister adds collectors to the default registry
+func MustRegister(collectors ...Collector) {
+	DefaultRegistry.MustRegister(collectors...)
+}
+
+// labelSet tracks the label names of a metric family and encodes their values
+type labelSet struct {
+	names []string
+}
+
+// key joins label values into a map key, checking the number of values
+func (l labelSet) key(values []string) string {
+	if len(values) != len(l.names) {
+		panic(fmt.Sprintf("expected %d label values, got %d", len(l.names), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// format renders label values as {name="value",...}, with optional extra pairs appended
+func (l labelSet) format(key string, extra ...string) string {
+	var pairs []string
+
+	if len(l.names) > 0 {
+		values := strings.Split(key, "\xff")
+		for i, name := range l.names {
+			pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeLabelValue(values[i])))
+		}
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", extra[i], escapeLabelValue(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels
+type CounterVec struct {
+	name   string
+	help   string
+	labels labelSet
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec creates a counter with the given label names
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labelSet{names: labels},
+		values: make(map[string]float64),
+	}
+}
+
+// Name returns the metric name
+func (c *CounterVec) Name() string {
+	return c.name
+}
+
+// Inc increments the counter for the given label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by delta
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("counter cannot decrease")
+	}
+
+	key := c.labels.key(labelValues)
+
+	c.mutex.Lock()
+	c.values[key] += delta
+	c.mutex.Unlock()
+}
+
+// Value returns the current counter value for the given label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	key := c.labels.key(labelValues)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.values[key]
+}
+
+// Write renders the counter in Prometheus text format
+func (c *CounterVec) Write(w io.Writer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(c.values) {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, c.labels.format(key), formatValue(c.values[key])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DefaultBuckets are latency buckets in seconds suited to HTTP handlers and file writes
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogramSeries holds the observations of one label combination
+type histogramSeries struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec samples observations into buckets partitioned by labels
+type HistogramVec struct {
+	name    string
+	help    string
+	buckets []float64
+	labels  labelSet
+	mutex   sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// NewHistogramVec creates a histogram with the given upper bounds and label names
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := make([]float64, len(buckets))
+	copy(sorted, buckets)
+	sort.Float64s(sorted)
+
+	return &HistogramVec{
+		name:    name,
+		help:    help,
+		buckets: sorted,
+		labels:  labelSet{names: labels},
+		series:  make(map[string]*histogramSeries),
+	}
+}
+
+// Name returns the metric name
+func (h *HistogramVec) Name() string {
+	return h.name
+}
+
+// Observe records a single value for the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.labels.key(labelValues)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// Count returns the number of observations for the given label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	key := h.labels.key(labelValues)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+// Write renders the histogram in Prometheus text format
+func (h *HistogramVec) Write(w io.Writer) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		for i, bound := range h.buckets {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labels.format(key, "le", formatValue(bound)), s.counts[i]); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labels.format(key, "le", "+Inf"), s.count); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labels.format(key), formatValue(s.sum)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labels.format(key), s.count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValueFunc is a metric whose value is read from a callback at scrape time
+type ValueFunc struct {
+	name       string
+	help       string
+	metricType string
+	fn         func() float64
+}
+
+// NewGaugeFunc creates a gauge that reports the value returned by fn
+func NewGaugeFunc(name, help string, fn func() float64) *ValueFunc {
+	return &ValueFunc{name: name, help: help, metricType: "gauge", fn: fn}
+}
+
+// NewCounterFunc creates a counter that reports the cumulative value returned by fn
+func NewCounterFunc(name, help string, fn func() float64) *ValueFunc {
+	return &ValueFunc{name: name, help: help, metricType: "counter", fn: fn}
+}
+
+// Name returns the metric name
+func (f *ValueFunc) Name() string {
+	return f.name
+}
+
+// Write renders the current value in Prometheus text format
+func (f *ValueFunc) Write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", f.name, f.help, f.name, f.metricType, f.name, formatValue(f.fn()))
+	return err
+}
+
+// sortedKeys returns map keys in a stable order so scrapes are deterministic
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatValue formats a sample value the way Prometheus expects
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabelValue escapes backslashes, quotes and newlines in label values
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounterVec(t *testing.T) {
+	registry := NewRegistry()
+	counter := NewCounterVec("test_rejected_total", "Rejected things.", "reason")
+	registry.MustRegister(counter)
+
+	counter.Inc("missing_ndc")
+	counter.Inc("missing_ndc")
+	counter.Add(3, "quote\"d")
+
+	require.Equal(t, float64(2), counter.Value("missing_ndc"))
+
+	var out strings.Builder
+	require.NoError(t, registry.Write(&out))
+
+	require.Contains(t, out.String(), "# TYPE test_rejected_total counter\n")
+	require.Contains(t, out.String(), "test_rejected_total{reason=\"missing_ndc\"} 2\n")
+	require.Contains(t, out.String(), "test_rejected_total{reason=\"quote\\\"d\"} 3\n")
+}
+
+func TestHistogramVec(t *testing.T) {
+	registry := NewRegistry()
+	histogram := NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.1, 1}, "route")
+	registry.MustRegister(histogram)
+
+	histogram.Observe(0.05, "/claims")
+	histogram.Observe(0.5, "/claims")
+	histogram.Observe(5, "/claims")
+
+	require.Equal(t, uint64(3), histogram.Count("/claims"))
+
+	var out strings.Builder
+	require.NoError(t, registry.Write(&out))
+
+	require.Contains(t, out.String(), "test_duration_seconds_bucket{route=\"/claims\",le=\"0.1\"} 1\n")
+	require.Contains(t, out.String(), "test_duration_seconds_bucket{route=\"/claims\",le=\"1\"} 2\n")
+	require.Contains(t, out.String(), "test_duration_seconds_bucket{route=\"/claims\",le=\"+Inf\"} 3\n")
+	require.Contains(t, out.String(), "test_duration_seconds_sum{route=\"/claims\"} 5.55\n")
+	require.Contains(t, out.String(), "test_duration_seconds_count{route=\"/claims\"} 3\n")
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(NewCounterVec("test_total", "First.")))
+	require.Error(t, registry.Register(NewCounterVec("test_total", "Second.")))
+}
+
+func TestRegistryHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.MustRegister(NewGaugeFunc("test_idle_connections", "Idle connections.", func() float64 { return 4 }))
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain"))
+	require.Contains(t, recorder.Body.String(), "# TYPE test_idle_connections gauge\ntest_idle_connections 4\n")
+}
@@ -0,0 +1,125 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+)
+
+// Policy decides what happens to a claim priced above its allowed amount
+type Policy string
+
+const (
+	// PolicyFlag accepts the claim and marks it with price_exceeds_allowed
+	PolicyFlag Policy = "flag"
+	// PolicyReject rejects the claim with reject code 78
+	PolicyReject Policy = "reject"
+)
+
+// Source is the reference data the pricer reads; *sqlc.Queries satisfies it
+type Source interface {
+	GetReferencePrice(ctx context.Context, arg sqlc.GetReferencePriceParams) (sqlc.ReferencePrice, error)
+	GetEffectiveContract(ctx context.Context, arg sqlc.GetEffectiveContractParams) (sqlc.Contract, error)
+}
+
+// Quote is the allowed amount of a claim and how it was derived
+type Quote struct {
+	UnitPrice       float64 `json:"unit_price"`
+	DiscountPercent float64 `json:"discount_percent"`
+	DispensingFee   float64 `json:"dispensing_fee"`
+	AllowedAmount   float64 `json:"allowed_amount"`
+	// ContractID is the chain contract the terms came from; empty when no contract was in effect
+	ContractID string `json:"contract_id,omitempty"`
+}
+
+// Pricer computes allowed amounts from the reference price table and the chain's contract
+type Pricer struct {
+	// DispensingFee is the fee for chains without a contract in effect, unless ChainFees has an entry for the chain
+	DispensingFee float64
+	ChainFees     map[string]float64
+	// Tolerance is the fraction above the allowed amount a submitted price may reach, e.g. 0.1
+	Tolerance float64
+	Policy    Policy
+}
+
+// NewPricerFromConfig builds the pricer from the pricing settings
+func NewPricerFromConfig(config util.Config) (*Pricer, error) {
+	policy := Policy(config.PriceCeilingPolicy)
+	if policy != PolicyFlag && policy != PolicyReject {
+		return nil, fmt.Errorf("unknown price ceiling policy %q", config.PriceCeilingPolicy)
+	}
+
+	if config.DispensingFee < 0 || config.PriceTolerancePercent < 0 {
+		return nil, errors.New("dispensing fee and price tolerance cannot be negative")
+	}
+
+	return &Pricer{
+		DispensingFee: config.DispensingFee,
+		ChainFees:     config.ChainDispensingFees,
+		Tolerance:     config.PriceTolerancePercent / 100,
+		Policy:        policy,
+	}, nil
+}
+
+// FeeFor returns the dispensing fee paid to a chain
+func (p *Pricer) FeeFor(chain string) float64 {
+	if fee, ok := p.ChainFees[chain]; ok {
+		return fee
+	}
+	return p.DispensingFee
+}
+
+// Price quotes the allowed amount of a fill on the service date. The chain's contract in effect
+// on that date sets the discount off the reference price and the dispensing fee; without one the
+// configured fee applies and there is no discount. It reports false when the NDC has no reference
+// price in effect, in which case the claim cannot be priced.
+func (p *Pricer) Price(ctx context.Context, source Source, ndc, chain string, quantity int64, serviceDate time.Time) (Quote, bool, error) {
+	reference, err := source.GetReferencePrice(ctx, sqlc.GetReferencePriceParams{
+		NDC:         ndc,
+		ServiceDate: serviceDate,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Quote{}, false, nil
+	}
+	if err != nil {
+		return Quote{}, false, err
+	}
+
+	quote := Quote{
+		UnitPrice:     reference.UnitPrice,
+		DispensingFee: p.FeeFor(chain),
+	}
+
+	contract, err := source.GetEffectiveContract(ctx, sqlc.GetEffectiveContractParams{
+		Chain:       chain,
+		ServiceDate: serviceDate,
+	})
+	switch {
+	case err == nil:
+		quote.DiscountPercent = contract.DiscountPercent
+		quote.DispensingFee = contract.DispensingFee
+		quote.ContractID = contract.ID.String()
+	case !errors.Is(err, pgx.ErrNoRows):
+		return Quote{}, false, err
+	}
+
+	ingredientCost := float64(quantity) * reference.UnitPrice * (1 - quote.DiscountPercent/100)
+	quote.AllowedAmount = roundCents(ingredientCost + quote.DispensingFee)
+	return quote, true, nil
+}
+
+// Exceeds reports whether a submitted price is above the allowed amount by more than the tolerance
+func (p *Pricer) Exceeds(submitted float64, quote Quote) bool {
+	return submitted > roundCents(quote.AllowedAmount*(1+p.Tolerance))
+}
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
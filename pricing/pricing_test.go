@@ -0,0 +1,92 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource serves one reference price per NDC and one contract per chain regardless of the service date
+type fakeSource struct {
+	prices    map[string]sqlc.ReferencePrice
+	contracts map[string]sqlc.Contract
+}
+
+func (f fakeSource) GetReferencePrice(ctx context.Context, arg sqlc.GetReferencePriceParams) (sqlc.ReferencePrice, error) {
+	if price, ok := f.prices[arg.NDC]; ok {
+		return price, nil
+	}
+	return sqlc.ReferencePrice{}, pgx.ErrNoRows
+}
+
+func (f fakeSource) GetEffectiveContract(ctx context.Context, arg sqlc.GetEffectiveContractParams) (sqlc.Contract, error) {
+	if contract, ok := f.contracts[arg.Chain]; ok {
+		return contract, nil
+	}
+	return sqlc.Contract{}, pgx.ErrNoRows
+}
+
+func TestPrice(t *testing.T) {
+	contractID := uuid.New()
+	source := fakeSource{
+		prices: map[string]sqlc.ReferencePrice{
+			"00002323401": {NDC: "00002323401", UnitPrice: 0.125},
+		},
+		contracts: map[string]sqlc.Contract{
+			"Rite Aid": {ID: contractID, Chain: "Rite Aid", DiscountPercent: 20, DispensingFee: 1},
+		},
+	}
+
+	pricer := &Pricer{
+		DispensingFee: 2,
+		ChainFees:     map[string]float64{"CVS": 1.5},
+		Tolerance:     0.1,
+		Policy:        PolicyFlag,
+	}
+
+	quote, ok, err := pricer.Price(context.Background(), source, "00002323401", "Walgreens", 30, time.Now())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Quote{UnitPrice: 0.125, DispensingFee: 2, AllowedAmount: 5.75}, quote)
+
+	// The chain's fee replaces the default
+	quote, ok, err = pricer.Price(context.Background(), source, "00002323401", "CVS", 30, time.Now())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 5.25, quote.AllowedAmount)
+
+	// Within the tolerance is fine, above it is not
+	require.False(t, pricer.Exceeds(5.7, quote))
+	require.True(t, pricer.Exceeds(5.8, quote))
+
+	// A contract's discount and fee replace the configured fee
+	quote, ok, err = pricer.Price(context.Background(), source, "00002323401", "Rite Aid", 30, time.Now())
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Quote{UnitPrice: 0.125, DiscountPercent: 20, DispensingFee: 1, AllowedAmount: 4, ContractID: contractID.String()}, quote)
+
+	_, ok, err = pricer.Price(context.Background(), source, "99999999999", "CVS", 30, time.Now())
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestNewPricerFromConfig(t *testing.T) {
+	pricer, err := NewPricerFromConfig(util.Config{
+		DispensingFee:         1.75,
+		PriceTolerancePercent: 15,
+		PriceCeilingPolicy:    "reject",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PolicyReject, pricer.Policy)
+	require.Equal(t, 0.15, pricer.Tolerance)
+	require.Equal(t, 1.75, pricer.FeeFor("CVS"))
+
+	_, err = NewPricerFromConfig(util.Config{PriceCeilingPolicy: "ignore"})
+	require.Error(t, err)
+}
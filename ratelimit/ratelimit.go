@@ -0,0 +1,120 @@
+// Package ratelimit implements token buckets: each key may spend up to Burst requests at once,
+// refilled at Rate requests per second.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a sustained request rate and the burst allowed on top of it
+type Limit struct {
+	// Rate is the number of requests per second
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of one request against a limit
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request is allowed; zero when allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Limiter tracks one token bucket per key
+type Limiter struct {
+	limit Limit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+
+	now func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a limiter enforcing limit for every key
+func NewLimiter(limit Limit) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the key's bucket if one is available
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.refill(key, now)
+
+	result := Result{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.timeFor(1 - b.tokens)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = l.timeFor(float64(l.limit.Burst) - b.tokens)
+	return result
+}
+
+// Purge forgets the buckets that have refilled completely, which behave like new ones
+func (l *Limiter) Purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key := range l.buckets {
+		if b := l.refill(key, now); b.tokens >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of buckets tracked
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
+
+// refill returns the key's bucket with the tokens earned since it was last used
+func (l *Limiter) refill(key string, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[key] = b
+		return b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(l.limit.Burst), b.tokens+elapsed*l.limit.Rate)
+		b.last = now
+	}
+	return b
+}
+
+// timeFor returns how long it takes to earn the given number of tokens
+func (l *Limiter) timeFor(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	if l.limit.Rate <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(math.Ceil(tokens / l.limit.Rate * float64(time.Second)))
+}
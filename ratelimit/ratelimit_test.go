@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(Limit{Rate: 2, Burst: 3})
+	limiter.now = func() time.Time { return now }
+
+	// The burst is available at once
+	for i := 2; i >= 0; i-- {
+		result := limiter.Allow("pharmacy-a")
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Limit)
+		require.Equal(t, i, result.Remaining)
+	}
+
+	result := limiter.Allow("pharmacy-a")
+	require.False(t, result.Allowed)
+	require.Equal(t, 0, result.Remaining)
+	require.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	require.Equal(t, 1500*time.Millisecond, result.Reset)
+
+	// Other keys have their own bucket
+	require.True(t, limiter.Allow("pharmacy-b").Allowed)
+
+	// Tokens are earned at the configured rate
+	now = now.Add(500 * time.Millisecond)
+	require.True(t, limiter.Allow("pharmacy-a").Allowed)
+	require.False(t, limiter.Allow("pharmacy-a").Allowed)
+
+	// Buckets never hold more than the burst
+	now = now.Add(time.Hour)
+	result = limiter.Allow("pharmacy-a")
+	require.True(t, result.Allowed)
+	require.Equal(t, 2, result.Remaining)
+}
+
+func TestLimiterPurge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(Limit{Rate: 1, Burst: 2})
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("idle")
+	now = now.Add(500 * time.Millisecond)
+	limiter.Allow("busy")
+	limiter.Allow("busy")
+	require.Equal(t, 2, limiter.Len())
+
+	// The idle bucket has refilled, the busy one has not
+	now = now.Add(time.Second)
+	limiter.Purge()
+	require.Equal(t, 1, limiter.Len())
+}
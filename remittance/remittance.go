@@ -0,0 +1,155 @@
+package remittance
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// Payer identifies the plan in the remittance envelope and payer loop
+type Payer struct {
+	ID   string
+	Name string
+}
+
+// Remittance is everything needed to write the remittance advice of one payment batch
+type Remittance struct {
+	Payer    Payer
+	Pharmacy sqlc.Pharmacy
+	Cycle    sqlc.SettlementCycle
+	Batch    sqlc.SettlementBatch
+	Items    []sqlc.ListSettlementItemsByBatchRow
+	// Created is the time stamped on the interchange
+	Created time.Time
+}
+
+// FileName is the suggested name of a batch's remittance file
+func FileName(batch sqlc.SettlementBatch, cycle sqlc.SettlementCycle) string {
+	return fmt.Sprintf("remittance-%s-%s.835", batch.NPI, cycle.Cutoff.UTC().Format("20060102"))
+}
+
+// Write renders the batch as an X12 835-style remittance advice. It keeps to the segments a
+// pharmacy needs to reconcile a payment: one CLP/SVC pair per claim paid or adjusted in the
+// batch, and a PLB overpayment recovery per clawback. One segment is written per line.
+func Write(w io.Writer, r Remittance) error {
+	control := controlNumber(r.Batch.ID)
+	created := r.Created.UTC()
+
+	var segments [][]string
+	add := func(elements ...string) {
+		segments = append(segments, elements)
+	}
+
+	add("ISA", "00", pad("", 10), "00", pad("", 10), "ZZ", pad(r.Payer.ID, 15), "ZZ", pad(r.Batch.NPI, 15),
+		created.Format("060102"), created.Format("1504"), "^", "00501", fmt.Sprintf("%09d", control), "0", "P", ":")
+	add("GS", "HP", r.Payer.ID, r.Batch.NPI, created.Format("20060102"), created.Format("1504"), strconv.Itoa(control), "X", "005010X221A1")
+
+	start := len(segments)
+	add("ST", "835", "0001")
+
+	// Only a positive net amount is paid; otherwise the advice is a notification of the recovery
+	if r.Batch.NetAmount > 0 {
+		add(bpr("I", r.Batch.NetAmount, "ACH", created)...)
+	} else {
+		add(bpr("H", 0, "NON", created)...)
+	}
+	add("TRN", "1", r.Batch.ID.String(), r.Payer.ID)
+	add("DTM", "405", r.Cycle.Cutoff.UTC().Format("20060102"))
+	add("N1", "PR", r.Payer.Name)
+	add("N1", "PE", r.Pharmacy.Chain, "XX", r.Batch.NPI)
+	add("LX", "1")
+
+	var clawbacks []sqlc.ListSettlementItemsByBatchRow
+	for _, claim := range groupByClaim(r.Items) {
+		var paid float64
+		lines := 0
+		for _, item := range claim {
+			if item.Kind == db.SettlementItemClawback {
+				clawbacks = append(clawbacks, item)
+				continue
+			}
+			paid += item.Amount
+			lines++
+		}
+		if lines == 0 {
+			continue
+		}
+
+		// Status 22 marks a claim whose net settlement in this batch is a recovery
+		status := "1"
+		if paid < 0 {
+			status = "22"
+		}
+
+		first := claim[0]
+		add("CLP", first.ClaimID.String(), status, amount(first.Price), amount(paid), "", "13")
+		add("SVC", "N4:"+first.NDC, amount(first.Price), amount(paid), "", strconv.FormatInt(first.Quantity, 10))
+		add("DTM", "472", first.ClaimTimestamp.UTC().Format("20060102"))
+	}
+
+	for _, item := range clawbacks {
+		add("PLB", r.Batch.NPI, r.Cycle.Cutoff.UTC().Format("20060102"), "WO:"+item.ClaimID.String(), amount(-item.Amount))
+	}
+
+	add("SE", strconv.Itoa(len(segments)-start+1), "0001")
+	add("GE", "1", strconv.Itoa(control))
+	add("IEA", "1", fmt.Sprintf("%09d", control))
+
+	for _, segment := range segments {
+		if _, err := io.WriteString(w, strings.Join(segment, "*")+"~\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bpr builds the financial information segment, whose payment date is its sixteenth element
+func bpr(handling string, total float64, method string, date time.Time) []string {
+	elements := make([]string, 17)
+	elements[0] = "BPR"
+	elements[1] = handling
+	elements[2] = amount(total)
+	elements[3] = "C"
+	elements[4] = method
+	elements[16] = date.Format("20060102")
+	return elements
+}
+
+// groupByClaim splits items, which are ordered by claim, into one slice per claim
+func groupByClaim(items []sqlc.ListSettlementItemsByBatchRow) [][]sqlc.ListSettlementItemsByBatchRow {
+	var groups [][]sqlc.ListSettlementItemsByBatchRow
+	for i, item := range items {
+		if i == 0 || item.ClaimID != items[i-1].ClaimID {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], item)
+	}
+	return groups
+}
+
+// controlNumber derives a stable nine digit interchange control number from the batch ID
+func controlNumber(id uuid.UUID) int {
+	return int(binary.BigEndian.Uint32(id[:4]) % 1000000000)
+}
+
+// amount formats a monetary amount with two decimals
+func amount(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', 2, 64)
+}
+
+// pad left-aligns a value in a fixed-width ISA element
+func pad(value string, width int) string {
+	if len(value) > width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
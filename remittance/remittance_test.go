@@ -0,0 +1,64 @@
+package remittance
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	cutoff := time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+	paid := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	earlier := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	r := Remittance{
+		Payer:    Payer{ID: "PCAPAYER", Name: "Pharmacy Claims"},
+		Pharmacy: sqlc.Pharmacy{NPI: "1234567890", Chain: "CVS"},
+		Cycle:    sqlc.SettlementCycle{Cutoff: cutoff},
+		Batch: sqlc.SettlementBatch{
+			ID:             uuid.MustParse("0000007b-0000-0000-0000-000000000000"),
+			NPI:            "1234567890",
+			ClaimCount:     1,
+			PaymentAmount:  40,
+			ClawbackAmount: 15.5,
+			NetAmount:      24.5,
+		},
+		Items: []sqlc.ListSettlementItemsByBatchRow{
+			{ClaimID: earlier, Kind: db.SettlementItemClawback, Amount: -15.5, ReversalID: pgtype.UUID{Bytes: uuid.New(), Valid: true}},
+			{ClaimID: paid, Kind: db.SettlementItemPayment, Amount: 50, NDC: "00002323401", Quantity: 30, Price: 50, ClaimTimestamp: cutoff.Add(-time.Hour)},
+			{ClaimID: paid, Kind: db.SettlementItemReversal, Amount: -10, NDC: "00002323401", Quantity: 30, Price: 50, ClaimTimestamp: cutoff.Add(-time.Hour)},
+		},
+		Created: cutoff,
+	}
+
+	var out strings.Builder
+	require.NoError(t, Write(&out, r))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Equal(t, "ISA*00*          *00*          *ZZ*PCAPAYER       *ZZ*1234567890     *250307*0000*^*00501*000000123*0*P*:~", lines[0])
+	require.Equal(t, "BPR*I*24.50*C*ACH************20250307~", lines[3])
+	require.Contains(t, lines, "N1*PE*CVS*XX*1234567890~")
+	require.Contains(t, lines, "CLP*"+paid.String()+"*1*50.00*40.00**13~")
+	require.Contains(t, lines, "SVC*N4:00002323401*50.00*40.00**30~")
+	require.Contains(t, lines, "PLB*1234567890*20250307*WO:"+earlier.String()+"*15.50~")
+	require.Equal(t, "SE*12*0001~", lines[len(lines)-3])
+	require.Equal(t, "IEA*1*000000123~", lines[len(lines)-1])
+
+	// A batch that recovers more than it pays is a notification only
+	r.Batch.NetAmount = -3
+	out.Reset()
+	require.NoError(t, Write(&out, r))
+	require.Contains(t, out.String(), "BPR*H*0.00*C*NON************20250307~")
+}
+
+func TestFileName(t *testing.T) {
+	batch := sqlc.SettlementBatch{NPI: "1234567890"}
+	cycle := sqlc.SettlementCycle{Cutoff: time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)}
+	require.Equal(t, "remittance-1234567890-20250307.835", FileName(batch, cycle))
+}
@@ -0,0 +1,217 @@
+package seeder
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+)
+
+// drugColumns maps accepted header names to drug fields
+var drugColumns = map[string]string{
+	"ndc":             "ndc",
+	"ndc11":           "ndc",
+	"ndcpackagecode":  "ndc",
+	"name":            "name",
+	"drugname":        "name",
+	"proprietaryname": "name",
+	"strength":        "strength",
+	"packagesize":     "package_size",
+	"unitofmeasure":   "unit_of_measure",
+	"uom":             "unit_of_measure",
+	"active":          "active",
+	"quantitylimit":   "quantity_limit",
+}
+
+// DrugImportResult summarizes a product file import
+type DrugImportResult struct {
+	Imported int
+	Skipped  int
+}
+
+// SeedDrugs imports every product file in data/drugs, if that directory exists
+func SeedDrugs(store db.Store, dataDir string) error {
+	dir := filepath.Join(dataDir, "drugs")
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".csv" && ext != ".txt") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		result, err := ImportDrugs(store, path)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", path, err)
+		}
+
+		log.Printf("Imported %d drugs from %s (%d skipped)", result.Imported, path, result.Skipped)
+	}
+
+	return nil
+}
+
+// ImportDrugs upserts the products of a comma, pipe or tab delimited file. The delimiter is
+// detected from the header row, which must name at least the ndc and name columns.
+func ImportDrugs(store db.Store, path string) (DrugImportResult, error) {
+	var result DrugImportResult
+
+	file, err := os.Open(path)
+	if err != nil {
+		return result, fmt.Errorf("failed to open product file: %w", err)
+	}
+	defer file.Close()
+
+	return importDrugs(store, file)
+}
+
+// importDrugs reads a product file from r and upserts each valid row
+func importDrugs(store db.Store, r io.Reader) (DrugImportResult, error) {
+	var result DrugImportResult
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return result, fmt.Errorf("failed to read product file: %w", err)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = detectDelimiter(string(content))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return result, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ToLower(strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.TrimSpace(name)))
+		if field, ok := drugColumns[key]; ok {
+			columns[field] = i
+		}
+	}
+
+	for _, required := range []string{"ndc", "name"} {
+		if _, ok := columns[required]; !ok {
+			return result, fmt.Errorf("header is missing the %s column", required)
+		}
+	}
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		arg, err := parseDrugRecord(record, columns)
+		if err != nil {
+			log.Printf("Skipping invalid drug at line %d: %v", line, err)
+			result.Skipped++
+			continue
+		}
+
+		if _, err := store.UpsertDrug(context.Background(), arg); err != nil {
+			log.Printf("Failed to import drug %s: %v", arg.NDC, err)
+			result.Skipped++
+			continue
+		}
+
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// parseDrugRecord converts one product row to upsert parameters
+func parseDrugRecord(record []string, columns map[string]int) (sqlc.UpsertDrugParams, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	ndc, ok := util.NormalizeNDC(value("ndc"))
+	if !ok {
+		return sqlc.UpsertDrugParams{}, fmt.Errorf("invalid ndc %q", value("ndc"))
+	}
+
+	arg := sqlc.UpsertDrugParams{
+		NDC:           ndc,
+		Name:          value("name"),
+		Strength:      value("strength"),
+		UnitOfMeasure: strings.ToUpper(value("unit_of_measure")),
+		Active:        true,
+	}
+
+	if arg.Name == "" {
+		return arg, fmt.Errorf("ndc %s has no name", ndc)
+	}
+
+	if size := value("package_size"); size != "" {
+		packageSize, err := strconv.ParseFloat(size, 64)
+		if err != nil || packageSize < 0 {
+			return arg, fmt.Errorf("invalid package size %q", size)
+		}
+		arg.PackageSize = packageSize
+	}
+
+	if limit := value("quantity_limit"); limit != "" {
+		quantityLimit, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || quantityLimit < 0 {
+			return arg, fmt.Errorf("invalid quantity limit %q", limit)
+		}
+		arg.QuantityLimit = quantityLimit
+	}
+
+	if active := value("active"); active != "" {
+		parsed, err := strconv.ParseBool(active)
+		if err != nil {
+			return arg, fmt.Errorf("invalid active flag %q", active)
+		}
+		arg.Active = parsed
+	}
+
+	return arg, nil
+}
+
+// detectDelimiter picks the delimiter used in the header row
+func detectDelimiter(content string) rune {
+	header, _, _ := strings.Cut(content, "\n")
+
+	switch {
+	case strings.Contains(header, "|"):
+		return '|'
+	case strings.Contains(header, "\t"):
+		return '\t'
+	default:
+		return ','
+	}
+}
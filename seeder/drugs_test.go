@@ -0,0 +1,37 @@
+package seeder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	require.Equal(t, '|', detectDelimiter("ndc|name|strength\n00002323401|Amoxicillin|500 mg\n"))
+	require.Equal(t, '\t', detectDelimiter("ndc\tname\n"))
+	require.Equal(t, ',', detectDelimiter("ndc,name\n00002323401,\"Amoxicillin | Generic\"\n"))
+}
+
+func TestParseDrugRecord(t *testing.T) {
+	columns := map[string]int{"ndc": 0, "name": 1, "package_size": 2, "unit_of_measure": 3, "active": 4, "quantity_limit": 5}
+
+	arg, err := parseDrugRecord([]string{"0002-3234-01", "Amoxicillin", "100", "ea", "false", "90"}, columns)
+	require.NoError(t, err)
+	require.Equal(t, "00002323401", arg.NDC)
+	require.Equal(t, float64(100), arg.PackageSize)
+	require.Equal(t, "EA", arg.UnitOfMeasure)
+	require.False(t, arg.Active)
+	require.Equal(t, int64(90), arg.QuantityLimit)
+
+	_, err = parseDrugRecord([]string{"12345", "Amoxicillin"}, columns)
+	require.Error(t, err)
+
+	_, err = parseDrugRecord([]string{"00002323401", ""}, columns)
+	require.Error(t, err)
+
+	_, err = parseDrugRecord([]string{"00002323401", "Amoxicillin", "-1"}, columns)
+	require.Error(t, err)
+
+	_, err = parseDrugRecord([]string{"00002323401", "Amoxicillin", "", "", "", "-30"}, columns)
+	require.Error(t, err)
+}
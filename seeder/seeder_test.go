@@ -0,0 +1,42 @@
+package seeder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// pharmacyStore records the pharmacies seeded into an empty database
+type pharmacyStore struct {
+	db.Store
+	pharmacies []sqlc.CreatePharmacyParams
+}
+
+func (store *pharmacyStore) CountPharmacies(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (store *pharmacyStore) CreatePharmacy(ctx context.Context, arg sqlc.CreatePharmacyParams) (sqlc.Pharmacy, error) {
+	store.pharmacies = append(store.pharmacies, arg)
+	return sqlc.Pharmacy{NPI: arg.NPI, Chain: arg.Chain, Active: true}, nil
+}
+
+// TestSeedPharmacies checks that every pharmacy shipped in data/pharmacies is seeded unchanged,
+// with an NPI that claim requests accept
+func TestSeedPharmacies(t *testing.T) {
+	store := &pharmacyStore{}
+	require.NoError(t, SeedPharmacies(store, "../data"))
+	require.Len(t, store.pharmacies, 17)
+
+	npis := make([]string, len(store.pharmacies))
+	for i, pharmacy := range store.pharmacies {
+		require.True(t, util.ValidNPIFormat(pharmacy.NPI), pharmacy.NPI)
+		npis[i] = pharmacy.NPI
+	}
+	require.Contains(t, npis, "4444444444")
+	require.Contains(t, npis, "1234567890")
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const (
+	actorContextKey contextKey = "actor"
+
+	// actorHeader identifies the caller when authentication is disabled; otherwise the
+	// authenticated key names the actor
+	actorHeader    = "X-Actor"
+	anonymousActor = "anonymous"
+)
+
+// actorMiddleware attaches the identity of the caller to the request context
+func (server *Server) actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := strings.TrimSpace(r.Header.Get(actorHeader))
+		if actor == "" {
+			actor = anonymousActor
+		}
+
+		next.ServeHTTP(w, r.WithContext(withActor(r.Context(), actor)))
+	})
+}
+
+// withActor returns a copy of ctx carrying the given actor
+func withActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// actorFromContext returns the actor attached to ctx, or anonymous if there is none
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey).(string); ok && actor != "" {
+		return actor
+	}
+	return anonymousActor
+}
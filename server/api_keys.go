@@ -0,0 +1,161 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// listAPIKeys handles GET /api/v1/api-keys
+func (server *Server) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := server.store.ListAPIKeys(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list API keys")
+		return
+	}
+
+	data := make([]APIKey, 0, len(keys))
+	for _, key := range keys {
+		data = append(data, convertDBAPIKeyToAPI(key))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// createAPIKey handles POST /api/v1/api-keys. The key itself is only ever returned here.
+func (server *Server) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: name (string), and either npis (array of strings) or chain (string)",
+		"example": map[string]interface{}{
+			"name": "Main Street Pharmacy POS",
+			"npis": []string{"1234567890"},
+		},
+	}) {
+		return
+	}
+
+	arg, verr := validateAPIKeyRequest(req)
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	key, prefix, err := generateAPIKey()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "Failed to generate API key")
+		return
+	}
+	arg.Prefix = prefix
+	arg.KeyHash = hashAPIKey(key)
+
+	apiKey, err := server.store.CreateAPIKey(r.Context(), arg)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to create API key")
+		return
+	}
+
+	data := convertDBAPIKeyToAPI(apiKey)
+	data.Key = key
+
+	response := APIResponse{
+		Success: true,
+		Message: "API key created successfully. Store the key now, it cannot be retrieved again",
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// revokeAPIKey handles DELETE /api/v1/api-keys/{id}
+func (server *Server) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeParameterError(w, r, "id", "must be a UUID", "550e8400-e29b-41d4-a716-446655440000")
+		return
+	}
+
+	apiKey, err := server.store.RevokeAPIKey(r.Context(), id)
+	if errors.Is(err, db.ErrNotFound) {
+		// Tell an unknown key apart from one that was already revoked
+		if _, err := server.store.GetAPIKey(r.Context(), id); err == nil {
+			writeError(w, r, http.StatusConflict, codeAlreadyRevoked, "API key has already been revoked")
+			return
+		}
+		writeError(w, r, http.StatusNotFound, codeNotFound, "API key not found")
+		return
+	}
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to revoke API key")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+		Data:    convertDBAPIKeyToAPI(apiKey),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// validateAPIKeyRequest checks a key request and returns the parameters to store it with.
+// A key is scoped to either a set of NPIs or a whole chain.
+func validateAPIKeyRequest(req APIKeyRequest) (sqlc.CreateAPIKeyParams, *validationError) {
+	arg := sqlc.CreateAPIKeyParams{
+		Name: strings.TrimSpace(req.Name),
+		NPIs: []string{},
+	}
+
+	if verr := validateRequest(req); verr != nil {
+		return arg, verr
+	}
+
+	for _, npi := range req.NPIs {
+		if !slices.Contains(arg.NPIs, npi) {
+			arg.NPIs = append(arg.NPIs, npi)
+		}
+	}
+
+	if chain := strings.TrimSpace(req.Chain); chain != "" {
+		arg.Chain = pgtype.Text{String: chain, Valid: true}
+	}
+
+	if arg.Chain.Valid == (len(arg.NPIs) > 0) {
+		verr := invalidField("npis", "or chain must be set, but not both")
+		verr.Message = "API key must be scoped to either a list of NPIs or a chain"
+		return arg, verr
+	}
+
+	return arg, nil
+}
+
+// convertDBAPIKeyToAPI converts a database API key to the API type, without its hash
+func convertDBAPIKeyToAPI(apiKey sqlc.APIKey) APIKey {
+	result := APIKey{
+		ID:        apiKey.ID.String(),
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Chain:     apiKey.Chain.String,
+		NPIs:      apiKey.NPIs,
+		Active:    !apiKey.RevokedAt.Valid,
+		Timestamp: apiKey.Timestamp,
+	}
+
+	if apiKey.RevokedAt.Valid {
+		result.RevokedAt = &apiKey.RevokedAt.Time
+	}
+
+	return result
+}
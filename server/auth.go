@@ -0,0 +1,317 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/auth"
+	"github.com/pharmacy_claims_application/db"
+)
+
+const (
+	principalContextKey contextKey = "principal"
+
+	// apiKeyHeader carries the caller's API key; internal users send a bearer token instead
+	apiKeyHeader = "X-API-Key"
+	bearerPrefix = "Bearer "
+
+	// apiKeyTag starts every issued key so leaked keys are easy to recognise
+	apiKeyTag = "pck_"
+	// apiKeyPrefixLength is how much of a key is stored in the clear to identify it
+	apiKeyPrefixLength = len(apiKeyTag) + 8
+	adminActor         = "admin"
+)
+
+// scopedRoutes are the routes a pharmacy or chain key may call. Each of them checks that the
+// claim or NPI involved is within the key's scope; every other API route needs the admin key.
+var scopedRoutes = map[string]bool{
+	"POST /api/v1/claims":     true,
+	"GET /api/v1/claims/{id}": true,
+	"POST /api/v1/reversals":  true,
+	"POST /api/v2/claims":     true,
+	"GET /api/v2/claims/{id}": true,
+}
+
+// routePermissions is the permission a bearer token needs for each route. Routes missing here,
+// such as API key management, are reserved for the admin role.
+var routePermissions = map[string]auth.Permission{
+	"POST /api/v1/claims":                             auth.PermissionSubmitClaims,
+	"POST /api/v1/claims/batch":                       auth.PermissionSubmitClaims,
+	"POST /api/v1/claims/files":                       auth.PermissionSubmitClaims,
+	"GET /api/v1/claims/{id}":                         auth.PermissionReadClaims,
+	"POST /api/v1/reversals":                          auth.PermissionReverseClaims,
+	"POST /api/v1/reversals/batch":                    auth.PermissionReverseClaims,
+	"GET /api/v1/reversals":                           auth.PermissionReadClaims,
+	"GET /api/v1/reversal-reasons":                    auth.PermissionReadClaims,
+	"POST /api/v1/reversal-reasons":                   auth.PermissionManageReference,
+	"PUT /api/v1/reversal-reasons/{code}":             auth.PermissionManageReference,
+	"PATCH /api/v1/pharmacies/{npi}":                  auth.PermissionManagePharmacies,
+	"GET /api/v1/drugs":                               auth.PermissionReadClaims,
+	"POST /api/v1/drugs":                              auth.PermissionManageReference,
+	"GET /api/v1/drugs/{ndc}":                         auth.PermissionReadClaims,
+	"PUT /api/v1/drugs/{ndc}":                         auth.PermissionManageReference,
+	"DELETE /api/v1/drugs/{ndc}":                      auth.PermissionManageReference,
+	"GET /api/v1/drugs/{ndc}/prices":                  auth.PermissionReadClaims,
+	"POST /api/v1/drugs/{ndc}/prices":                 auth.PermissionManageReference,
+	"GET /api/v1/contracts":                           auth.PermissionViewReports,
+	"POST /api/v1/contracts":                          auth.PermissionManageReference,
+	"GET /api/v1/contracts/{id}":                      auth.PermissionViewReports,
+	"PUT /api/v1/contracts/{id}":                      auth.PermissionManageReference,
+	"GET /api/v1/reports/chain-pricing":               auth.PermissionViewReports,
+	"POST /api/v1/settlements":                        auth.PermissionRunSettlements,
+	"GET /api/v1/settlements":                         auth.PermissionViewReports,
+	"GET /api/v1/settlements/{id}":                    auth.PermissionViewReports,
+	"GET /api/v1/settlement-batches/{id}":             auth.PermissionViewReports,
+	"GET /api/v1/settlement-batches/{id}/remittance":  auth.PermissionViewReports,
+	"GET /api/v1/pharmacies/{npi}/settlement-batches": auth.PermissionViewReports,
+	"POST /api/v2/claims":                             auth.PermissionSubmitClaims,
+	"GET /api/v2/claims/{id}":                         auth.PermissionReadClaims,
+}
+
+// principal is the authenticated caller of a request
+type principal struct {
+	Admin bool
+	KeyID uuid.UUID
+	Name  string
+	Chain string
+	NPIs  []string
+	// Permissions granted by the roles of a bearer token; nil for API keys
+	Permissions map[auth.Permission]bool
+}
+
+// allows reports whether the principal may call the route with the given pattern
+func (p *principal) allows(pattern string) bool {
+	if p.Admin {
+		return true
+	}
+	if p.Permissions == nil {
+		return scopedRoutes[pattern]
+	}
+
+	permission, ok := routePermissions[pattern]
+	return ok && p.Permissions[permission]
+}
+
+// scoped reports whether the principal may only act for some pharmacies
+func (p *principal) scoped() bool {
+	return p.Chain != "" || len(p.NPIs) > 0
+}
+
+// authMiddleware authenticates every API request by its bearer token, API key or client
+// certificate. Health checks and metrics stay open. The admin key and the admin role may call
+// every route; issued keys and client certificates may only call the scoped routes, and other
+// roles the routes their permissions cover.
+func (server *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !server.config.AuthEnabled || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p, ok := server.authenticate(w, r)
+		if !ok {
+			return
+		}
+
+		if _, pattern := server.router.Handler(r); pattern != "" && !p.allows(pattern) {
+			details := map[string]interface{}{}
+			if permission, ok := routePermissions[pattern]; ok && p.Permissions != nil {
+				details["required_permission"] = permission
+			}
+			writeError(w, r, http.StatusForbidden, codeForbidden, "Caller is not allowed to call this endpoint", details)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalContextKey, p)
+		next.ServeHTTP(w, r.WithContext(withActor(ctx, p.Name)))
+	})
+}
+
+// authenticate identifies the caller from, in order of precedence, a bearer token, an API key or
+// a verified client certificate, writing an error response when it cannot
+func (server *Server) authenticate(w http.ResponseWriter, r *http.Request) (*principal, bool) {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		return server.authenticateBearer(w, r, authorization)
+	}
+
+	if key := strings.TrimSpace(r.Header.Get(apiKeyHeader)); key != "" {
+		p, err := server.authenticateAPIKey(r.Context(), key)
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusUnauthorized, codeInvalidCredentials, "API key is invalid or has been revoked")
+			return nil, false
+		}
+		if err != nil {
+			writeStoreError(w, r, err, "Failed to authenticate request")
+			return nil, false
+		}
+		return p, true
+	}
+
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return server.authenticateCertificate(w, r, r.TLS.VerifiedChains[0][0])
+	}
+
+	writeError(w, r, http.StatusUnauthorized, codeUnauthenticated, "API key, bearer token or client certificate is required", map[string]interface{}{
+		"headers": []string{apiKeyHeader, "Authorization"},
+	})
+	return nil, false
+}
+
+// authenticateCertificate maps a verified client certificate to the pharmacies its subject is
+// configured for. Certificates act like API keys and may only call the scoped routes.
+func (server *Server) authenticateCertificate(w http.ResponseWriter, r *http.Request, cert *x509.Certificate) (*principal, bool) {
+	subject := cert.Subject.CommonName
+
+	scope, ok := server.config.ClientSubjects[subject]
+	if !ok {
+		writeError(w, r, http.StatusForbidden, codeForbidden, "Client certificate is not mapped to a pharmacy or chain", map[string]interface{}{
+			"subject": subject,
+		})
+		return nil, false
+	}
+
+	return &principal{
+		Name:  subject,
+		Chain: scope.Chain,
+		NPIs:  scope.NPIs,
+	}, true
+}
+
+// authenticateAPIKey resolves an API key to its principal. It returns db.ErrNotFound for unknown
+// and revoked keys.
+func (server *Server) authenticateAPIKey(ctx context.Context, key string) (*principal, error) {
+	if admin := server.config.AdminAPIKey; admin != "" && subtle.ConstantTimeCompare([]byte(key), []byte(admin)) == 1 {
+		return &principal{Admin: true, Name: adminActor}, nil
+	}
+
+	apiKey, err := server.store.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokedAt.Valid {
+		return nil, db.ErrNotFound
+	}
+
+	return &principal{
+		KeyID: apiKey.ID,
+		Name:  apiKey.Name,
+		Chain: apiKey.Chain.String,
+		NPIs:  apiKey.NPIs,
+	}, nil
+}
+
+// authenticateBearer verifies the bearer token in an Authorization header, writing a 401
+// response when it is refused
+func (server *Server) authenticateBearer(w http.ResponseWriter, r *http.Request, authorization string) (*principal, bool) {
+	token, found := strings.CutPrefix(authorization, bearerPrefix)
+	if !found || strings.TrimSpace(token) == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_request"`)
+		writeError(w, r, http.StatusUnauthorized, codeInvalidCredentials, "Authorization header must be a bearer token")
+		return nil, false
+	}
+
+	if server.verifier == nil {
+		writeError(w, r, http.StatusUnauthorized, codeInvalidCredentials, "Bearer tokens are not accepted by this server")
+		return nil, false
+	}
+
+	claims, err := server.verifier.Verify(strings.TrimSpace(token))
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(w, r, http.StatusUnauthorized, codeInvalidCredentials, "Bearer token is invalid", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	return &principal{
+		Admin:       slices.Contains(claims.Roles, auth.RoleAdmin),
+		Name:        claims.Subject,
+		Permissions: auth.PermissionsFor(claims.Roles),
+	}, true
+}
+
+// principalFromContext returns the authenticated caller, if authentication is enabled
+func principalFromContext(ctx context.Context) (*principal, bool) {
+	p, ok := ctx.Value(principalContextKey).(*principal)
+	return p, ok
+}
+
+// authorizePharmacy reports whether the caller may act for the pharmacy with the given NPI.
+// Without authentication, and for the admin key, every pharmacy is allowed.
+func (server *Server) authorizePharmacy(ctx context.Context, npi string) (bool, error) {
+	p, ok := principalFromContext(ctx)
+	if !ok || !p.scoped() {
+		return true, nil
+	}
+
+	if slices.Contains(p.NPIs, npi) {
+		return true, nil
+	}
+	if p.Chain == "" {
+		return false, nil
+	}
+
+	pharmacy, err := server.store.GetPharmacy(ctx, npi)
+	if errors.Is(err, db.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return pharmacy.Chain == p.Chain, nil
+}
+
+// authorizeClaim reports whether the caller may act on the claim with the given ID. Unknown
+// claims are allowed so that the caller gets the usual not found response.
+func (server *Server) authorizeClaim(ctx context.Context, claimID uuid.UUID) (bool, error) {
+	if p, ok := principalFromContext(ctx); !ok || !p.scoped() {
+		return true, nil
+	}
+
+	claim, err := server.store.GetClaim(ctx, claimID)
+	if errors.Is(err, db.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return server.authorizePharmacy(ctx, claim.NPI)
+}
+
+// authorizeWindowOverride reports whether the caller may reverse claims after their reversal
+// window has closed. Only the admin key and the admin role may; without authentication every
+// caller may, as for the other checks.
+func authorizeWindowOverride(ctx context.Context) bool {
+	p, ok := principalFromContext(ctx)
+	return !ok || p.Admin
+}
+
+// generateAPIKey returns a new random API key and the prefix stored to identify it
+func generateAPIKey() (string, string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	key := apiKeyTag + hex.EncodeToString(secret)
+	return key, key[:apiKeyPrefixLength], nil
+}
+
+// hashAPIKey returns the hash under which an API key is stored. Keys are random, so a fast
+// hash is enough to keep them out of the database.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/auth"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAdminKey = "admin-secret"
+	testChainKey = "pck_chainkey"
+	testNPIKey   = "pck_npikey01"
+	testRevoked  = "pck_revoked1"
+)
+
+var notFound = &db.Error{Kind: db.ErrNotFound, Err: pgx.ErrNoRows}
+
+// authStore serves two CVS pharmacies, one Walgreens pharmacy, a claim at each, and API keys
+// scoped to the CVS chain and to a single CVS pharmacy
+func authStore() (*fakeStore, map[string]uuid.UUID) {
+	pharmacies := map[string]string{
+		"1111111111": "CVS",
+		"2222222222": "CVS",
+		"3333333333": "Walgreens",
+	}
+
+	claims := make(map[uuid.UUID]string)
+	claimIDs := make(map[string]uuid.UUID)
+	for npi := range pharmacies {
+		id := uuid.New()
+		claims[id] = npi
+		claimIDs[npi] = id
+	}
+
+	keys := map[string]sqlc.APIKey{
+		hashAPIKey(testChainKey): {ID: uuid.New(), Name: "cvs", Chain: pgtype.Text{String: "CVS", Valid: true}},
+		hashAPIKey(testNPIKey):   {ID: uuid.New(), Name: "cvs-1111111111", NPIs: []string{"1111111111"}},
+		hashAPIKey(testRevoked):  {ID: uuid.New(), Name: "old", NPIs: []string{"1111111111"}, RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}},
+	}
+
+	return &fakeStore{
+		getClaim: func(ctx context.Context, id uuid.UUID) (sqlc.Claim, error) {
+			npi, ok := claims[id]
+			if !ok {
+				return sqlc.Claim{}, notFound
+			}
+			return sqlc.Claim{ID: id, NPI: npi}, nil
+		},
+		getPharmacy: func(ctx context.Context, npi string) (sqlc.Pharmacy, error) {
+			chain, ok := pharmacies[npi]
+			if !ok {
+				return sqlc.Pharmacy{}, notFound
+			}
+			return sqlc.Pharmacy{NPI: npi, Chain: chain}, nil
+		},
+		getAPIKeyByHash: func(ctx context.Context, keyHash string) (sqlc.APIKey, error) {
+			key, ok := keys[keyHash]
+			if !ok {
+				return sqlc.APIKey{}, notFound
+			}
+			return key, nil
+		},
+	}, claimIDs
+}
+
+func newAuthServer(t *testing.T, store db.Store) *Server {
+	eventLogger, err := logger.NewLogger(t.TempDir())
+	require.NoError(t, err)
+
+	config := util.Config{AuthEnabled: true, AdminAPIKey: testAdminKey}
+	return NewServer(config, store, eventLogger, nil, nil, nil)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	store, _ := authStore()
+	server := newAuthServer(t, store)
+
+	testCases := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		status  int
+		code    errorCode
+		actor   string
+	}{
+		{name: "no credentials", method: http.MethodPost, path: "/api/v1/claims", status: http.StatusUnauthorized, code: codeUnauthenticated},
+		{name: "unknown key", method: http.MethodPost, path: "/api/v1/claims", headers: map[string]string{apiKeyHeader: "pck_unknown1"}, status: http.StatusUnauthorized, code: codeInvalidCredentials},
+		{name: "revoked key", method: http.MethodPost, path: "/api/v1/claims", headers: map[string]string{apiKeyHeader: testRevoked}, status: http.StatusUnauthorized, code: codeInvalidCredentials},
+		{name: "bearer without verifier", method: http.MethodGet, path: "/api/v1/contracts", headers: map[string]string{"Authorization": "Bearer abc"}, status: http.StatusUnauthorized, code: codeInvalidCredentials},
+		{name: "admin key on key management", method: http.MethodPost, path: "/api/v1/api-keys", headers: map[string]string{apiKeyHeader: testAdminKey}, status: http.StatusOK, actor: adminActor},
+		{name: "scoped key on scoped route", method: http.MethodPost, path: "/api/v1/claims", headers: map[string]string{apiKeyHeader: testChainKey}, status: http.StatusOK, actor: "cvs"},
+		{name: "scoped key on unscoped route", method: http.MethodGet, path: "/api/v1/contracts", headers: map[string]string{apiKeyHeader: testNPIKey}, status: http.StatusForbidden, code: codeForbidden},
+		{name: "scoped key on key management", method: http.MethodPost, path: "/api/v1/api-keys", headers: map[string]string{apiKeyHeader: testChainKey}, status: http.StatusForbidden, code: codeForbidden},
+		{name: "health checks stay open", method: http.MethodGet, path: "/health/live", status: http.StatusOK, actor: anonymousActor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actor string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = actorFromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			for name, value := range tc.headers {
+				r.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+
+			server.authMiddleware(next).ServeHTTP(w, r)
+
+			require.Equal(t, tc.status, w.Code, w.Body.String())
+			if tc.status != http.StatusOK {
+				require.Equal(t, tc.code, decodeProblem(t, w).Code)
+				require.Empty(t, actor, "handler must not run")
+				return
+			}
+			require.Equal(t, tc.actor, actor)
+		})
+	}
+}
+
+func TestPrincipalAllows(t *testing.T) {
+	admin := &principal{Admin: true}
+	apiKey := &principal{Chain: "CVS"}
+	support := &principal{Permissions: auth.PermissionsFor([]string{auth.RoleSupport})}
+	adminRole := &principal{Admin: true, Permissions: auth.PermissionsFor([]string{auth.RoleAdmin})}
+
+	testCases := []struct {
+		name      string
+		principal *principal
+		pattern   string
+		allowed   bool
+	}{
+		{name: "admin key on key management", principal: admin, pattern: "POST /api/v1/api-keys", allowed: true},
+		{name: "admin role on key management", principal: adminRole, pattern: "POST /api/v1/api-keys", allowed: true},
+		{name: "api key on scoped route", principal: apiKey, pattern: "POST /api/v1/reversals", allowed: true},
+		{name: "api key on unscoped route", principal: apiKey, pattern: "POST /api/v1/reversals/batch"},
+		{name: "api key on key management", principal: apiKey, pattern: "POST /api/v1/api-keys"},
+		{name: "role with permission", principal: support, pattern: "POST /api/v1/reversals/batch", allowed: true},
+		{name: "role without permission", principal: support, pattern: "POST /api/v1/settlements"},
+		{name: "role on admin-only route", principal: support, pattern: "GET /api/v1/api-keys"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.allowed, tc.principal.allows(tc.pattern))
+		})
+	}
+}
+
+// withPrincipal returns a context authenticated as p, or an anonymous one when p is nil
+func withPrincipal(p *principal) context.Context {
+	if p == nil {
+		return context.Background()
+	}
+	return context.WithValue(context.Background(), principalContextKey, p)
+}
+
+func TestAuthorizePharmacy(t *testing.T) {
+	store, _ := authStore()
+	server := newAuthServer(t, store)
+
+	chainKey := &principal{Name: "cvs", Chain: "CVS"}
+	npiKey := &principal{Name: "cvs-1111111111", NPIs: []string{"1111111111"}}
+
+	testCases := []struct {
+		name      string
+		principal *principal
+		npi       string
+		allowed   bool
+	}{
+		{name: "authentication disabled", npi: "3333333333", allowed: true},
+		{name: "admin", principal: &principal{Admin: true}, npi: "3333333333", allowed: true},
+		{name: "unscoped role", principal: &principal{Permissions: auth.PermissionsFor([]string{auth.RoleSupport})}, npi: "3333333333", allowed: true},
+		{name: "listed npi", principal: npiKey, npi: "1111111111", allowed: true},
+		{name: "npi outside the list", principal: npiKey, npi: "2222222222"},
+		{name: "pharmacy of the chain", principal: chainKey, npi: "2222222222", allowed: true},
+		{name: "pharmacy of another chain", principal: chainKey, npi: "3333333333"},
+		{name: "unknown pharmacy", principal: chainKey, npi: "4444444444"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, err := server.authorizePharmacy(withPrincipal(tc.principal), tc.npi)
+			require.NoError(t, err)
+			require.Equal(t, tc.allowed, allowed)
+		})
+	}
+}
+
+func TestAuthorizeClaim(t *testing.T) {
+	store, claimIDs := authStore()
+	server := newAuthServer(t, store)
+
+	chainKey := &principal{Name: "cvs", Chain: "CVS"}
+	npiKey := &principal{Name: "cvs-1111111111", NPIs: []string{"1111111111"}}
+
+	testCases := []struct {
+		name      string
+		principal *principal
+		claimID   uuid.UUID
+		allowed   bool
+	}{
+		{name: "authentication disabled", claimID: claimIDs["3333333333"], allowed: true},
+		{name: "admin", principal: &principal{Admin: true}, claimID: claimIDs["3333333333"], allowed: true},
+		{name: "claim of a listed npi", principal: npiKey, claimID: claimIDs["1111111111"], allowed: true},
+		{name: "claim outside the npi list", principal: npiKey, claimID: claimIDs["2222222222"]},
+		{name: "claim of the chain", principal: chainKey, claimID: claimIDs["2222222222"], allowed: true},
+		{name: "claim of another chain", principal: chainKey, claimID: claimIDs["3333333333"]},
+		{name: "unknown claims are left to the handler", principal: npiKey, claimID: uuid.New(), allowed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			allowed, err := server.authorizeClaim(withPrincipal(tc.principal), tc.claimID)
+			require.NoError(t, err)
+			require.Equal(t, tc.allowed, allowed)
+		})
+	}
+}
+
+// TestRoutePermissionsGranted checks that every permission a route requires is granted by a role
+// other than admin, so that no route is reserved for admins by accident
+func TestRoutePermissionsGranted(t *testing.T) {
+	for pattern, permission := range routePermissions {
+		t.Run(pattern, func(t *testing.T) {
+			var roles []string
+			for role, permissions := range auth.RolePermissions {
+				if role != auth.RoleAdmin && slices.Contains(permissions, permission) {
+					roles = append(roles, role)
+				}
+			}
+			require.NotEmpty(t, roles, "no role grants %s", permission)
+		})
+	}
+}
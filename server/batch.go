@@ -0,0 +1,336 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+)
+
+const (
+	batchModeBestEffort   = "best_effort"
+	batchModeAllOrNothing = "all_or_nothing"
+)
+
+var errBatchTooLarge = errors.New("batch exceeds the maximum number of items")
+
+// createClaimBatch handles POST /api/v1/claims/batch
+func (server *Server) createClaimBatch(w http.ResponseWriter, r *http.Request) {
+	items, err := decodeBatch(w, r, server.config.BatchMaxClaims, server.config.MaxUploadBodyBytes)
+	if errors.Is(err, errBatchTooLarge) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, codeBatchTooLarge, fmt.Sprintf("Batch cannot contain more than %d claims", server.config.BatchMaxClaims), map[string]interface{}{
+			"max_items": server.config.BatchMaxClaims,
+		})
+		return
+	}
+	var rerr *requestError
+	if errors.As(err, &rerr) {
+		writeRequestError(w, r, rerr, nil)
+		return
+	}
+	if errors.As(err, new(*http.MaxBytesError)) {
+		writeRequestError(w, r, bodyReadError(err, server.config.MaxUploadBodyBytes), nil)
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, codeInvalidJSON, "Invalid batch format in request body", map[string]interface{}{
+			"expected_format": "JSON array of claim objects, or one claim object per line with Content-Type: application/x-ndjson",
+			"error":           err.Error(),
+		})
+		return
+	}
+
+	if len(items) == 0 {
+		writeError(w, r, http.StatusBadRequest, codeEmptyBatch, "Batch must contain at least one claim")
+		return
+	}
+
+	results := make([]BatchItemResult, len(items))
+	requests := make([]CreateClaimRequest, len(items))
+
+	// Validate every item first, collecting the valid ones for a single insert
+	var indexes []int
+	var args []db.CreateClaimTxParams
+
+	for i, raw := range items {
+		results[i].Index = i
+
+		if rerr := decodeJSONObject(raw, &requests[i]); rerr != nil {
+			claimsRejected.Inc(rejectInvalidJSON)
+			var details map[string]interface{}
+			if len(rerr.Fields) > 0 {
+				details = map[string]interface{}{"errors": rerr.Fields}
+			}
+			results[i].fail(rerr.Status, rerr.Code, rerr.Message, details)
+			continue
+		}
+
+		if verr := server.validateClaimRequest(requests[i]); verr != nil {
+			claimsRejected.Inc(verr.Reason)
+			results[i].fail(http.StatusBadRequest, codeInvalidFields, verr.Message, verr.extensions())
+			continue
+		}
+
+		indexes = append(indexes, i)
+		args = append(args, server.claimTxParams(requests[i]))
+	}
+
+	if len(args) > 0 {
+		created, err := server.store.CreateClaimBatchTx(r.Context(), args)
+		if err != nil {
+			writeStoreError(w, r, err, "Failed to create claim batch")
+			return
+		}
+
+		var submissions []logger.ClaimSubmission
+		for j, item := range created {
+			i := indexes[j]
+
+			if item.Err != nil {
+				verr, statusCode := createClaimError(item.Err)
+				claimsRejected.Inc(verr.Reason)
+				results[i].fail(statusCode, verr.Code, verr.Message, verr.extensions())
+				continue
+			}
+
+			claimsSubmitted.Inc()
+			recordAdjudication(item.Decision)
+			results[i].Status = "claim submitted"
+			if !item.Decision.Approved() {
+				results[i].Status = "claim rejected"
+			}
+			results[i].ClaimID = item.Claim.ID.String()
+			results[i].Adjudication = &item.Decision
+			if item.Quote != nil {
+				results[i].AllowedAmount = &item.Quote.AllowedAmount
+				results[i].PriceExceedsAllowed = item.Claim.PriceExceedsAllowed
+			}
+			if item.Claim.PossibleDuplicate {
+				results[i].PossibleDuplicate = true
+				results[i].DuplicateOf = item.DuplicateOf.String()
+			}
+
+			submissions = append(submissions, claimSubmission(item.Claim.ID, requests[i]))
+		}
+
+		// Log the claim submission events in one write
+		if err := server.logger.LogClaimSubmissions(submissions); err != nil {
+			log.Printf("Warning: failed to log claim submissions: %v", err)
+		}
+	}
+
+	accepted := 0
+	for _, result := range results {
+		if result.ClaimID != "" {
+			accepted++
+		}
+	}
+
+	response := APIResponse{
+		Success: true,
+		Status:  "batch processed",
+		Data: map[string]interface{}{
+			"total":    len(results),
+			"accepted": accepted,
+			"rejected": len(results) - accepted,
+			"results":  results,
+		},
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// createReversalBatch handles POST /api/v1/reversals/batch
+func (server *Server) createReversalBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchReversalRequest
+
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: claim_ids (array of UUID strings), reason_code (string), notes (string, optional), mode (string, optional)",
+		"example": map[string]interface{}{
+			"claim_ids":   []string{"550e8400-e29b-41d4-a716-446655440000"},
+			"reason_code": "BILLED_IN_ERROR",
+			"mode":        batchModeBestEffort,
+		},
+	}) {
+		return
+	}
+
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+	if req.OverrideWindow && !authorizeWindowOverride(r.Context()) {
+		writeWindowOverrideForbidden(w, r)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = batchModeBestEffort
+	}
+	if len(req.ClaimIDs) > server.config.BatchMaxClaims {
+		writeError(w, r, http.StatusRequestEntityTooLarge, codeBatchTooLarge, fmt.Sprintf("Batch cannot contain more than %d reversals", server.config.BatchMaxClaims), map[string]interface{}{
+			"max_items": server.config.BatchMaxClaims,
+		})
+		return
+	}
+
+	// Each claim is fully reversed, exactly as a single reversal without quantity or amount
+	args := make([]db.CreateReversalTxParams, len(req.ClaimIDs))
+	for i, claimID := range req.ClaimIDs {
+		args[i] = db.CreateReversalTxParams{
+			CreateReversalParams: sqlc.CreateReversalParams{
+				ClaimID:    claimID,
+				Kind:       db.ReversalKindReversal,
+				ReasonCode: req.ReasonCode,
+				Notes:      req.Notes,
+				Actor:      actorFromContext(r.Context()),
+			},
+			Window:         server.reversalWindowPolicy(),
+			OverrideWindow: req.OverrideWindow,
+		}
+	}
+
+	items, err := server.store.CreateReversalBatchTx(r.Context(), args, req.Mode == batchModeAllOrNothing)
+	rolledBack := errors.Is(err, db.ErrBatchRolledBack)
+	if err != nil && !rolledBack {
+		writeStoreError(w, r, err, "Failed to create reversal batch")
+		return
+	}
+
+	results := make([]BatchItemResult, len(items))
+	reversed := 0
+	var events []logger.ReversalEvent
+
+	for i, item := range items {
+		results[i].Index = i
+		results[i].ClaimID = item.ClaimID.String()
+
+		switch {
+		case item.Err == nil && rolledBack:
+			results[i].Status = "rolled_back"
+		case item.Err == nil:
+			reversed++
+			claimsReversed.Inc()
+			results[i].Status = "reversed"
+			results[i].ReversalID = item.Reversal.ID.String()
+			events = append(events, reversalEvent(item.CreateReversalTxResult))
+		default:
+			statusCode, code, message, details := createReversalError(item.Err)
+			results[i].fail(statusCode, code, message, details)
+			results[i].Status = reversalOutcome(item.Err)
+		}
+	}
+
+	// Log the claim reversal events in one write
+	if err := server.logger.LogClaimReversals(events); err != nil {
+		log.Printf("Warning: failed to log claim reversals: %v", err)
+	}
+
+	if rolledBack {
+		writeError(w, r, http.StatusConflict, codeBatchRolledBack, "Batch rolled back because at least one reversal failed", map[string]interface{}{
+			"mode":    req.Mode,
+			"results": results,
+		})
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Status:  "batch processed",
+		Data: map[string]interface{}{
+			"mode":     req.Mode,
+			"total":    len(results),
+			"reversed": reversed,
+			"failed":   len(results) - reversed,
+			"results":  results,
+		},
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// reversalOutcome names the per-claim outcome of a failed reversal
+func reversalOutcome(err error) string {
+	switch {
+	case errors.Is(err, db.ErrClaimNotFound):
+		return "not_found"
+	case errors.Is(err, db.ErrClaimAlreadyReversed):
+		return "already_reversed"
+	case errors.Is(err, db.ErrClaimRejected):
+		return "claim_rejected"
+	case errors.As(err, new(*db.ReversalWindowExpiredError)):
+		return "window_closed"
+	default:
+		return "error"
+	}
+}
+
+// fail marks the item as rejected with the problem describing why
+func (b *BatchItemResult) fail(statusCode int, code errorCode, detail string, extensions map[string]interface{}) {
+	b.Status = "error"
+	b.Error = newProblem(statusCode, code, detail, extensions)
+}
+
+// decodeBatch reads a JSON array or an NDJSON stream of items of at most maxBytes from the
+// request body
+func decodeBatch(w http.ResponseWriter, r *http.Request, maxItems int, maxBytes int64) ([]json.RawMessage, error) {
+	if rerr := requireContentType(r, "application/json", "application/x-ndjson", "application/ndjson"); rerr != nil {
+		return nil, rerr
+	}
+
+	var items []json.RawMessage
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-ndjson" || mediaType == "application/ndjson" {
+		for {
+			var item json.RawMessage
+			if err := decoder.Decode(&item); err == io.EOF {
+				return items, nil
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: %w", len(items)+1, err)
+			}
+
+			if len(items) == maxItems {
+				return nil, errBatchTooLarge
+			}
+			items = append(items, item)
+		}
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("expected a JSON array")
+	}
+
+	for decoder.More() {
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(items), err)
+		}
+
+		if len(items) == maxItems {
+			return nil, errBatchTooLarge
+		}
+		items = append(items, item)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON array")
+	}
+
+	return items, nil
+}
@@ -0,0 +1,418 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testClaim = `{"ndc": "00002323401", "npi": "9876543210", "quantity": 30, "price": 15.99}`
+
+// newBatchServer returns a server over store with small batch limits
+func newBatchServer(t *testing.T, store db.Store) *Server {
+	eventLogger, err := logger.NewLogger(t.TempDir())
+	require.NoError(t, err)
+
+	config := util.Config{
+		BatchMaxClaims:      3,
+		MaxRequestBodyBytes: 1 << 10,
+		MaxUploadBodyBytes:  1 << 10,
+	}
+	return NewServer(config, store, eventLogger, nil, nil, nil)
+}
+
+func postBatch(server *Server, path, contentType, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, r)
+	return w
+}
+
+func TestDecodeBatch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		items       int
+		err         string
+	}{
+		{name: "array", contentType: "application/json", body: `[{"a": 1}, {"a": 2}]`, items: 2},
+		{name: "empty array", contentType: "application/json", body: ` [ ] `, items: 0},
+		{name: "array at limit", contentType: "application/json", body: `[1, 2, 3]`, items: 3},
+		{name: "array over limit", contentType: "application/json", body: `[1, 2, 3, 4]`, err: errBatchTooLarge.Error()},
+		{name: "ndjson", contentType: "application/x-ndjson", body: "{\"a\": 1}\n{\"a\": 2}\n", items: 2},
+		{name: "ndjson alias", contentType: "application/ndjson; charset=utf-8", body: "{\"a\": 1}", items: 1},
+		{name: "empty ndjson", contentType: "application/x-ndjson", body: "", items: 0},
+		{name: "ndjson over limit", contentType: "application/x-ndjson", body: "1\n2\n3\n4\n", err: errBatchTooLarge.Error()},
+		{name: "ndjson malformed line", contentType: "application/x-ndjson", body: "{\"a\": 1}\n{\"a\":\n", err: "line 2"},
+		{name: "object", contentType: "application/json", body: `{"a": 1}`, err: "expected a JSON array"},
+		{name: "malformed item", contentType: "application/json", body: `[{"a": 1}, {"a":}]`, err: "item 1"},
+		{name: "unterminated array", contentType: "application/json", body: `[{"a": 1}`, err: "unexpected end"},
+		{name: "trailing data", contentType: "application/json", body: `[{"a": 1}] {"a": 2}`, err: "unexpected data after the JSON array"},
+		{name: "unsupported content type", contentType: "text/csv", body: `[]`, err: "Content-Type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/claims/batch", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+
+			items, err := decodeBatch(httptest.NewRecorder(), r, 3, 1<<10)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, items, tc.items)
+		})
+	}
+
+	// Bodies over the byte limit are cut off
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/claims/batch", strings.NewReader(`["`+strings.Repeat("x", 64)+`"]`))
+	r.Header.Set("Content-Type", "application/json")
+	_, err := decodeBatch(httptest.NewRecorder(), r, 3, 16)
+	require.ErrorAs(t, err, new(*http.MaxBytesError))
+}
+
+func TestCreateClaimBatchRequestErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		statusCode  int
+		code        errorCode
+	}{
+		{"empty array", "application/json", `[]`, http.StatusBadRequest, codeEmptyBatch},
+		{"empty ndjson", "application/x-ndjson", ``, http.StatusBadRequest, codeEmptyBatch},
+		{"too many claims", "application/json", "[" + strings.Repeat(testClaim+",", 3) + testClaim + "]", http.StatusRequestEntityTooLarge, codeBatchTooLarge},
+		{"trailing data", "application/json", "[" + testClaim + "] []", http.StatusBadRequest, codeInvalidJSON},
+		{"not an array", "application/json", testClaim, http.StatusBadRequest, codeInvalidJSON},
+		{"body too large", "application/json", "[" + strings.Repeat(" ", 2<<10) + "]", http.StatusRequestEntityTooLarge, codeBodyTooLarge},
+		{"unsupported content type", "text/plain", "[" + testClaim + "]", http.StatusUnsupportedMediaType, codeUnsupportedMediaType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The store is never reached
+			server := newBatchServer(t, &fakeStore{})
+
+			w := postBatch(server, "/api/v1/claims/batch", tc.contentType, tc.body)
+			require.Equal(t, tc.statusCode, w.Code)
+			require.Equal(t, tc.code, decodeProblem(t, w).Code)
+		})
+	}
+}
+
+func TestCreateClaimBatch(t *testing.T) {
+	var stored []db.CreateClaimTxParams
+	store := &fakeStore{
+		createClaimBatchTx: func(ctx context.Context, args []db.CreateClaimTxParams) ([]db.CreateClaimBatchItem, error) {
+			stored = args
+
+			items := make([]db.CreateClaimBatchItem, len(args))
+			for i, arg := range args {
+				if arg.NPI == "1234567890" {
+					items[i].Err = db.ErrPharmacyNotFound
+					continue
+				}
+				items[i].Claim = sqlc.Claim{ID: uuid.New(), NPI: arg.NPI, NDC: arg.NDC, Quantity: arg.Quantity, Price: arg.Price}
+				items[i].Decision = adjudication.Decision{Status: adjudication.StatusApproved}
+			}
+			return items, nil
+		},
+	}
+	server := newBatchServer(t, store)
+
+	// A valid claim, an item that is not an object, an invalid NDC and a claim the store refuses
+	body := strings.Join([]string{
+		testClaim,
+		`[30]`,
+		`{"ndc": "123", "npi": "9876543210", "quantity": 30, "price": 15.99}`,
+		`{"ndc": "00002323401", "npi": "1234567890", "quantity": 30, "price": 15.99}`,
+	}, "\n")
+	server.config.BatchMaxClaims = 4
+
+	w := postBatch(server, "/api/v1/claims/batch", "application/x-ndjson", body)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Only the items that passed validation reach the store, in order
+	require.Len(t, stored, 2)
+	require.Equal(t, "9876543210", stored[0].NPI)
+	require.Equal(t, "1234567890", stored[1].NPI)
+
+	var response struct {
+		Success bool   `json:"success"`
+		Status  string `json:"status"`
+		Data    struct {
+			Total    int               `json:"total"`
+			Accepted int               `json:"accepted"`
+			Rejected int               `json:"rejected"`
+			Results  []BatchItemResult `json:"results"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.True(t, response.Success)
+	require.Equal(t, "batch processed", response.Status)
+	require.Equal(t, 4, response.Data.Total)
+	require.Equal(t, 1, response.Data.Accepted)
+	require.Equal(t, 3, response.Data.Rejected)
+	require.Len(t, response.Data.Results, 4)
+
+	created := response.Data.Results[0]
+	require.Equal(t, "claim submitted", created.Status)
+	require.NotEmpty(t, created.ClaimID)
+	require.Nil(t, created.Error)
+
+	// Only the stored claim is logged
+	events, err := server.logger.GetEventsByType(logger.EventClaimSubmitted)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, created.ClaimID, events[0].Data["claim_id"])
+
+	for i, expected := range []struct {
+		statusCode int
+		code       errorCode
+	}{
+		{http.StatusBadRequest, codeInvalidJSON},
+		{http.StatusBadRequest, codeInvalidFields},
+		{http.StatusUnprocessableEntity, codeUnknownPharmacy},
+	} {
+		result := response.Data.Results[i+1]
+		require.Equal(t, i+1, result.Index)
+		require.Equal(t, "error", result.Status)
+		require.Empty(t, result.ClaimID)
+		require.NotNil(t, result.Error)
+		require.Equal(t, expected.statusCode, result.Error.Status)
+		require.Equal(t, expected.code, result.Error.Code)
+	}
+}
+
+func TestCreateClaimBatchStoreError(t *testing.T) {
+	store := &fakeStore{
+		createClaimBatchTx: func(ctx context.Context, args []db.CreateClaimTxParams) ([]db.CreateClaimBatchItem, error) {
+			return nil, db.TranslateError(&pgconn.ConnectError{})
+		},
+	}
+	server := newBatchServer(t, store)
+
+	w := postBatch(server, "/api/v1/claims/batch", "application/json", "["+testClaim+"]")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, codeServiceUnavailable, decodeProblem(t, w).Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Nothing is stored when every item fails validation
+	store.createClaimBatchTx = func(ctx context.Context, args []db.CreateClaimTxParams) ([]db.CreateClaimBatchItem, error) {
+		return nil, errors.New("unexpected call")
+	}
+	w = postBatch(server, "/api/v1/claims/batch", "application/json", `[{"ndc": "123"}]`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, bytes.Contains(w.Body.Bytes(), []byte(`"accepted":0`)))
+}
+
+// reversalBatchStore fails the reversal of each claim with the error in failures, and rolls the
+// batch back when allOrNothing is set and any reversal failed
+func reversalBatchStore(failures map[uuid.UUID]error, calls *[]bool) *fakeStore {
+	return &fakeStore{
+		createReversalBatchTx: func(ctx context.Context, args []db.CreateReversalTxParams, allOrNothing bool) ([]db.CreateReversalBatchItem, error) {
+			*calls = append(*calls, allOrNothing)
+
+			items := make([]db.CreateReversalBatchItem, len(args))
+			failed := false
+			for i, arg := range args {
+				items[i].ClaimID = arg.ClaimID
+				if err, ok := failures[arg.ClaimID]; ok {
+					items[i].Err = err
+					failed = true
+					continue
+				}
+				items[i].Reversal = sqlc.Reversal{ID: uuid.New(), ClaimID: arg.ClaimID, Kind: arg.Kind, ReasonCode: arg.ReasonCode}
+			}
+
+			if allOrNothing && failed {
+				return items, db.ErrBatchRolledBack
+			}
+			return items, nil
+		},
+	}
+}
+
+func reversalBatchBody(mode string, claimIDs ...uuid.UUID) string {
+	body, _ := json.Marshal(map[string]interface{}{
+		"claim_ids":   claimIDs,
+		"reason_code": "BILLED_IN_ERROR",
+		"mode":        mode,
+	})
+	return string(body)
+}
+
+// reversalBatchResults reads the per-claim results of a reversal batch response, which are the
+// data of a processed batch and an extension of a rolled back one
+func reversalBatchResults(t *testing.T, w *httptest.ResponseRecorder) []BatchItemResult {
+	var body struct {
+		Data struct {
+			Results []BatchItemResult `json:"results"`
+		} `json:"data"`
+		Results []BatchItemResult `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	if w.Code == http.StatusOK {
+		return body.Data.Results
+	}
+	return body.Results
+}
+
+func TestCreateReversalBatch(t *testing.T) {
+	reversed, missing, rejected, expired := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	failures := map[uuid.UUID]error{
+		missing:  db.ErrClaimNotFound,
+		rejected: db.ErrClaimRejected,
+		expired:  &db.ReversalWindowExpiredError{Window: 24 * time.Hour},
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		var calls []bool
+		server := newBatchServer(t, reversalBatchStore(failures, &calls))
+		server.config.BatchMaxClaims = 4
+
+		// The mode defaults to best effort
+		w := postBatch(server, "/api/v1/reversals/batch", "application/json", reversalBatchBody("", reversed, missing, rejected, expired))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, []bool{false}, calls)
+
+		var body struct {
+			Status string `json:"status"`
+			Data   struct {
+				Mode     string `json:"mode"`
+				Total    int    `json:"total"`
+				Reversed int    `json:"reversed"`
+				Failed   int    `json:"failed"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "batch processed", body.Status)
+		require.Equal(t, batchModeBestEffort, body.Data.Mode)
+		require.Equal(t, 4, body.Data.Total)
+		require.Equal(t, 1, body.Data.Reversed)
+		require.Equal(t, 3, body.Data.Failed)
+
+		results := reversalBatchResults(t, w)
+		require.Len(t, results, 4)
+		require.Equal(t, "reversed", results[0].Status)
+		require.NotEmpty(t, results[0].ReversalID)
+
+		for i, expected := range []struct {
+			status     string
+			statusCode int
+			code       errorCode
+		}{
+			{"not_found", http.StatusNotFound, codeNotFound},
+			{"claim_rejected", http.StatusConflict, codeClaimRejected},
+			{"window_closed", http.StatusUnprocessableEntity, codeReversalWindowClosed},
+		} {
+			result := results[i+1]
+			require.Equal(t, expected.status, result.Status)
+			require.Empty(t, result.ReversalID)
+			require.Equal(t, expected.statusCode, result.Error.Status)
+			require.Equal(t, expected.code, result.Error.Code)
+		}
+	})
+
+	t.Run("all or nothing rolled back", func(t *testing.T) {
+		var calls []bool
+		server := newBatchServer(t, reversalBatchStore(failures, &calls))
+
+		w := postBatch(server, "/api/v1/reversals/batch", "application/json", reversalBatchBody(batchModeAllOrNothing, reversed, missing))
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Equal(t, []bool{true}, calls)
+		require.Equal(t, codeBatchRolledBack, decodeProblem(t, w).Code)
+
+		results := reversalBatchResults(t, w)
+		require.Len(t, results, 2)
+		require.Equal(t, "rolled_back", results[0].Status)
+		require.Empty(t, results[0].ReversalID)
+		require.Nil(t, results[0].Error)
+		require.Equal(t, "not_found", results[1].Status)
+	})
+
+	t.Run("all or nothing committed", func(t *testing.T) {
+		var calls []bool
+		server := newBatchServer(t, reversalBatchStore(failures, &calls))
+
+		w := postBatch(server, "/api/v1/reversals/batch", "application/json", reversalBatchBody(batchModeAllOrNothing, reversed, uuid.New()))
+		require.Equal(t, http.StatusOK, w.Code)
+		for _, result := range reversalBatchResults(t, w) {
+			require.Equal(t, "reversed", result.Status)
+		}
+	})
+}
+
+func TestCreateReversalBatchRequestErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+		code       errorCode
+	}{
+		{"no claims", reversalBatchBody(""), http.StatusBadRequest, codeInvalidFields},
+		{"unknown mode", reversalBatchBody("sometimes", uuid.New()), http.StatusBadRequest, codeInvalidFields},
+		{"too many claims", reversalBatchBody("", uuid.New(), uuid.New(), uuid.New(), uuid.New()), http.StatusRequestEntityTooLarge, codeBatchTooLarge},
+		{"invalid claim ID", `{"claim_ids": ["42"], "reason_code": "BILLED_IN_ERROR"}`, http.StatusBadRequest, codeInvalidFields},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newBatchServer(t, &fakeStore{})
+
+			w := postBatch(server, "/api/v1/reversals/batch", "application/json", tc.body)
+			require.Equal(t, tc.statusCode, w.Code)
+			require.Equal(t, tc.code, decodeProblem(t, w).Code)
+		})
+	}
+
+	// Failures of the batch as a whole are store errors
+	server := newBatchServer(t, &fakeStore{
+		createReversalBatchTx: func(ctx context.Context, args []db.CreateReversalTxParams, allOrNothing bool) ([]db.CreateReversalBatchItem, error) {
+			return nil, db.TranslateError(&pgconn.PgError{Code: "40001"})
+		},
+	})
+	w := postBatch(server, "/api/v1/reversals/batch", "application/json", reversalBatchBody("", uuid.New()))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, codeTransactionConflict, decodeProblem(t, w).Code)
+}
+
+func TestReversalOutcome(t *testing.T) {
+	testCases := []struct {
+		err     error
+		outcome string
+	}{
+		{db.ErrClaimNotFound, "not_found"},
+		{db.ErrClaimAlreadyReversed, "already_reversed"},
+		{db.ErrClaimRejected, "claim_rejected"},
+		{&db.ReversalWindowExpiredError{}, "window_closed"},
+		{fmt.Errorf("item 3: %w", db.ErrClaimNotFound), "not_found"},
+		{db.ErrInvalidReasonCode, "error"},
+		{errors.New("boom"), "error"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.outcome, reversalOutcome(tc.err), tc.err.Error())
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pharmacy_claims_application/claimfile"
+	"github.com/pharmacy_claims_application/db"
+	"github.com/pharmacy_claims_application/logger"
+)
+
+// uploadClaimFile handles POST /api/v1/claims/files. The body is a claim batch file and the
+// response is its acknowledgement file.
+func (server *Server) uploadClaimFile(w http.ResponseWriter, r *http.Request) {
+	if rerr := requireContentType(r, "text/plain", "application/octet-stream"); rerr != nil {
+		writeRequestError(w, r, rerr, nil)
+		return
+	}
+
+	ack, err := server.IngestClaimFile(r.Context(), http.MaxBytesReader(w, r.Body, server.config.MaxUploadBodyBytes))
+	if errors.As(err, new(*http.MaxBytesError)) {
+		writeRequestError(w, r, bodyReadError(err, server.config.MaxUploadBodyBytes), nil)
+		return
+	}
+	if errors.Is(err, claimfile.ErrTooManyRecords) {
+		writeError(w, r, http.StatusRequestEntityTooLarge, codeBatchTooLarge, fmt.Sprintf("Claim file cannot contain more than %d claim records", server.config.ClaimFileMaxRecords), map[string]interface{}{
+			"max_records": server.config.ClaimFileMaxRecords,
+		})
+		return
+	}
+	if err != nil {
+		var lineErr *claimfile.LineError
+		if errors.As(err, &lineErr) || errors.Is(err, claimfile.ErrMissingHeader) || errors.Is(err, claimfile.ErrMissingTrailer) {
+			details := map[string]interface{}{
+				"expected_format": "HD header, CL claim records and TR trailer, pipe-delimited or fixed-width",
+				"error":           err.Error(),
+			}
+			if lineErr != nil {
+				details["line"] = lineErr.Line
+			}
+			writeError(w, r, http.StatusBadRequest, codeInvalidClaimFile, "Invalid claim file", details)
+			return
+		}
+		writeStoreError(w, r, err, "Failed to ingest claim file")
+		return
+	}
+
+	var body bytes.Buffer
+	if err := claimfile.WriteAck(&body, ack); err != nil {
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "Failed to write acknowledgement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ackFileName(ack.Header)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// IngestClaimFile parses a claim batch file and submits every readable record with the same
+// validation, duplicate detection, pricing and adjudication as POST /api/v1/claims. Records are
+// stored independently, so a rejected record does not affect the others. An error is returned
+// only when the file itself is malformed or the store fails.
+func (server *Server) IngestClaimFile(ctx context.Context, body io.Reader) (claimfile.Ack, error) {
+	file, err := claimfile.Parse(body, server.config.ClaimFileMaxRecords)
+	if err != nil {
+		return claimfile.Ack{}, err
+	}
+
+	ack := claimfile.Ack{
+		Header:    file.Header,
+		Processed: time.Now(),
+		Results:   make([]claimfile.AckResult, len(file.Records)),
+	}
+	requests := make([]CreateClaimRequest, len(file.Records))
+
+	var indexes []int
+	var args []db.CreateClaimTxParams
+
+	for i, record := range file.Records {
+		result := &ack.Results[i]
+		result.Line = record.Line
+		result.Sequence = record.Sequence
+
+		if record.Err != nil {
+			claimsRejected.Inc(rejectInvalidRecord)
+			result.Reason = rejectInvalidRecord
+			result.Message = record.Err.Error()
+			continue
+		}
+
+		requests[i] = CreateClaimRequest{
+			NDC:      record.Claim.NDC,
+			NPI:      record.Claim.NPI,
+			Quantity: int(record.Claim.Quantity),
+			Price:    record.Claim.Price,
+		}
+
+		if verr := server.validateClaimRequest(requests[i]); verr != nil {
+			claimsRejected.Inc(verr.Reason)
+			result.Reason = verr.Reason
+			result.Message = verr.Message
+			continue
+		}
+
+		indexes = append(indexes, i)
+		args = append(args, server.claimTxParams(requests[i]))
+	}
+
+	if len(args) == 0 {
+		return ack, nil
+	}
+
+	created, err := server.store.CreateClaimBatchTx(ctx, args)
+	if err != nil {
+		return claimfile.Ack{}, err
+	}
+
+	var submissions []logger.ClaimSubmission
+	for j, item := range created {
+		i := indexes[j]
+		result := &ack.Results[i]
+
+		if item.Err != nil {
+			verr, _ := createClaimError(item.Err)
+			claimsRejected.Inc(verr.Reason)
+			result.Reason = verr.Reason
+			result.Message = verr.Message
+			continue
+		}
+
+		claimsSubmitted.Inc()
+		recordAdjudication(item.Decision)
+		result.Accepted = true
+		result.ClaimID = item.Claim.ID.String()
+		result.Status = item.Decision.Status
+		result.RejectCodes = item.Decision.Codes()
+
+		submissions = append(submissions, claimSubmission(item.Claim.ID, requests[i]))
+	}
+
+	// Log the claim submission events in one write
+	if err := server.logger.LogClaimSubmissions(submissions); err != nil {
+		log.Printf("Warning: failed to log claim submissions: %v", err)
+	}
+
+	return ack, nil
+}
+
+// ackFileName is the suggested name of the acknowledgement of a claim file
+func ackFileName(header claimfile.Header) string {
+	return fmt.Sprintf("ack-%s-%s.txt", header.SenderID, header.BatchID)
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pharmacy_claims_application/claimfile"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadClaimFileLineTooLong(t *testing.T) {
+	server := NewServer(util.Config{MaxUploadBodyBytes: 1 << 20}, nil, nil, nil, nil, nil)
+
+	body := "HD|ACME|B0001|20250307\nCL|1|1234567890|00002323401|30|" + strings.Repeat("9", claimfile.MaxLineLength) + "\nTR|1\n"
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/claims/files", strings.NewReader(body))
+	r.Header.Set("Content-Type", "text/plain")
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Equal(t, codeInvalidClaimFile, decodeProblem(t, w).Code)
+
+	var members map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &members))
+	require.Equal(t, float64(2), members["line"])
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// Lifecycle status of a v2 claim
+const (
+	claimStatusApproved          = "approved"
+	claimStatusRejected          = "rejected"
+	claimStatusPartiallyReversed = "partially_reversed"
+	claimStatusReversed          = "reversed"
+)
+
+// Decimal is a currency amount written as a JSON string with two decimal places, such as "15.99",
+// so clients never parse money as a binary float
+type Decimal float64
+
+// MarshalJSON writes the amount rounded to cents
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatFloat(float64(d), 'f', 2, 64))), nil
+}
+
+// formatTimestamp formats a stored time as RFC 3339 in UTC with millisecond precision
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
+
+// createClaimV2 handles POST /api/v2/claims
+func (server *Server) createClaimV2(w http.ResponseWriter, r *http.Request) {
+	server.submitClaim(w, r, apiV2, func(result db.CreateClaimTxResult) interface{} {
+		claim := convertDBClaimToV2(result.Claim, result.Pharmacy, nil)
+		if result.Claim.PossibleDuplicate {
+			claim.DuplicateOf = result.DuplicateOf.String()
+		}
+		return APIResponse{
+			Success: true,
+			Data:    claim,
+		}
+	})
+}
+
+// getClaimV2 handles GET /api/v2/claims/{id}
+func (server *Server) getClaimV2(w http.ResponseWriter, r *http.Request) {
+	claim, reversals, ok := server.loadClaim(w, r)
+	if !ok {
+		return
+	}
+
+	pharmacy, err := server.store.GetPharmacy(r.Context(), claim.NPI)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to load claim pharmacy")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    convertDBClaimToV2(claim, pharmacy, reversals),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// convertDBClaimToV2 converts a database claim, its pharmacy and its reversals to the v2 format
+func convertDBClaimToV2(dbClaim sqlc.Claim, pharmacy sqlc.Pharmacy, reversals []sqlc.Reversal) ClaimV2 {
+	balance := db.NewClaimBalance(dbClaim, reversals)
+	decision := adjudication.DecisionFromClaim(dbClaim)
+
+	claim := ClaimV2{
+		ID:       dbClaim.ID.String(),
+		Status:   claimStatus(decision, balance),
+		NDC:      dbClaim.NDC,
+		Quantity: dbClaim.Quantity,
+		Pharmacy: ClaimPharmacy{
+			NPI:    pharmacy.NPI,
+			Chain:  pharmacy.Chain,
+			Active: pharmacy.Active,
+		},
+		SubmittedAmount:     Decimal(dbClaim.Price),
+		PriceExceedsAllowed: dbClaim.PriceExceedsAllowed,
+		PossibleDuplicate:   dbClaim.PossibleDuplicate,
+		Adjudication:        decision,
+		Balance: ClaimBalanceV2{
+			ReversedQuantity: balance.ReversedQuantity,
+			ReversedAmount:   Decimal(balance.ReversedAmount),
+			AdjustedQuantity: balance.AdjustedQuantity,
+			AdjustedAmount:   Decimal(balance.AdjustedAmount),
+			NetQuantity:      balance.NetQuantity,
+			NetAmount:        Decimal(balance.NetAmount),
+		},
+		Reversals:   make([]ReversalV2, 0, len(reversals)),
+		SubmittedAt: formatTimestamp(dbClaim.Timestamp),
+	}
+
+	if dbClaim.AllowedAmount.Valid {
+		allowed := Decimal(dbClaim.AllowedAmount.Float64)
+		claim.AllowedAmount = &allowed
+	}
+
+	for _, reversal := range reversals {
+		claim.Reversals = append(claim.Reversals, ReversalV2{
+			ID:         reversal.ID.String(),
+			Kind:       reversal.Kind,
+			Quantity:   reversal.Quantity,
+			Amount:     Decimal(reversal.Amount),
+			ReasonCode: reversal.ReasonCode,
+			Notes:      reversal.Notes,
+			Actor:      reversal.Actor,
+			CreatedAt:  formatTimestamp(reversal.Timestamp),
+		})
+	}
+
+	return claim
+}
+
+// claimStatus derives where a claim is in its lifecycle from its adjudication and balance
+func claimStatus(decision adjudication.Decision, balance db.ClaimBalance) string {
+	switch {
+	case !decision.Approved():
+		return claimStatusRejected
+	case balance.FullyReversed():
+		return claimStatusReversed
+	case balance.ReversedQuantity > 0 || balance.ReversedAmount > 0:
+		return claimStatusPartiallyReversed
+	default:
+		return claimStatusApproved
+	}
+}
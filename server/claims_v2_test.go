@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertDBClaimToV2(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+	claim := sqlc.Claim{
+		ID:            uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		NDC:           "00002323401",
+		Quantity:      30,
+		NPI:           "9876543210",
+		Price:         15.9,
+		Timestamp:     time.Date(2026, time.October, 19, 9, 3, 7, 512000000, eastern),
+		Status:        adjudication.StatusApproved,
+		AllowedAmount: pgtype.Float8{Float64: 12.5, Valid: true},
+	}
+	pharmacy := sqlc.Pharmacy{NPI: "9876543210", Chain: "CVS", Active: true}
+	reversals := []sqlc.Reversal{{
+		ID:         uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"),
+		ClaimID:    claim.ID,
+		Timestamp:  time.Date(2026, time.October, 20, 8, 0, 0, 0, time.UTC),
+		Kind:       db.ReversalKindReversal,
+		Quantity:   10,
+		Amount:     5.3,
+		ReasonCode: "BILLED_IN_ERROR",
+		Actor:      "admin",
+	}}
+
+	body, err := json.Marshal(convertDBClaimToV2(claim, pharmacy, reversals))
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"id": "550e8400-e29b-41d4-a716-446655440000",
+		"status": "partially_reversed",
+		"ndc": "00002323401",
+		"quantity": 30,
+		"pharmacy": {"npi": "9876543210", "chain": "CVS", "active": true},
+		"submitted_amount": "15.90",
+		"allowed_amount": "12.50",
+		"price_exceeds_allowed": false,
+		"possible_duplicate": false,
+		"adjudication": {"status": "approved"},
+		"balance": {
+			"reversed_quantity": 10,
+			"reversed_amount": "5.30",
+			"adjusted_quantity": 0,
+			"adjusted_amount": "0.00",
+			"net_quantity": 20,
+			"net_amount": "10.60"
+		},
+		"reversals": [{
+			"id": "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+			"kind": "reversal",
+			"quantity": 10,
+			"amount": "5.30",
+			"reason_code": "BILLED_IN_ERROR",
+			"actor": "admin",
+			"created_at": "2026-10-20T08:00:00.000Z"
+		}],
+		"submitted_at": "2026-10-19T14:03:07.512Z"
+	}`, string(body))
+
+	// A claim without reversals or a reference price still has both members
+	claim.AllowedAmount = pgtype.Float8{}
+	body, err = json.Marshal(convertDBClaimToV2(claim, pharmacy, nil))
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"allowed_amount":null`)
+	require.Contains(t, string(body), `"reversals":[]`)
+}
+
+func TestClaimStatus(t *testing.T) {
+	approved := adjudication.Decision{Status: adjudication.StatusApproved}
+	rejected := adjudication.Decision{Status: adjudication.StatusRejected}
+
+	require.Equal(t, claimStatusApproved, claimStatus(approved, db.ClaimBalance{NetQuantity: 30, NetAmount: 15.99}))
+	require.Equal(t, claimStatusApproved, claimStatus(approved, db.ClaimBalance{AdjustedQuantity: 5, NetQuantity: 35, NetAmount: 18}))
+	require.Equal(t, claimStatusPartiallyReversed, claimStatus(approved, db.ClaimBalance{ReversedQuantity: 10, NetQuantity: 20, NetAmount: 10}))
+	require.Equal(t, claimStatusReversed, claimStatus(approved, db.ClaimBalance{ReversedQuantity: 30, ReversedAmount: 15.99}))
+	require.Equal(t, claimStatusRejected, claimStatus(rejected, db.ClaimBalance{NetQuantity: 30, NetAmount: 15.99}))
+}
+
+func TestDecimalJSON(t *testing.T) {
+	for value, expected := range map[Decimal]string{
+		0:       `"0.00"`,
+		15.99:   `"15.99"`,
+		2.5:     `"2.50"`,
+		-3:      `"-3.00"`,
+		1234.56: `"1234.56"`,
+	} {
+		body, err := json.Marshal(value)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(body))
+	}
+}
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// contractExample is shown in responses to malformed contract requests
+var contractExample = map[string]interface{}{
+	"chain":            "CVS",
+	"discount_percent": 15,
+	"dispensing_fee":   1.75,
+	"effective_from":   "2025-01-01",
+	"effective_to":     "2026-01-01",
+}
+
+// contractTerms are the validated fields of a contract request
+type contractTerms struct {
+	DiscountPercent float64
+	DispensingFee   float64
+	EffectiveFrom   time.Time
+	EffectiveTo     pgtype.Timestamptz
+}
+
+// listContracts handles GET /api/v1/contracts
+func (server *Server) listContracts(w http.ResponseWriter, r *http.Request) {
+	var chain pgtype.Text
+	if value := r.URL.Query().Get("chain"); value != "" {
+		chain = pgtype.Text{String: value, Valid: true}
+	}
+
+	contracts, err := server.store.ListContracts(r.Context(), chain)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list contracts")
+		return
+	}
+
+	data := make([]Contract, 0, len(contracts))
+	for _, contract := range contracts {
+		data = append(data, convertDBContractToAPI(contract))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getContract handles GET /api/v1/contracts/{id}
+func (server *Server) getContract(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseContractID(w, r)
+	if !ok {
+		return
+	}
+
+	contract, err := server.store.GetContract(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Contract not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to get contract")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    convertDBContractToAPI(contract),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// createContract handles POST /api/v1/contracts
+func (server *Server) createContract(w http.ResponseWriter, r *http.Request) {
+	var req ContractRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: chain (string), discount_percent (number), dispensing_fee (number), effective_from (date), effective_to (date, optional)",
+		"example":         contractExample,
+	}) {
+		return
+	}
+
+	terms, verr := validateContractRequest(req)
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	contract, err := server.store.CreateContractTx(r.Context(), sqlc.CreateContractParams{
+		Chain:           strings.TrimSpace(req.Chain),
+		DiscountPercent: terms.DiscountPercent,
+		DispensingFee:   terms.DispensingFee,
+		EffectiveFrom:   terms.EffectiveFrom,
+		EffectiveTo:     terms.EffectiveTo,
+	})
+	if err != nil {
+		writeContractError(w, r, err, "Failed to create contract")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Contract created successfully",
+		Data:    convertDBContractToAPI(contract),
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// updateContract handles PUT /api/v1/contracts/{id}. The chain of a contract cannot change.
+func (server *Server) updateContract(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseContractID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ContractRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: discount_percent (number), dispensing_fee (number), effective_from (date), effective_to (date, optional)",
+		"example":         contractExample,
+	}) {
+		return
+	}
+
+	existing, err := server.store.GetContract(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Contract not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to update contract")
+		return
+	}
+
+	if req.Chain == "" {
+		req.Chain = existing.Chain
+	}
+	if strings.TrimSpace(req.Chain) != existing.Chain {
+		verr := invalidField("chain", "cannot be changed")
+		verr.Details = map[string]interface{}{"chain": existing.Chain}
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	terms, verr := validateContractRequest(req)
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	contract, err := server.store.UpdateContractTx(r.Context(), sqlc.UpdateContractParams{
+		ID:              id,
+		DiscountPercent: terms.DiscountPercent,
+		DispensingFee:   terms.DispensingFee,
+		EffectiveFrom:   terms.EffectiveFrom,
+		EffectiveTo:     terms.EffectiveTo,
+	})
+	if err != nil {
+		writeContractError(w, r, err, "Failed to update contract")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Contract updated successfully",
+		Data:    convertDBContractToAPI(contract),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// chainPricingReport handles GET /api/v1/reports/chain-pricing
+func (server *Server) chainPricingReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var arg sqlc.ChainPricingReportParams
+
+	if chain := query.Get("chain"); chain != "" {
+		arg.Chain = pgtype.Text{String: chain, Valid: true}
+	}
+
+	for _, bound := range []struct {
+		name   string
+		target *pgtype.Timestamptz
+	}{
+		{name: "since", target: &arg.Since},
+		{name: "until", target: &arg.Until},
+	} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseTime(value)
+		if err != nil {
+			writeParameterError(w, r, bound.name, "must be an RFC3339 timestamp", "2024-01-01T00:00:00Z")
+			return
+		}
+		*bound.target = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+
+	rows, err := server.store.ChainPricingReport(r.Context(), arg)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to build chain pricing report")
+		return
+	}
+
+	data := make([]ChainPricingSummary, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, ChainPricingSummary{
+			Chain:                row.Chain,
+			ClaimCount:           row.ClaimCount,
+			PricedCount:          row.PricedCount,
+			ExceedingCount:       row.ExceedingCount,
+			SubmittedTotal:       roundCents(row.SubmittedTotal),
+			PricedSubmittedTotal: roundCents(row.PricedSubmittedTotal),
+			ContractedTotal:      roundCents(row.ContractedTotal),
+			Variance:             roundCents(row.PricedSubmittedTotal - row.ContractedTotal),
+		})
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeContractError writes the response for a contract that could not be saved. Terms whose
+// dates overlap another contract of the chain are refused, so that exactly one contract is in
+// effect on any claim date.
+func writeContractError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	var overlap *db.ContractOverlapError
+	switch {
+	case errors.As(err, &overlap):
+		writeError(w, r, http.StatusConflict, codeContractOverlap, "Contract dates overlap an existing contract for the chain", map[string]interface{}{
+			"contract_id": overlap.Contract.ID.String(),
+			"chain":       overlap.Contract.Chain,
+		})
+	case errors.Is(err, db.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, codeNotFound, "Contract not found")
+	default:
+		writeStoreError(w, r, err, message)
+	}
+}
+
+// validateContractRequest checks the terms shared by contract creation and replacement
+func validateContractRequest(req ContractRequest) (contractTerms, *validationError) {
+	if verr := validateRequest(req); verr != nil {
+		return contractTerms{}, verr
+	}
+
+	// The dates were checked by validateRequest
+	from, _ := parseEffectiveDate(req.EffectiveFrom)
+	terms := contractTerms{
+		DiscountPercent: req.DiscountPercent,
+		DispensingFee:   req.DispensingFee,
+		EffectiveFrom:   from,
+	}
+
+	if req.EffectiveTo != "" {
+		to, _ := parseEffectiveDate(req.EffectiveTo)
+		if !to.After(from) {
+			return terms, invalidField("effective_to", "must be after effective_from")
+		}
+		terms.EffectiveTo = pgtype.Timestamptz{Time: to, Valid: true}
+	}
+
+	return terms, nil
+}
+
+// parseContractID reads the contract ID path value, writing a 400 when it is not a UUID
+func parseContractID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeParameterError(w, r, "id", "must be a UUID", "550e8400-e29b-41d4-a716-446655440000")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// roundCents rounds a report total to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// convertDBContractToAPI converts a database contract to the API model
+func convertDBContractToAPI(contract sqlc.Contract) Contract {
+	result := Contract{
+		ID:              contract.ID.String(),
+		Chain:           contract.Chain,
+		DiscountPercent: contract.DiscountPercent,
+		DispensingFee:   contract.DispensingFee,
+		EffectiveFrom:   contract.EffectiveFrom,
+		Timestamp:       contract.Timestamp,
+	}
+
+	if contract.EffectiveTo.Valid {
+		result.EffectiveTo = &contract.EffectiveTo.Time
+	}
+
+	return result
+}
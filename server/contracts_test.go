@@ -0,0 +1,47 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteContractError(t *testing.T) {
+	overlapping := sqlc.Contract{ID: uuid.New(), Chain: "CVS"}
+
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   errorCode
+	}{
+		{name: "overlap", err: &db.ContractOverlapError{Contract: overlapping}, status: http.StatusConflict, code: codeContractOverlap},
+		{name: "not found", err: &db.Error{Kind: db.ErrNotFound, Err: pgx.ErrNoRows}, status: http.StatusNotFound, code: codeNotFound},
+		{name: "other", err: errors.New("boom"), status: http.StatusInternalServerError, code: codeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/contracts", nil)
+			w := httptest.NewRecorder()
+
+			writeContractError(w, r, tc.err, "Failed to create contract")
+
+			require.Equal(t, tc.status, w.Code)
+			require.Equal(t, tc.code, decodeProblem(t, w).Code)
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/contracts", nil)
+	w := httptest.NewRecorder()
+	writeContractError(w, r, &db.ContractOverlapError{Contract: overlapping}, "Failed to create contract")
+	require.Contains(t, w.Body.String(), `"contract_id":"`+overlapping.ID.String()+`"`)
+	require.Contains(t, w.Body.String(), `"chain":"CVS"`)
+}
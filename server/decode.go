@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// fieldError describes one invalid field of a request
+type fieldError struct {
+	Field string `json:"field"`
+	// Rule is the validate tag rule the field failed, if any
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// requestError is a request body that could not be decoded
+type requestError struct {
+	Status  int
+	Code    errorCode
+	Message string
+	Fields  []fieldError
+}
+
+func (e *requestError) Error() string {
+	return e.Message
+}
+
+// decodeJSON decodes a JSON object request body into v, writing an error response when it
+// cannot. usage describes the expected body and is added to the error response.
+func (server *Server) decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, usage map[string]interface{}) bool {
+	if rerr := decodeJSONBody(w, r, v, server.config.MaxRequestBodyBytes, false); rerr != nil {
+		writeRequestError(w, r, rerr, usage)
+		return false
+	}
+	return true
+}
+
+// writeRequestError writes the response for a request body that could not be decoded
+func writeRequestError(w http.ResponseWriter, r *http.Request, rerr *requestError, usage map[string]interface{}) {
+	details := make(map[string]interface{}, len(usage)+1)
+	maps.Copy(details, usage)
+	if len(rerr.Fields) > 0 {
+		details["errors"] = rerr.Fields
+	}
+
+	writeError(w, r, rerr.Status, rerr.Code, rerr.Message, details)
+}
+
+// decodeJSONBody reads a single JSON object of at most maxBytes from the request body into v,
+// which must point to a struct. An empty body is accepted when optional is set.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}, maxBytes int64, optional bool) *requestError {
+	body, rerr := readBody(w, r, maxBytes)
+	if rerr != nil {
+		return rerr
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if optional {
+			return nil
+		}
+		return &requestError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "Request body is required"}
+	}
+
+	if rerr := requireContentType(r, "application/json"); rerr != nil {
+		return rerr
+	}
+
+	return decodeJSONObject(body, v)
+}
+
+// readBody reads the whole request body, refusing bodies larger than maxBytes
+func readBody(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, *requestError) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		return nil, bodyReadError(err, maxBytes)
+	}
+	return body, nil
+}
+
+// bodyReadError maps a failure to read the request body to a request error
+func bodyReadError(err error, maxBytes int64) *requestError {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &requestError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    codeBodyTooLarge,
+			Message: fmt.Sprintf("Request body cannot be larger than %d bytes", maxBytes),
+		}
+	}
+	return &requestError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "Failed to read request body"}
+}
+
+// requireContentType refuses requests whose Content-Type is not one of the given media types
+func requireContentType(r *http.Request, mediaTypes ...string) *requestError {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if slices.Contains(mediaTypes, mediaType) {
+		return nil
+	}
+
+	return &requestError{
+		Status:  http.StatusUnsupportedMediaType,
+		Code:    codeUnsupportedMediaType,
+		Message: fmt.Sprintf("Content-Type must be %s", strings.Join(mediaTypes, " or ")),
+	}
+}
+
+// decodeJSONObject decodes one JSON object into the struct v. Unlike json.Decoder, it reports
+// every unknown field and every field of the wrong type rather than stopping at the first.
+func decodeJSONObject(data []byte, v interface{}) *requestError {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	var fields map[string]json.RawMessage
+	if err := decoder.Decode(&fields); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return &requestError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "Request body must be a JSON object"}
+		}
+		return &requestError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "Invalid JSON format in request body"}
+	}
+	if fields == nil {
+		return &requestError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "Request body must be a JSON object"}
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return &requestError{Status: http.StatusBadRequest, Code: codeInvalidJSON, Message: "Request body must contain a single JSON object"}
+	}
+
+	target := reflect.ValueOf(v).Elem()
+	known := jsonFields(target.Type())
+
+	var errs []fieldError
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		index, ok := known[name]
+		if !ok {
+			errs = append(errs, fieldError{Field: name, Message: "unknown field"})
+			continue
+		}
+
+		field := target.Field(index)
+		if err := json.Unmarshal(fields[name], field.Addr().Interface()); err != nil {
+			errs = append(errs, fieldError{Field: name, Message: typeErrorMessage(field.Type())})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &requestError{
+			Status:  http.StatusBadRequest,
+			Code:    codeInvalidFields,
+			Message: "Request body contains invalid fields",
+			Fields:  errs,
+		}
+	}
+	return nil
+}
+
+// jsonFields maps the JSON names of a struct's exported fields to their index
+func jsonFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = i
+	}
+	return fields
+}
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// typeErrorMessage describes the JSON value expected for a Go type
+func typeErrorMessage(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == uuidType:
+		return "must be a UUID string"
+	case t.Kind() == reflect.Slice && t.Elem() == uuidType:
+		return "must be an array of UUID strings"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "must be a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "must be an integer"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.Bool:
+		return "must be a boolean"
+	case reflect.Slice:
+		return "must be an array"
+	default:
+		return "has an invalid value"
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSONObject(t *testing.T) {
+	var req CreateReversalRequest
+	rerr := decodeJSONObject([]byte(`{"claim_id": "550e8400-e29b-41d4-a716-446655440000", "reason_code": "BILLED_IN_ERROR", "quantity": 2}`), &req)
+	require.Nil(t, rerr)
+	require.Equal(t, uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), req.ClaimID)
+	require.Equal(t, int64(2), req.Quantity)
+
+	// Every unknown field and type mismatch is reported, in field order
+	var claim CreateClaimRequest
+	rerr = decodeJSONObject([]byte(`{"ndc": 123, "npi": "1234567890", "quantity": 1.5, "prise": 10, "extra": true}`), &claim)
+	require.NotNil(t, rerr)
+	require.Equal(t, http.StatusBadRequest, rerr.Status)
+	require.Equal(t, []fieldError{
+		{Field: "extra", Message: "unknown field"},
+		{Field: "ndc", Message: "must be a string"},
+		{Field: "prise", Message: "unknown field"},
+		{Field: "quantity", Message: "must be an integer"},
+	}, rerr.Fields)
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"trailing object", `{"ndc": "1"} {"ndc": "2"}`},
+		{"trailing garbage", `{"ndc": "1"} x`},
+		{"array", `[{"ndc": "1"}]`},
+		{"null", `null`},
+		{"malformed", `{"ndc": `},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var claim CreateClaimRequest
+			rerr := decodeJSONObject([]byte(tc.body), &claim)
+			require.NotNil(t, rerr)
+			require.Equal(t, http.StatusBadRequest, rerr.Status)
+			require.Empty(t, rerr.Fields)
+		})
+	}
+}
+
+func TestDecodeJSONBody(t *testing.T) {
+	request := func(contentType, body string) (*httptest.ResponseRecorder, *http.Request) {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/claims", strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		return httptest.NewRecorder(), r
+	}
+
+	w, r := request("application/json; charset=utf-8", `{"ndc": "1"}`)
+	var claim CreateClaimRequest
+	require.Nil(t, decodeJSONBody(w, r, &claim, 1024, false))
+	require.Equal(t, "1", claim.NDC)
+
+	w, r = request("text/plain", `{"ndc": "1"}`)
+	rerr := decodeJSONBody(w, r, &claim, 1024, false)
+	require.NotNil(t, rerr)
+	require.Equal(t, http.StatusUnsupportedMediaType, rerr.Status)
+
+	w, r = request("application/json", `{"ndc": "`+strings.Repeat("1", 2048)+`"}`)
+	rerr = decodeJSONBody(w, r, &claim, 1024, false)
+	require.NotNil(t, rerr)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rerr.Status)
+
+	// An empty body needs no content type, and is only accepted when optional
+	w, r = request("", "")
+	rerr = decodeJSONBody(w, r, &claim, 1024, false)
+	require.NotNil(t, rerr)
+	require.Equal(t, http.StatusBadRequest, rerr.Status)
+
+	w, r = request("", " ")
+	require.Nil(t, decodeJSONBody(w, r, &claim, 1024, true))
+}
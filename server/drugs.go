@@ -0,0 +1,213 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
+)
+
+// drugExample is shown in responses to malformed drug requests
+var drugExample = map[string]interface{}{
+	"ndc":             "00002323401",
+	"name":            "Amoxicillin",
+	"strength":        "500 mg",
+	"package_size":    100,
+	"unit_of_measure": "EA",
+}
+
+// listDrugs handles GET /api/v1/drugs
+func (server *Server) listDrugs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var arg sqlc.ListDrugsParams
+
+	if active := query.Get("active"); active != "" {
+		value, err := strconv.ParseBool(active)
+		if err != nil {
+			writeParameterError(w, r, "active", "must be true or false", "true")
+			return
+		}
+		arg.Active = pgtype.Bool{Bool: value, Valid: true}
+	}
+
+	if name := query.Get("name"); name != "" {
+		arg.Name = pgtype.Text{String: name, Valid: true}
+	}
+
+	limit, offset, verr := parsePagination(query)
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+	arg.RowLimit = limit
+	arg.RowOffset = offset
+
+	drugs, err := server.store.ListDrugs(r.Context(), arg)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list drugs")
+		return
+	}
+
+	data := make([]Drug, 0, len(drugs))
+	for _, drug := range drugs {
+		data = append(data, convertDBDrugToAPI(drug))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getDrug handles GET /api/v1/drugs/{ndc}
+func (server *Server) getDrug(w http.ResponseWriter, r *http.Request) {
+	drug, err := server.store.GetDrug(r.Context(), r.PathValue("ndc"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Drug not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to get drug")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    convertDBDrugToAPI(drug),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// createDrug handles POST /api/v1/drugs
+func (server *Server) createDrug(w http.ResponseWriter, r *http.Request) {
+	var req DrugRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: ndc (string), name (string), strength (string, optional), package_size (number, optional), unit_of_measure (string, optional), active (boolean, optional), quantity_limit (integer, optional)",
+		"example":         drugExample,
+	}) {
+		return
+	}
+
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+	ndc, _ := util.NormalizeNDC(req.NDC)
+
+	if _, err := server.store.GetDrug(r.Context(), ndc); err == nil {
+		writeError(w, r, http.StatusConflict, codeAlreadyExists, "Drug already exists", map[string]interface{}{
+			"ndc": ndc,
+		})
+		return
+	} else if !errors.Is(err, db.ErrNotFound) {
+		writeStoreError(w, r, err, "Failed to create drug")
+		return
+	}
+
+	drug, err := server.store.CreateDrug(r.Context(), sqlc.CreateDrugParams{
+		NDC:           ndc,
+		Name:          strings.TrimSpace(req.Name),
+		Strength:      strings.TrimSpace(req.Strength),
+		PackageSize:   req.PackageSize,
+		UnitOfMeasure: strings.ToUpper(strings.TrimSpace(req.UnitOfMeasure)),
+		Active:        req.Active == nil || *req.Active,
+		QuantityLimit: req.QuantityLimit,
+	})
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to create drug")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Drug created successfully",
+		Data:    convertDBDrugToAPI(drug),
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// updateDrug handles PUT /api/v1/drugs/{ndc}
+func (server *Server) updateDrug(w http.ResponseWriter, r *http.Request) {
+	var req DrugRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: name (string), strength (string, optional), package_size (number, optional), unit_of_measure (string, optional), active (boolean, optional), quantity_limit (integer, optional)",
+		"example":         drugExample,
+	}) {
+		return
+	}
+
+	// The NDC of a drug comes from the path and cannot change
+	if req.NDC == "" {
+		req.NDC = r.PathValue("ndc")
+	}
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	drug, err := server.store.UpdateDrug(r.Context(), sqlc.UpdateDrugParams{
+		NDC:           r.PathValue("ndc"),
+		Name:          strings.TrimSpace(req.Name),
+		Strength:      strings.TrimSpace(req.Strength),
+		PackageSize:   req.PackageSize,
+		UnitOfMeasure: strings.ToUpper(strings.TrimSpace(req.UnitOfMeasure)),
+		Active:        req.Active == nil || *req.Active,
+		QuantityLimit: req.QuantityLimit,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Drug not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to update drug")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Drug updated successfully",
+		Data:    convertDBDrugToAPI(drug),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// deleteDrug handles DELETE /api/v1/drugs/{ndc}
+func (server *Server) deleteDrug(w http.ResponseWriter, r *http.Request) {
+	deleted, err := server.store.DeleteDrug(r.Context(), r.PathValue("ndc"))
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to delete drug")
+		return
+	}
+
+	if deleted == 0 {
+		writeError(w, r, http.StatusNotFound, codeNotFound, "Drug not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// convertDBDrugToAPI converts a database drug to the API model
+func convertDBDrugToAPI(drug sqlc.Drug) Drug {
+	return Drug{
+		NDC:           drug.NDC,
+		Name:          drug.Name,
+		Strength:      drug.Strength,
+		PackageSize:   drug.PackageSize,
+		UnitOfMeasure: drug.UnitOfMeasure,
+		Active:        drug.Active,
+		QuantityLimit: drug.QuantityLimit,
+		Timestamp:     drug.Timestamp,
+	}
+}
@@ -1,14 +1,17 @@
 package server
 
 import (
-	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
 	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/util"
 )
 
 // healthCheck handles the health check endpoint
@@ -27,173 +30,490 @@ func (server *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 
 // createClaim handles POST /api/v1/claims
 func (server *Server) createClaim(w http.ResponseWriter, r *http.Request) {
+	server.submitClaim(w, r, apiV1, func(result db.CreateClaimTxResult) interface{} {
+		return claimSubmittedResponse(result)
+	})
+}
+
+// claimRenderer builds the response body for a newly created claim in one API version
+type claimRenderer func(result db.CreateClaimTxResult) interface{}
 
+// submitClaim validates, authorizes and stores a claim submission, then writes the created
+// claim with render. Idempotency keys are scoped to the version.
+func (server *Server) submitClaim(w http.ResponseWriter, r *http.Request, version apiVersion, render claimRenderer) {
 	var req CreateClaimRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON format in request body", map[string]interface{}{
-			"expected_format": "JSON object with fields: ndc (string), npi (string), quantity (integer), price (number)",
-			"example": map[string]interface{}{
-				"ndc":      "123456789",
-				"npi":      "9876543210",
-				"quantity": 30,
-				"price":    15.99,
-			},
-		})
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: ndc (string), npi (string), quantity (integer), price (number)",
+		"example": map[string]interface{}{
+			"ndc":      "00002323401",
+			"npi":      "9876543210",
+			"quantity": 30,
+			"price":    15.99,
+		},
+	}) {
+		claimsRejected.Inc(rejectInvalidJSON)
 		return
 	}
 
 	// Basic validation with specific error messages
-	if req.NDC == "" {
-		writeError(w, http.StatusBadRequest, "NDC (National Drug Code) is required", map[string]interface{}{
-			"field":       "ndc",
-			"type":        "string",
-			"description": "National Drug Code identifier",
-			"example":     "123456789",
-		})
-		return
-	}
-
-	if req.NPI == "" {
-		writeError(w, http.StatusBadRequest, "NPI (National Provider Identifier) is required", map[string]interface{}{
-			"field":       "npi",
-			"type":        "string",
-			"description": "National Provider Identifier",
-			"example":     "9876543210",
-		})
+	if verr := server.validateClaimRequest(req); verr != nil {
+		claimsRejected.Inc(verr.Reason)
+		writeValidationError(w, r, verr)
 		return
 	}
 
-	if req.Quantity <= 0 {
-		writeError(w, http.StatusBadRequest, "Quantity must be greater than 0", map[string]interface{}{
-			"field":     "quantity",
-			"type":      "integer",
-			"min_value": 1,
-			"example":   30,
-		})
+	// The API key must cover the submitting pharmacy
+	allowed, err := server.authorizePharmacy(r.Context(), req.NPI)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to authorize claim")
 		return
 	}
-
-	if req.Price < 0 {
-		writeError(w, http.StatusBadRequest, "Price cannot be negative", map[string]interface{}{
-			"field":     "price",
-			"type":      "number",
-			"min_value": 0,
-			"example":   15.99,
+	if !allowed {
+		claimsRejected.Inc(rejectForbiddenNPI)
+		writeError(w, r, http.StatusForbidden, codePharmacyNotAuthorized, "API key is not authorized to submit claims for this pharmacy", map[string]interface{}{
+			"field": "npi",
+			"npi":   req.NPI,
 		})
 		return
 	}
 
 	// Create claim in database
-	arg := sqlc.CreateClaimParams{
-		NDC:      req.NDC,
-		NPI:      req.NPI,
-		Quantity: int64(req.Quantity),
-		Price:    req.Price,
-	}
+	arg := server.claimTxParams(req)
 
-	claim, err := server.store.CreateClaim(r.Context(), arg)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to create claim")
-		return
+	var result db.CreateClaimTxResult
+
+	if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+		var ok bool
+		if result, ok = server.submitIdempotentClaim(w, r, req, arg, key, version, render); !ok {
+			return
+		}
+	} else {
+		var err error
+		result, err = server.store.CreateClaimTx(r.Context(), arg)
+		if err != nil {
+			writeCreateClaimError(w, r, err)
+			return
+		}
 	}
 
+	claimsSubmitted.Inc()
+	recordAdjudication(result.Decision)
+
 	// Log the claim submission event
+	claim := result.Claim
 	if err := server.logger.LogClaimSubmission(claim.ID, req.NDC, req.NPI, req.Quantity, req.Price); err != nil {
 		log.Printf("Warning: failed to log claim submission: %v", err)
 	}
 
-	response := map[string]interface{}{
-		"status":   "claim submitted",
-		"claim_id": claim.ID.String(),
+	w.Header().Set("Location", version.path("/claims/"+claim.ID.String()))
+	writeJSON(w, http.StatusCreated, render(result))
+}
+
+// validationError describes why a request failed field validation
+type validationError struct {
+	// Reason is recorded on the rejected claims metric
+	Reason string
+	// Code is the error code of the response; invalid_fields when empty
+	Code    errorCode
+	Message string
+	Fields  []fieldError
+	Details map[string]interface{}
+}
+
+// extensions returns the problem members describing the error
+func (verr *validationError) extensions() map[string]interface{} {
+	members := make(map[string]interface{}, len(verr.Details)+1)
+	for key, value := range verr.Details {
+		members[key] = value
+	}
+	if len(verr.Fields) > 0 {
+		members["errors"] = verr.Fields
+	}
+	return members
+}
+
+// validateClaimRequest checks the fields of a claim submission, then the strict formats enabled
+// in the configuration. The rejection reason recorded in metrics is that of the first invalid field.
+func (server *Server) validateClaimRequest(req CreateClaimRequest) *validationError {
+	verr := validateRequest(req)
+	if verr == nil {
+		verr = server.checkStrictClaimFormats(req)
+	}
+	if verr == nil {
+		return nil
+	}
+
+	fe := verr.Fields[0]
+	switch {
+	case fe.Field == "ndc" && fe.Rule == "required":
+		verr.Reason = rejectMissingNDC
+	case fe.Field == "ndc":
+		verr.Reason = rejectInvalidNDC
+	case fe.Field == "npi" && fe.Rule == "required":
+		verr.Reason = rejectMissingNPI
+	case fe.Field == "npi":
+		verr.Reason = rejectInvalidNPI
+	case fe.Field == "quantity":
+		verr.Reason = rejectInvalidQuantity
+	default:
+		verr.Reason = rejectNegativePrice
 	}
 
-	writeJSON(w, http.StatusCreated, response)
+	return verr
+}
+
+// checkStrictClaimFormats applies the optional formats of a claim that passed its tag rules:
+// with STRICT_NDC_VALIDATION the NDC must convert to the 11-digit billing format, and with
+// STRICT_NPI_VALIDATION the NPI must end in a valid check digit
+func (server *Server) checkStrictClaimFormats(req CreateClaimRequest) *validationError {
+	var errs []fieldError
+
+	if _, ok := util.NormalizeNDC(req.NDC); server.config.StrictNDCValidation && !ok {
+		errs = append(errs, fieldError{Field: "ndc", Rule: "billing_ndc", Message: formatRules["billing_ndc"].message})
+	}
+	if server.config.StrictNPIValidation && !util.ValidNPI(req.NPI) {
+		errs = append(errs, fieldError{Field: "npi", Rule: "npi_check_digit", Message: "must end in a valid check digit"})
+	}
+
+	return newValidationError(errs)
+}
+
+// claimTxParams builds the store parameters for a validated claim submission
+func (server *Server) claimTxParams(req CreateClaimRequest) db.CreateClaimTxParams {
+	// NDCs that can be are stored in the 11-digit billing format, the others as submitted
+	ndc := req.NDC
+	if normalized, ok := util.NormalizeNDC(req.NDC); ok {
+		ndc = normalized
+	}
+
+	return db.CreateClaimTxParams{
+		CreateClaimParams: sqlc.CreateClaimParams{
+			NDC:      ndc,
+			NPI:      req.NPI,
+			Quantity: int64(req.Quantity),
+			Price:    req.Price,
+		},
+		DuplicateWindow: server.config.DuplicateClaimWindow,
+		DuplicatePolicy: db.DuplicatePolicy(server.config.DuplicateClaimPolicy),
+		Adjudicator:     server.adjudicator,
+		StrictNDC:       server.config.StrictNDCValidation,
+		Pricer:          server.pricer,
+	}
+}
+
+// writeCreateClaimError writes the response for a failed claim insert
+func writeCreateClaimError(w http.ResponseWriter, r *http.Request, err error) {
+	verr, statusCode := createClaimError(err)
+
+	claimsRejected.Inc(verr.Reason)
+	if verr.Reason == rejectStoreError {
+		writeStoreError(w, r, err, verr.Message)
+		return
+	}
+	writeError(w, r, statusCode, verr.Code, verr.Message, verr.extensions())
+}
+
+// createClaimError maps a store error from a claim insert to a status code and message
+func createClaimError(err error) (*validationError, int) {
+	var duplicate *db.DuplicateClaimError
+	if errors.As(err, &duplicate) {
+		return &validationError{
+			Reason:  rejectDuplicate,
+			Code:    codeDuplicateClaim,
+			Message: "Claim duplicates a recent claim for the same pharmacy, drug and quantity",
+			Details: map[string]interface{}{
+				"duplicate_of": duplicate.ClaimID.String(),
+			},
+		}, http.StatusConflict
+	}
+
+	if errors.Is(err, db.ErrUnknownNDC) || errors.Is(err, db.ErrInactiveNDC) {
+		verr := &validationError{
+			Reason:  rejectUnknownNDC,
+			Code:    codeUnknownNDC,
+			Message: "NDC is not in the drug reference table",
+			Details: map[string]interface{}{
+				"field": "ndc",
+			},
+		}
+		if errors.Is(err, db.ErrInactiveNDC) {
+			verr.Reason = rejectInactiveNDC
+			verr.Code = codeInactiveNDC
+			verr.Message = "NDC is inactive in the drug reference table"
+		}
+		return verr, http.StatusUnprocessableEntity
+	}
+
+	if errors.Is(err, db.ErrPharmacyNotFound) {
+		return &validationError{
+			Reason:  rejectUnknownPharmacy,
+			Code:    codeUnknownPharmacy,
+			Message: "Pharmacy not found",
+			Details: map[string]interface{}{
+				"field":       "npi",
+				"reject_code": adjudication.NewReject(adjudication.RejectNonMatchedPharmacy),
+			},
+		}, http.StatusUnprocessableEntity
+	}
+
+	statusCode, code := storeErrorStatus(err)
+	return &validationError{
+		Reason:  rejectStoreError,
+		Code:    code,
+		Message: "Failed to create claim",
+	}, statusCode
 }
 
 // getClaim handles GET /api/v1/claims/{id}
 func (server *Server) getClaim(w http.ResponseWriter, r *http.Request) {
-	// Extract ID from URL path
-	pathParts := strings.Split(r.URL.Path, "/")
-	if len(pathParts) < 5 {
-		writeError(w, http.StatusBadRequest, "Invalid URL format. Expected: /api/v1/claims/{id}")
+	claim, reversals, ok := server.loadClaim(w, r)
+	if !ok {
 		return
 	}
 
-	id := pathParts[4]
-	if id == "" {
-		writeError(w, http.StatusBadRequest, "Claim ID cannot be empty")
-		return
+	// Net the claim against its reversals and adjustments
+	balance := db.NewClaimBalance(claim, reversals)
+
+	apiClaim := convertDBClaimToAPI(claim)
+	apiClaim.Balance = &balance
+	for _, reversal := range reversals {
+		apiClaim.Reversals = append(apiClaim.Reversals, convertDBReversalToAPI(reversal))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    apiClaim,
 	}
 
-	// Parse UUID
-	claimID, err := uuid.Parse(id)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// loadClaim fetches the claim named by the {id} path value with its reversals. Claims of
+// pharmacies outside the API key's scope are reported as missing. When ok is false the error
+// response has been written.
+func (server *Server) loadClaim(w http.ResponseWriter, r *http.Request) (claim sqlc.Claim, reversals []sqlc.Reversal, ok bool) {
+	claimID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid claim ID format. Must be a valid UUID", map[string]interface{}{
-			"field":   "claim_id",
-			"type":    "string (UUID)",
-			"format":  "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
-			"example": "550e8400-e29b-41d4-a716-446655440000",
-		})
-		return
+		writeParameterError(w, r, "claim_id", "must be a UUID", "550e8400-e29b-41d4-a716-446655440000")
+		return claim, nil, false
 	}
 
 	// Get claim from database
-	claim, err := server.store.GetClaim(r.Context(), claimID)
+	claim, err = server.store.GetClaim(r.Context(), claimID)
+	if errors.Is(err, db.ErrNotFound) {
+		writeError(w, r, http.StatusNotFound, codeNotFound, "Claim not found")
+		return claim, nil, false
+	}
 	if err != nil {
-		writeError(w, http.StatusNotFound, "Claim not found")
-		return
+		writeStoreError(w, r, err, "Failed to get claim")
+		return claim, nil, false
 	}
 
-	response := APIResponse{
-		Success: true,
-		Data:    convertDBClaimToAPI(claim),
+	allowed, err := server.authorizePharmacy(r.Context(), claim.NPI)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to authorize claim")
+		return claim, nil, false
+	}
+	if !allowed {
+		writeError(w, r, http.StatusNotFound, codeNotFound, "Claim not found")
+		return claim, nil, false
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	reversals, err = server.store.ListReversalsByClaimID(r.Context(), claimID)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to load claim reversals")
+		return claim, nil, false
+	}
+
+	return claim, reversals, true
 }
 
 // createReversal handles POST /api/v1/reversals
 func (server *Server) createReversal(w http.ResponseWriter, r *http.Request) {
 	var req CreateReversalRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON format in request body", map[string]interface{}{
-			"expected_format": "JSON object with field: claim_id (string, UUID format)",
-			"example": map[string]interface{}{
-				"claim_id": "550e8400-e29b-41d4-a716-446655440000",
-			},
-		})
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: claim_id (string, UUID format), reason_code (string), notes (string, optional), kind (string, optional), quantity (integer, optional), amount (number, optional), override_window (boolean, optional)",
+		"example": map[string]interface{}{
+			"claim_id":    "550e8400-e29b-41d4-a716-446655440000",
+			"reason_code": "BILLED_IN_ERROR",
+		},
+	}) {
+		return
+	}
+
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+	if req.OverrideWindow && !authorizeWindowOverride(r.Context()) {
+		writeWindowOverrideForbidden(w, r)
 		return
 	}
 
-	// Basic validation
-	if req.ClaimID == uuid.Nil {
-		writeError(w, http.StatusBadRequest, "Claim ID is required and must be a valid UUID", map[string]interface{}{
-			"field":   "claim_id",
-			"type":    "string (UUID)",
-			"format":  "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx",
-			"example": "550e8400-e29b-41d4-a716-446655440000",
+	// Claims of pharmacies outside the API key's scope are reported as missing
+	allowed, err := server.authorizeClaim(r.Context(), req.ClaimID)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to authorize reversal")
+		return
+	}
+	if !allowed {
+		writeError(w, r, http.StatusNotFound, codeNotFound, "Claim not found", map[string]interface{}{
+			"claim_id": req.ClaimID.String(),
 		})
 		return
 	}
 
 	// Create reversal in database
-	_, err := server.store.CreateReversal(r.Context(), req.ClaimID)
+	result, err := server.store.CreateReversalTx(r.Context(), db.CreateReversalTxParams{
+		CreateReversalParams: sqlc.CreateReversalParams{
+			ClaimID:    req.ClaimID,
+			Kind:       req.Kind,
+			Quantity:   req.Quantity,
+			Amount:     req.Amount,
+			ReasonCode: req.ReasonCode,
+			Notes:      req.Notes,
+			Actor:      actorFromContext(r.Context()),
+		},
+		Window:         server.reversalWindowPolicy(),
+		OverrideWindow: req.OverrideWindow,
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to create reversal")
+		statusCode, code, message, details := createReversalError(err)
+		details["claim_id"] = req.ClaimID.String()
+		if statusCode == http.StatusServiceUnavailable {
+			setStoreRetryAfter(w)
+		}
+		writeError(w, r, statusCode, code, message, details)
 		return
 	}
 
+	claimsReversed.Inc()
+
 	// Log the claim reversal event
-	if err := server.logger.LogClaimReversal(req.ClaimID); err != nil {
+	if err := server.logger.LogClaimReversal(reversalEvent(result)); err != nil {
 		log.Printf("Warning: failed to log claim reversal: %v", err)
 	}
 
-	response := map[string]interface{}{
-		"status":   "claim reversed",
-		"claim_id": req.ClaimID.String(),
+	writeJSON(w, http.StatusCreated, reversalCreatedResponse(result))
+}
+
+// createReversalError maps a store error from a reversal to a status code, error code, message
+// and details
+func createReversalError(err error) (int, errorCode, string, map[string]interface{}) {
+	details := map[string]interface{}{}
+
+	var exceeds *db.ReversalExceedsBalanceError
+	var expired *db.ReversalWindowExpiredError
+	switch {
+	case errors.Is(err, db.ErrClaimNotFound):
+		return http.StatusNotFound, codeNotFound, "Claim not found", details
+	case errors.Is(err, db.ErrClaimAlreadyReversed):
+		return http.StatusConflict, codeClaimAlreadyReversed, "Claim has already been reversed", details
+	case errors.Is(err, db.ErrClaimRejected):
+		return http.StatusConflict, codeClaimRejected, "Claim was rejected at adjudication and cannot be reversed", details
+	case errors.Is(err, db.ErrInvalidReasonCode):
+		return http.StatusUnprocessableEntity, codeInvalidReasonCode, "Reason code is unknown or inactive", details
+	case errors.Is(err, db.ErrEmptyAdjustment):
+		return http.StatusBadRequest, codeEmptyAdjustment, "Adjustment must add a positive quantity or amount", details
+	case errors.As(err, &expired):
+		details["deadline"] = formatTime(expired.Deadline)
+		details["window"] = expired.Window.String()
+		return http.StatusUnprocessableEntity, codeReversalWindowClosed, "Reversal window has closed for this claim", details
+	case errors.As(err, &exceeds):
+		details["balance"] = exceeds.Balance
+		return http.StatusUnprocessableEntity, codeReversalExceedsBalance, "Reversal exceeds the remaining claim balance", details
+	default:
+		statusCode, code := storeErrorStatus(err)
+		return statusCode, code, "Failed to create reversal", details
 	}
+}
 
-	writeJSON(w, http.StatusCreated, response)
+// writeWindowOverrideForbidden refuses a reversal whose caller may not override the window
+func writeWindowOverrideForbidden(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusForbidden, codeForbidden, "Only administrators may override the reversal window", map[string]interface{}{
+		"field": "override_window",
+	})
+}
+
+// reversalWindowPolicy builds the reversal window policy from the server configuration
+func (server *Server) reversalWindowPolicy() db.ReversalWindowPolicy {
+	return db.ReversalWindowPolicy{
+		Window:       server.config.ReversalWindow,
+		ChainWindows: server.config.ReversalChainWindows,
+	}
+}
+
+// listReversals handles GET /api/v1/reversals
+func (server *Server) listReversals(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	arg := sqlc.ListReversalsParams{
+		RowLimit:  defaultPageSize,
+		RowOffset: 0,
+	}
+
+	if reasonCode := query.Get("reason_code"); reasonCode != "" {
+		arg.ReasonCode = pgtype.Text{String: reasonCode, Valid: true}
+	}
+
+	if actor := query.Get("actor"); actor != "" {
+		arg.Actor = pgtype.Text{String: actor, Valid: true}
+	}
+
+	if claimID := query.Get("claim_id"); claimID != "" {
+		id, err := uuid.Parse(claimID)
+		if err != nil {
+			writeParameterError(w, r, "claim_id", "must be a UUID", "550e8400-e29b-41d4-a716-446655440000")
+			return
+		}
+		arg.ClaimID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	for _, bound := range []struct {
+		name   string
+		target *pgtype.Timestamptz
+	}{
+		{name: "since", target: &arg.Since},
+		{name: "until", target: &arg.Until},
+	} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+
+		t, err := parseTime(value)
+		if err != nil {
+			writeParameterError(w, r, bound.name, "must be an RFC3339 timestamp", "2024-01-01T00:00:00Z")
+			return
+		}
+		*bound.target = pgtype.Timestamptz{Time: t, Valid: true}
+	}
+
+	limit, offset, verr := parsePagination(query)
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+	arg.RowLimit = limit
+	arg.RowOffset = offset
+
+	reversals, err := server.store.ListReversals(r.Context(), arg)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list reversals")
+		return
+	}
+
+	data := make([]Reversal, 0, len(reversals))
+	for _, reversal := range reversals {
+		data = append(data, convertDBReversalToAPI(reversal))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pharmacy_claims_application/db"
+)
+
+const (
+	componentUp   = "up"
+	componentDown = "down"
+
+	readinessTimeout = 2 * time.Second
+)
+
+// dependencyCheck is a single named dependency probed by the readiness endpoint. The error of
+// a failed check is only logged; callers are shown the fixed message.
+type dependencyCheck struct {
+	name    string
+	message string
+	check   func(ctx context.Context) error
+}
+
+// livenessCheck handles GET /health/live
+func (server *Server) livenessCheck(w http.ResponseWriter, r *http.Request) {
+	response := APIResponse{
+		Success: true,
+		Status:  "ok",
+		Data: map[string]interface{}{
+			"timestamp": time.Now().UTC(),
+		},
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// readinessCheck handles GET /health/ready
+func (server *Server) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	checks := []dependencyCheck{
+		{name: "database", message: "database is unreachable", check: server.checkDatabase},
+		{name: "migrations", message: "database schema is not at the expected version", check: server.checkMigrations},
+		{name: "event_log", message: "event log is not writable", check: server.checkEventLog},
+		{name: "pharmacies", message: "pharmacy reference data is not loaded", check: server.checkPharmacies},
+	}
+
+	ready := true
+	components := make(map[string]ComponentStatus, len(checks))
+
+	for _, c := range checks {
+		start := time.Now()
+		err := c.check(ctx)
+
+		component := ComponentStatus{
+			Status:    componentUp,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000.0,
+		}
+		if err != nil {
+			ready = false
+			component.Status = componentDown
+			component.Error = c.message
+			log.Printf("Readiness check %s failed: %v", c.name, err)
+		}
+
+		components[c.name] = component
+	}
+
+	statusCode := http.StatusOK
+	response := APIResponse{
+		Success: ready,
+		Status:  "ready",
+		Data: map[string]interface{}{
+			"timestamp":  time.Now().UTC(),
+			"components": components,
+		},
+	}
+
+	if !ready {
+		statusCode = http.StatusServiceUnavailable
+		response.Status = "not ready"
+	}
+
+	writeJSON(w, statusCode, response)
+}
+
+// checkDatabase pings the connection pool
+func (server *Server) checkDatabase(ctx context.Context) error {
+	return server.store.Ping(ctx)
+}
+
+// checkMigrations verifies the schema is at the version this build expects
+func (server *Server) checkMigrations(ctx context.Context) error {
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+
+	if version != db.SchemaVersion {
+		return fmt.Errorf("schema version is %d, expected %d", version, db.SchemaVersion)
+	}
+
+	return nil
+}
+
+// checkEventLog verifies the event log directory is writable
+func (server *Server) checkEventLog(ctx context.Context) error {
+	if server.logger == nil {
+		return errors.New("event logger is not initialized")
+	}
+
+	return server.logger.CheckWritable()
+}
+
+// checkPharmacies verifies that pharmacy reference data has been seeded
+func (server *Server) checkPharmacies(ctx context.Context) error {
+	count, err := server.store.CountPharmacies(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count pharmacies: %w", err)
+	}
+
+	if count == 0 {
+		return errors.New("pharmacies table is empty")
+	}
+
+	return nil
+}
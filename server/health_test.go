@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pharmacy_claims_application/db"
+	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// readinessResponse is the body of GET /health/ready
+type readinessResponse struct {
+	Success bool   `json:"success"`
+	Status  string `json:"status"`
+	Data    struct {
+		Components map[string]ComponentStatus `json:"components"`
+	} `json:"data"`
+}
+
+func newHealthServer(t *testing.T, config util.Config, store db.Store) *Server {
+	eventLogger, err := logger.NewLogger(t.TempDir())
+	require.NoError(t, err)
+	return NewServer(config, store, eventLogger, nil, nil, nil)
+}
+
+func TestLivenessCheck(t *testing.T) {
+	// Liveness never touches the store
+	server := newHealthServer(t, util.Config{}, nil)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"status":"ok"`)
+}
+
+func TestReadinessCheck(t *testing.T) {
+	testCases := []struct {
+		name       string
+		modify     func(store *fakeStore)
+		statusCode int
+		down       string
+		error      string
+	}{
+		{
+			name:       "ready",
+			modify:     func(store *fakeStore) {},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "database down",
+			modify: func(store *fakeStore) {
+				store.ping = func(ctx context.Context) error { return errors.New("connection refused") }
+			},
+			statusCode: http.StatusServiceUnavailable,
+			down:       "database",
+			error:      "database is unreachable",
+		},
+		{
+			name: "schema behind",
+			modify: func(store *fakeStore) {
+				store.migrationVersion = func(ctx context.Context) (int64, bool, error) {
+					return db.SchemaVersion - 1, false, nil
+				}
+			},
+			statusCode: http.StatusServiceUnavailable,
+			down:       "migrations",
+			error:      "database schema is not at the expected version",
+		},
+		{
+			name: "dirty migration",
+			modify: func(store *fakeStore) {
+				store.migrationVersion = func(ctx context.Context) (int64, bool, error) {
+					return db.SchemaVersion, true, nil
+				}
+			},
+			statusCode: http.StatusServiceUnavailable,
+			down:       "migrations",
+			error:      "database schema is not at the expected version",
+		},
+		{
+			name: "no pharmacies",
+			modify: func(store *fakeStore) {
+				store.countPharmacies = func(ctx context.Context) (int64, error) { return 0, nil }
+			},
+			statusCode: http.StatusServiceUnavailable,
+			down:       "pharmacies",
+			error:      "pharmacy reference data is not loaded",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := healthyStore()
+			tc.modify(store)
+			server := newHealthServer(t, util.Config{}, store)
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+			require.Equal(t, tc.statusCode, w.Code)
+
+			var response readinessResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Equal(t, tc.down == "", response.Success)
+			require.Len(t, response.Data.Components, 4)
+
+			for name, component := range response.Data.Components {
+				if name == tc.down {
+					require.Equal(t, componentDown, component.Status)
+					require.Equal(t, tc.error, component.Error)
+					continue
+				}
+				require.Equal(t, componentUp, component.Status, name)
+			}
+		})
+	}
+}
+
+func TestHealthChecksSkipAuthentication(t *testing.T) {
+	server := newHealthServer(t, util.Config{AuthEnabled: true, AdminAPIKey: "admin-secret"}, healthyStore())
+	handler := server.authMiddleware(server.router)
+
+	for _, path := range []string{"/health", "/health/live", "/health/ready"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, path)
+	}
+
+	// API routes still require credentials
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reversal-reasons", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -2,10 +2,22 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
 	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
 )
 
 // writeJSON writes a JSON response with the given status code
@@ -20,45 +32,169 @@ func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	}
 }
 
-// writeError writes an error response
-func writeError(w http.ResponseWriter, statusCode int, message string, details ...map[string]interface{}) {
-	response := map[string]interface{}{
-		"status":  "error",
-		"message": message,
-		"code":    statusCode,
+// claimSubmittedResponse builds the response body returned for a newly created claim
+func claimSubmittedResponse(result db.CreateClaimTxResult) APIResponse {
+	status := "claim submitted"
+	if !result.Decision.Approved() {
+		status = "claim rejected"
 	}
 
-	// Add additional details if provided
-	if len(details) > 0 {
-		for key, value := range details[0] {
-			response[key] = value
+	data := map[string]interface{}{
+		"claim_id":     result.Claim.ID.String(),
+		"adjudication": result.Decision,
+	}
+
+	if result.Quote != nil {
+		pricing := map[string]interface{}{
+			"submitted_amount":      result.Claim.Price,
+			"allowed_amount":        result.Quote.AllowedAmount,
+			"unit_price":            result.Quote.UnitPrice,
+			"discount_percent":      result.Quote.DiscountPercent,
+			"dispensing_fee":        result.Quote.DispensingFee,
+			"price_exceeds_allowed": result.Claim.PriceExceedsAllowed,
+		}
+		if result.Quote.ContractID != "" {
+			pricing["contract_id"] = result.Quote.ContractID
 		}
+		data["pricing"] = pricing
 	}
 
-	writeJSON(w, statusCode, response)
+	if result.Claim.PossibleDuplicate {
+		data["possible_duplicate"] = true
+		data["duplicate_of"] = result.DuplicateOf.String()
+	}
+
+	return APIResponse{
+		Success: true,
+		Status:  status,
+		Data:    data,
+	}
 }
 
 // convertDBClaimToAPI converts a database claim to API format
 func convertDBClaimToAPI(dbClaim sqlc.Claim) Claim {
-	return Claim{
-		ID:        dbClaim.ID.String(),
-		NDC:       dbClaim.NDC,
-		Quantity:  int(dbClaim.Quantity),
-		NPI:       dbClaim.NPI,
-		Price:     dbClaim.Price,
-		Timestamp: dbClaim.Timestamp,
+	claim := Claim{
+		ID:                  dbClaim.ID.String(),
+		NDC:                 dbClaim.NDC,
+		Quantity:            int(dbClaim.Quantity),
+		NPI:                 dbClaim.NPI,
+		Price:               dbClaim.Price,
+		Timestamp:           dbClaim.Timestamp,
+		PossibleDuplicate:   dbClaim.PossibleDuplicate,
+		PriceExceedsAllowed: dbClaim.PriceExceedsAllowed,
+		Adjudication:        adjudication.DecisionFromClaim(dbClaim),
+	}
+
+	if dbClaim.AllowedAmount.Valid {
+		claim.AllowedAmount = &dbClaim.AllowedAmount.Float64
 	}
+
+	return claim
 }
 
 // convertDBReversalToAPI converts a database reversal to API format
 func convertDBReversalToAPI(dbReversal sqlc.Reversal) Reversal {
 	return Reversal{
-		ID:        dbReversal.ID.String(),
-		ClaimID:   dbReversal.ClaimID.String(),
-		Timestamp: dbReversal.Timestamp,
+		ID:         dbReversal.ID.String(),
+		ClaimID:    dbReversal.ClaimID.String(),
+		Kind:       dbReversal.Kind,
+		Quantity:   dbReversal.Quantity,
+		Amount:     dbReversal.Amount,
+		ReasonCode: dbReversal.ReasonCode,
+		Notes:      dbReversal.Notes,
+		Actor:      dbReversal.Actor,
+		Timestamp:  dbReversal.Timestamp,
+	}
+}
+
+// claimSubmission converts a stored claim submission to its event log representation
+func claimSubmission(claimID uuid.UUID, req CreateClaimRequest) logger.ClaimSubmission {
+	return logger.ClaimSubmission{
+		ClaimID:  claimID,
+		NDC:      req.NDC,
+		NPI:      req.NPI,
+		Quantity: req.Quantity,
+		Price:    req.Price,
+	}
+}
+
+// reversalEvent converts a recorded reversal to its event log representation
+func reversalEvent(result db.CreateReversalTxResult) logger.ReversalEvent {
+	dbReversal := result.Reversal
+	return logger.ReversalEvent{
+		ClaimID:        dbReversal.ClaimID,
+		ReversalID:     dbReversal.ID,
+		Kind:           dbReversal.Kind,
+		Quantity:       dbReversal.Quantity,
+		Amount:         dbReversal.Amount,
+		ReasonCode:     dbReversal.ReasonCode,
+		Notes:          dbReversal.Notes,
+		Actor:          dbReversal.Actor,
+		WindowOverride: result.WindowOverridden,
+	}
+}
+
+// reversalCreatedResponse builds the response body returned for a new reversal or adjustment
+func reversalCreatedResponse(result db.CreateReversalTxResult) APIResponse {
+	reversal := result.Reversal
+
+	status := "claim reversed"
+	switch {
+	case reversal.Kind == db.ReversalKindAdjustment:
+		status = "claim adjusted"
+	case !result.Balance.FullyReversed():
+		status = "claim partially reversed"
+	}
+
+	data := map[string]interface{}{
+		"claim_id":    reversal.ClaimID.String(),
+		"reversal_id": reversal.ID.String(),
+		"kind":        reversal.Kind,
+		"quantity":    reversal.Quantity,
+		"amount":      reversal.Amount,
+		"reason_code": reversal.ReasonCode,
+		"actor":       reversal.Actor,
+		"balance":     result.Balance,
+	}
+
+	if result.WindowOverridden {
+		data["window_override"] = true
+	}
+
+	return APIResponse{
+		Success: true,
+		Status:  status,
+		Data:    data,
 	}
 }
 
+// parsePagination reads the limit and offset query parameters
+func parsePagination(query url.Values) (int32, int32, *validationError) {
+	limit, offset := int64(defaultPageSize), int64(0)
+
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			verr := invalidField("limit", fmt.Sprintf("must be an integer between 1 and %d", maxPageSize))
+			verr.Code = codeInvalidParameter
+			return 0, 0, verr
+		}
+		limit = parsed
+	}
+
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed < 0 {
+			verr := invalidField("offset", "must be a non-negative integer")
+			verr.Code = codeInvalidParameter
+			return 0, 0, verr
+		}
+		offset = parsed
+	}
+
+	return int32(limit), int32(offset), nil
+}
+
 // parseTime parses a time string in RFC3339 format
 func parseTime(timeStr string) (time.Time, error) {
 	return time.Parse(time.RFC3339, timeStr)
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/db"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyPurgeInterval  = time.Hour
+	rejectIdempotencyKeyReuse = "idempotency_key_reused"
+)
+
+// submitIdempotentClaim creates a claim under an idempotency key of the API version, storing the
+// response built by render. When the key was already used, the stored response is written and ok is false; the
+// caller must not write again.
+func (server *Server) submitIdempotentClaim(w http.ResponseWriter, r *http.Request, req CreateClaimRequest, arg db.CreateClaimTxParams, key string, version apiVersion, render claimRenderer) (created db.CreateClaimTxResult, ok bool) {
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, r, http.StatusBadRequest, codeInvalidParameter, "Idempotency-Key header is too long", map[string]interface{}{
+			"field":      idempotencyKeyHeader,
+			"max_length": maxIdempotencyKeyLength,
+		})
+		return created, false
+	}
+
+	requestHash, err := hashRequest(req)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "Failed to create claim")
+		return created, false
+	}
+
+	result, err := server.store.CreateClaimIdempotentTx(r.Context(), db.CreateClaimIdempotentTxParams{
+		Claim:       arg,
+		Key:         version.idempotencyKey(idempotencyScope(r.Context()), key),
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(server.config.IdempotencyKeyTTL),
+		BuildResponse: func(result db.CreateClaimTxResult) (int32, []byte, error) {
+			body, err := json.Marshal(render(result))
+			return http.StatusCreated, body, err
+		},
+	})
+	if errors.Is(err, db.ErrIdempotencyKeyReused) {
+		claimsRejected.Inc(rejectIdempotencyKeyReuse)
+		writeError(w, r, http.StatusUnprocessableEntity, codeIdempotencyKeyReused, "Idempotency-Key was already used with a different request body", map[string]interface{}{
+			"field": idempotencyKeyHeader,
+		})
+		return created, false
+	}
+	if err != nil {
+		writeCreateClaimError(w, r, err)
+		return created, false
+	}
+
+	if result.Replayed {
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.Header().Set("Location", version.path("/claims/"+result.IdempotencyKey.ClaimID.String()))
+		writeJSON(w, int(result.IdempotencyKey.ResponseStatus), json.RawMessage(result.IdempotencyKey.ResponseBody))
+		return created, false
+	}
+
+	return result.CreateClaimTxResult, true
+}
+
+// idempotencyScope identifies the caller that owns an idempotency key; it is empty when
+// authentication is disabled
+func idempotencyScope(ctx context.Context) string {
+	p, ok := principalFromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case p.KeyID != uuid.Nil:
+		return "key:" + p.KeyID.String()
+	case p.Permissions != nil:
+		return "token:" + p.Name
+	case p.Admin:
+		return adminActor
+	default:
+		return "cert:" + p.Name
+	}
+}
+
+// hashRequest returns a hex SHA-256 of the decoded request, so formatting differences
+// between retries do not count as a different body
+func hashRequest(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// purgeIdempotencyKeys periodically removes expired idempotency keys
+func (server *Server) purgeIdempotencyKeys(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		deleted, err := server.store.DeleteExpiredIdempotencyKeys(ctx)
+		cancel()
+
+		if err != nil {
+			log.Printf("Warning: failed to purge expired idempotency keys: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("Purged %d expired idempotency keys", deleted)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+const idempotentClaimBody = `{"ndc": "00002323401", "npi": "1111111111", "quantity": 30, "price": 15.99}`
+
+// idempotentStore adds to authStore an in-memory idempotency key table that behaves like
+// CreateClaimIdempotentTx, returning the stored keys by their scoped name
+func idempotentStore() (*fakeStore, map[string]sqlc.IdempotencyKey) {
+	store, _ := authStore()
+	keys := make(map[string]sqlc.IdempotencyKey)
+
+	store.createClaimIdempotentTx = func(ctx context.Context, arg db.CreateClaimIdempotentTxParams) (db.CreateClaimIdempotentTxResult, error) {
+		if existing, ok := keys[arg.Key]; ok && existing.ExpiresAt.After(time.Now()) {
+			if existing.RequestHash != arg.RequestHash {
+				return db.CreateClaimIdempotentTxResult{}, db.ErrIdempotencyKeyReused
+			}
+			return db.CreateClaimIdempotentTxResult{IdempotencyKey: existing, Replayed: true}, nil
+		}
+
+		claim := arg.Claim.CreateClaimParams
+		result := db.CreateClaimTxResult{Claim: sqlc.Claim{ID: uuid.New(), NDC: claim.NDC, NPI: claim.NPI, Quantity: claim.Quantity, Price: claim.Price}}
+		status, body, err := arg.BuildResponse(result)
+		if err != nil {
+			return db.CreateClaimIdempotentTxResult{}, err
+		}
+
+		keys[arg.Key] = sqlc.IdempotencyKey{
+			Key:            arg.Key,
+			RequestHash:    arg.RequestHash,
+			ClaimID:        result.Claim.ID,
+			ResponseStatus: status,
+			ResponseBody:   body,
+			ExpiresAt:      arg.ExpiresAt,
+		}
+		return db.CreateClaimIdempotentTxResult{CreateClaimTxResult: result, IdempotencyKey: keys[arg.Key]}, nil
+	}
+
+	return store, keys
+}
+
+func newIdempotentServer(t *testing.T, store *fakeStore) http.Handler {
+	eventLogger, err := logger.NewLogger(t.TempDir())
+	require.NoError(t, err)
+
+	config := util.Config{
+		AuthEnabled:         true,
+		AdminAPIKey:         testAdminKey,
+		MaxRequestBodyBytes: 1 << 10,
+		IdempotencyKeyTTL:   time.Hour,
+	}
+	return NewServer(config, store, eventLogger, nil, nil, nil).handler()
+}
+
+func submitWithKey(handler http.Handler, path, apiKey, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(apiKeyHeader, apiKey)
+	r.Header.Set(idempotencyKeyHeader, key)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+// submittedClaimID returns the claim_id of a claim submission response
+func submittedClaimID(t *testing.T, w *httptest.ResponseRecorder) string {
+	var response struct {
+		Data struct {
+			ClaimID string `json:"claim_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Data.ClaimID)
+	return response.Data.ClaimID
+}
+
+func TestIdempotentClaimReplay(t *testing.T) {
+	store, keys := idempotentStore()
+	handler := newIdempotentServer(t, store)
+
+	first := submitWithKey(handler, "/api/v1/claims", testNPIKey, "retry-1", idempotentClaimBody)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+	require.Empty(t, first.Header().Get(idempotentReplayedHeader))
+	claimID := submittedClaimID(t, first)
+	require.Equal(t, "/api/v1/claims/"+claimID, first.Header().Get("Location"))
+
+	require.Len(t, keys, 1)
+	for _, key := range keys {
+		require.WithinDuration(t, time.Now().Add(time.Hour), key.ExpiresAt, time.Minute)
+	}
+
+	// Formatting differences do not make a retry a different request
+	replay := submitWithKey(handler, "/api/v1/claims", testNPIKey, "retry-1", strings.ReplaceAll(idempotentClaimBody, " ", ""))
+	require.Equal(t, http.StatusCreated, replay.Code, replay.Body.String())
+	require.Equal(t, "true", replay.Header().Get(idempotentReplayedHeader))
+	require.Equal(t, first.Header().Get("Location"), replay.Header().Get("Location"))
+	require.Equal(t, claimID, submittedClaimID(t, replay))
+	require.JSONEq(t, first.Body.String(), replay.Body.String())
+}
+
+func TestIdempotentClaimKeyReused(t *testing.T) {
+	store, _ := idempotentStore()
+	handler := newIdempotentServer(t, store)
+
+	w := submitWithKey(handler, "/api/v1/claims", testNPIKey, "retry-1", idempotentClaimBody)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = submitWithKey(handler, "/api/v1/claims", testNPIKey, "retry-1", strings.Replace(idempotentClaimBody, "30", "60", 1))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	require.Equal(t, codeIdempotencyKeyReused, decodeProblem(t, w).Code)
+}
+
+func TestIdempotentClaimExpiredKey(t *testing.T) {
+	store, keys := idempotentStore()
+	handler := newIdempotentServer(t, store)
+
+	first := submitWithKey(handler, "/api/v1/claims", testNPIKey, "retry-1", idempotentClaimBody)
+	require.Equal(t, http.StatusCreated, first.Code, first.Body.String())
+
+	for name, key := range keys {
+		key.ExpiresAt = time.Now().Add(-time.Minute)
+		keys[name] = key
+	}
+
+	second := submitWithKey(handler, "/api/v1/claims", testNPIKey, "retry-1", strings.Replace(idempotentClaimBody, "30", "60", 1))
+	require.Equal(t, http.StatusCreated, second.Code, second.Body.String())
+	require.Empty(t, second.Header().Get(idempotentReplayedHeader))
+	require.NotEqual(t, submittedClaimID(t, first), submittedClaimID(t, second))
+}
+
+func TestIdempotencyKeysScopedByPrincipal(t *testing.T) {
+	store, keys := idempotentStore()
+	handler := newIdempotentServer(t, store)
+
+	// Two API keys, and both versions, may use the same key for different requests
+	requests := []struct {
+		path   string
+		apiKey string
+		body   string
+	}{
+		{path: "/api/v1/claims", apiKey: testNPIKey, body: idempotentClaimBody},
+		{path: "/api/v1/claims", apiKey: testChainKey, body: strings.Replace(idempotentClaimBody, "30", "60", 1)},
+		{path: "/api/v2/claims", apiKey: testChainKey, body: strings.Replace(idempotentClaimBody, "30", "90", 1)},
+	}
+	for _, request := range requests {
+		w := submitWithKey(handler, request.path, request.apiKey, "shared-key", request.body)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.Empty(t, w.Header().Get(idempotentReplayedHeader))
+	}
+	require.Len(t, keys, len(requests))
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/metrics"
+)
+
+var (
+	claimsSubmitted = metrics.NewCounterVec(
+		"pharmacy_claims_submitted_total",
+		"Number of claims accepted and stored.",
+	)
+	claimsReversed = metrics.NewCounterVec(
+		"pharmacy_claims_reversed_total",
+		"Number of claims reversed.",
+	)
+	claimsRejected = metrics.NewCounterVec(
+		"pharmacy_claims_rejected_total",
+		"Number of claim submissions rejected, by reason.",
+		"reason",
+	)
+	claimsAdjudicated = metrics.NewCounterVec(
+		"pharmacy_claims_adjudicated_total",
+		"Number of stored claims, by adjudication status.",
+		"status",
+	)
+	claimRejectCodes = metrics.NewCounterVec(
+		"pharmacy_claim_reject_codes_total",
+		"Number of NCPDP reject codes returned by adjudication, by code.",
+		"code",
+	)
+	rateLimitRequests = metrics.NewCounterVec(
+		"pharmacy_rate_limit_requests_total",
+		"Number of API requests checked against a rate limit, by route and outcome.",
+		"route", "outcome",
+	)
+	requestDuration = metrics.NewHistogramVec(
+		"pharmacy_http_request_duration_seconds",
+		"Latency of HTTP handlers, by route and status code.",
+		metrics.DefaultBuckets,
+		"method", "route", "code",
+	)
+)
+
+func init() {
+	metrics.MustRegister(claimsSubmitted, claimsReversed, claimsRejected, claimsAdjudicated, claimRejectCodes, rateLimitRequests, requestDuration)
+}
+
+// Reasons recorded on pharmacy_claims_rejected_total
+const (
+	rejectInvalidJSON     = "invalid_json"
+	rejectMissingNDC      = "missing_ndc"
+	rejectMissingNPI      = "missing_npi"
+	rejectInvalidNDC      = "invalid_ndc"
+	rejectInvalidNPI      = "invalid_npi"
+	rejectInvalidQuantity = "invalid_quantity"
+	rejectNegativePrice   = "negative_price"
+	rejectDuplicate       = "duplicate"
+	rejectUnknownPharmacy = "unknown_pharmacy"
+	rejectUnknownNDC      = "unknown_ndc"
+	rejectInactiveNDC     = "inactive_ndc"
+	rejectStoreError      = "store_error"
+	rejectInvalidRecord   = "invalid_record"
+	rejectForbiddenNPI    = "forbidden_npi"
+)
+
+// recordAdjudication counts the decision of a stored claim
+func recordAdjudication(decision adjudication.Decision) {
+	claimsAdjudicated.Inc(decision.Status)
+	for _, reject := range decision.Rejects {
+		claimRejectCodes.Inc(reject.Code)
+	}
+}
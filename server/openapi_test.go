@@ -0,0 +1,195 @@
+package server
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// openAPIDocument is the part of the OpenAPI document checked against the code
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPISchema struct {
+	Properties map[string]json.RawMessage `json:"properties"`
+	Required   []string                   `json:"required"`
+	Enum       []string                   `json:"enum"`
+}
+
+// openAPITypes are the Go types described by the component schemas of the same name
+var openAPITypes = map[string]reflect.Type{
+	"APIResponse":             reflect.TypeOf(APIResponse{}),
+	"Problem":                 reflect.TypeOf(Problem{}),
+	"FieldError":              reflect.TypeOf(fieldError{}),
+	"Reject":                  reflect.TypeOf(adjudication.Reject{}),
+	"Decision":                reflect.TypeOf(adjudication.Decision{}),
+	"ClaimBalance":            reflect.TypeOf(db.ClaimBalance{}),
+	"Claim":                   reflect.TypeOf(Claim{}),
+	"Reversal":                reflect.TypeOf(Reversal{}),
+	"ClaimV2":                 reflect.TypeOf(ClaimV2{}),
+	"ClaimPharmacy":           reflect.TypeOf(ClaimPharmacy{}),
+	"ClaimBalanceV2":          reflect.TypeOf(ClaimBalanceV2{}),
+	"ReversalV2":              reflect.TypeOf(ReversalV2{}),
+	"CreateClaimRequest":      reflect.TypeOf(CreateClaimRequest{}),
+	"CreateReversalRequest":   reflect.TypeOf(CreateReversalRequest{}),
+	"BatchReversalRequest":    reflect.TypeOf(BatchReversalRequest{}),
+	"BatchItemResult":         reflect.TypeOf(BatchItemResult{}),
+	"ReversalReason":          reflect.TypeOf(ReversalReason{}),
+	"ReversalReasonRequest":   reflect.TypeOf(ReversalReasonRequest{}),
+	"Pharmacy":                reflect.TypeOf(Pharmacy{}),
+	"UpdatePharmacyRequest":   reflect.TypeOf(UpdatePharmacyRequest{}),
+	"Drug":                    reflect.TypeOf(Drug{}),
+	"DrugRequest":             reflect.TypeOf(DrugRequest{}),
+	"ReferencePrice":          reflect.TypeOf(ReferencePrice{}),
+	"ReferencePriceRequest":   reflect.TypeOf(ReferencePriceRequest{}),
+	"Contract":                reflect.TypeOf(Contract{}),
+	"ContractRequest":         reflect.TypeOf(ContractRequest{}),
+	"ChainPricingSummary":     reflect.TypeOf(ChainPricingSummary{}),
+	"SettlementCycle":         reflect.TypeOf(SettlementCycle{}),
+	"SettlementBatch":         reflect.TypeOf(SettlementBatch{}),
+	"SettlementItem":          reflect.TypeOf(SettlementItem{}),
+	"CreateSettlementRequest": reflect.TypeOf(CreateSettlementRequest{}),
+	"APIKey":                  reflect.TypeOf(APIKey{}),
+	"APIKeyRequest":           reflect.TypeOf(APIKeyRequest{}),
+	"ComponentStatus":         reflect.TypeOf(ComponentStatus{}),
+}
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(openAPISpec, &doc))
+	require.Equal(t, "3.1.0", doc.OpenAPI)
+	return doc
+}
+
+// registeredRoutes reads the patterns passed to the router in setupRoutes
+func registeredRoutes(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "server.go", nil, 0)
+	require.NoError(t, err)
+
+	var routes []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (selector.Sel.Name != "HandleFunc" && selector.Sel.Name != "Handle") {
+			return true
+		}
+		literal, ok := call.Args[0].(*ast.BasicLit)
+		require.True(t, ok, "route patterns must be string literals")
+
+		pattern, err := strconv.Unquote(literal.Value)
+		require.NoError(t, err)
+		routes = append(routes, pattern)
+		return true
+	})
+
+	sort.Strings(routes)
+	return routes
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	var documented []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(documented)
+
+	routes := registeredRoutes(t)
+	require.NotEmpty(t, routes)
+	require.Equal(t, routes, documented, "every route in setupRoutes must be documented in openapi.json, and nothing else")
+
+	// The documented paths are the ones the router matches
+	server := NewServer(util.Config{}, nil, nil, nil, nil, nil)
+	for _, route := range documented {
+		method, path, _ := strings.Cut(route, " ")
+		path = strings.NewReplacer("{id}", "550e8400-e29b-41d4-a716-446655440000", "{ndc}", "00002323401", "{npi}", "9876543210", "{code}", "DUPLICATE").Replace(path)
+
+		_, pattern := server.router.Handler(httptest.NewRequest(method, path, nil))
+		require.Equal(t, route, pattern)
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := loadOpenAPI(t)
+
+	for name, typ := range openAPITypes {
+		schema, ok := doc.Components.Schemas[name]
+		require.True(t, ok, "schema %s is missing", name)
+
+		var fields, properties []string
+		for field := range jsonFields(typ) {
+			fields = append(fields, field)
+		}
+		for property := range schema.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(fields)
+		sort.Strings(properties)
+		require.Equal(t, fields, properties, "properties of schema %s", name)
+
+		// Request fields the validator requires are required in the schema
+		if strings.HasSuffix(name, "Request") {
+			var required []string
+			for field, index := range jsonFields(typ) {
+				tag := typ.Field(index).Tag.Get("validate")
+				if slices.Contains(strings.Split(tag, ","), "required") {
+					required = append(required, field)
+				}
+			}
+			sort.Strings(required)
+			documented := slices.Clone(schema.Required)
+			sort.Strings(documented)
+			require.Equal(t, required, documented, "required fields of schema %s", name)
+		}
+	}
+
+	// Every error code is documented
+	var codes []string
+	for code := range errorTitles {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	documented := slices.Clone(doc.Components.Schemas["ErrorCode"].Enum)
+	sort.Strings(documented)
+	require.Equal(t, codes, documented)
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	server := NewServer(util.Config{}, nil, nil, nil, nil, nil)
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, string(openAPISpec), w.Body.String())
+
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `fetch("/openapi.json")`)
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// updatePharmacy handles PATCH /api/v1/pharmacies/{npi}
+func (server *Server) updatePharmacy(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePharmacyRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: active (boolean)",
+		"example":         map[string]interface{}{"active": false},
+	}) {
+		return
+	}
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	pharmacy, err := server.store.UpdatePharmacyActive(r.Context(), sqlc.UpdatePharmacyActiveParams{
+		NPI:    r.PathValue("npi"),
+		Active: *req.Active,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Pharmacy not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to update pharmacy")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Pharmacy updated successfully",
+		Data: Pharmacy{
+			NPI:       pharmacy.NPI,
+			Chain:     pharmacy.Chain,
+			Active:    pharmacy.Active,
+			Timestamp: pharmacy.Timestamp,
+		},
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	// problemTypePrefix starts the type URI of every problem; the error code completes it
+	problemTypePrefix = "urn:pharmacy-claims:problem:"
+)
+
+// errorCode is a stable, machine-readable identifier of a kind of error. Clients should act on
+// the code rather than on the detail message, which may change.
+type errorCode string
+
+const (
+	codeInvalidJSON          errorCode = "invalid_json"
+	codeInvalidFields        errorCode = "invalid_fields"
+	codeInvalidParameter     errorCode = "invalid_parameter"
+	codeBodyTooLarge         errorCode = "body_too_large"
+	codeUnsupportedMediaType errorCode = "unsupported_media_type"
+	codeBatchTooLarge        errorCode = "batch_too_large"
+	codeEmptyBatch           errorCode = "empty_batch"
+	codeInvalidClaimFile     errorCode = "invalid_claim_file"
+
+	codeUnauthenticated        errorCode = "unauthenticated"
+	codeInvalidCredentials     errorCode = "invalid_credentials"
+	codeForbidden              errorCode = "forbidden"
+	codePharmacyNotAuthorized  errorCode = "pharmacy_not_authorized"
+	codeRateLimited            errorCode = "rate_limited"
+	codeIdempotencyKeyReused   errorCode = "idempotency_key_reused"
+	codeNotFound               errorCode = "not_found"
+	codeAlreadyExists          errorCode = "already_exists"
+	codeAlreadyRevoked         errorCode = "already_revoked"
+	codeContractOverlap        errorCode = "contract_overlap"
+	codeBatchRolledBack        errorCode = "batch_rolled_back"
+	codeDuplicateClaim         errorCode = "duplicate_claim"
+	codeUnknownNDC             errorCode = "unknown_ndc"
+	codeInactiveNDC            errorCode = "inactive_ndc"
+	codeUnknownPharmacy        errorCode = "unknown_pharmacy"
+	codeClaimAlreadyReversed   errorCode = "claim_already_reversed"
+	codeClaimRejected          errorCode = "claim_rejected"
+	codeInvalidReasonCode      errorCode = "invalid_reason_code"
+	codeEmptyAdjustment        errorCode = "empty_adjustment"
+	codeReversalWindowClosed   errorCode = "reversal_window_closed"
+	codeReversalExceedsBalance errorCode = "reversal_exceeds_balance"
+	codeInvalidReference       errorCode = "invalid_reference"
+
+	codeInternal            errorCode = "internal_error"
+	codeTransactionConflict errorCode = "transaction_conflict"
+	codeServiceUnavailable  errorCode = "service_unavailable"
+)
+
+// errorTitles are the fixed, human-readable summaries of each error code
+var errorTitles = map[errorCode]string{
+	codeInvalidJSON:          "Request body is not valid JSON",
+	codeInvalidFields:        "Request has invalid fields",
+	codeInvalidParameter:     "Request has an invalid parameter",
+	codeBodyTooLarge:         "Request body is too large",
+	codeUnsupportedMediaType: "Unsupported media type",
+	codeBatchTooLarge:        "Batch is too large",
+	codeEmptyBatch:           "Batch is empty",
+	codeInvalidClaimFile:     "Claim file is invalid",
+
+	codeUnauthenticated:        "Authentication required",
+	codeInvalidCredentials:     "Invalid credentials",
+	codeForbidden:              "Forbidden",
+	codePharmacyNotAuthorized:  "Pharmacy not authorized",
+	codeRateLimited:            "Rate limit exceeded",
+	codeIdempotencyKeyReused:   "Idempotency key reused",
+	codeNotFound:               "Resource not found",
+	codeAlreadyExists:          "Resource already exists",
+	codeAlreadyRevoked:         "Already revoked",
+	codeContractOverlap:        "Contract dates overlap",
+	codeBatchRolledBack:        "Batch rolled back",
+	codeDuplicateClaim:         "Duplicate claim",
+	codeUnknownNDC:             "Unknown NDC",
+	codeInactiveNDC:            "Inactive NDC",
+	codeUnknownPharmacy:        "Unknown pharmacy",
+	codeClaimAlreadyReversed:   "Claim already reversed",
+	codeClaimRejected:          "Claim was rejected",
+	codeInvalidReasonCode:      "Invalid reason code",
+	codeEmptyAdjustment:        "Empty adjustment",
+	codeReversalWindowClosed:   "Reversal window closed",
+	codeReversalExceedsBalance: "Reversal exceeds balance",
+	codeInvalidReference:       "Referenced resource does not exist",
+
+	codeInternal:            "Internal server error",
+	codeTransactionConflict: "Transaction conflict",
+	codeServiceUnavailable:  "Service unavailable",
+}
+
+// newProblem builds the problem for an error code. Extensions are written alongside the
+// standard members; a "errors" extension holding field errors becomes the Errors member.
+func newProblem(statusCode int, code errorCode, detail string, extensions map[string]interface{}) *Problem {
+	problem := &Problem{
+		Type:   problemTypePrefix + string(code),
+		Title:  errorTitles[code],
+		Status: statusCode,
+		Detail: detail,
+		Code:   code,
+	}
+
+	for key, value := range extensions {
+		if fields, ok := value.([]fieldError); ok && key == "errors" {
+			problem.Errors = fields
+			continue
+		}
+		if problem.Extensions == nil {
+			problem.Extensions = make(map[string]interface{}, len(extensions))
+		}
+		problem.Extensions[key] = value
+	}
+
+	return problem
+}
+
+// MarshalJSON writes the standard members of the problem and its extensions as one object
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+7)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	members["code"] = p.Code
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	if len(p.Errors) > 0 {
+		members["errors"] = p.Errors
+	}
+
+	return json.Marshal(members)
+}
+
+// writeError writes an RFC 7807 problem response for the request. detail explains this
+// occurrence of the error; extensions add members such as the fields involved.
+func writeError(w http.ResponseWriter, r *http.Request, statusCode int, code errorCode, detail string, extensions ...map[string]interface{}) {
+	var members map[string]interface{}
+	if len(extensions) > 0 {
+		members = extensions[0]
+	}
+
+	problem := newProblem(statusCode, code, detail, members)
+	problem.Instance = r.URL.RequestURI()
+	writeProblem(w, problem)
+}
+
+// writeValidationError writes the 400 response for request fields that failed validation
+func writeValidationError(w http.ResponseWriter, r *http.Request, verr *validationError) {
+	code := verr.Code
+	if code == "" {
+		code = codeInvalidFields
+	}
+
+	writeError(w, r, http.StatusBadRequest, code, verr.Message, verr.extensions())
+}
+
+// writeParameterError writes the 400 response for an invalid path or query parameter
+func writeParameterError(w http.ResponseWriter, r *http.Request, name, message, example string) {
+	writeError(w, r, http.StatusBadRequest, codeInvalidParameter, name+" "+message, map[string]interface{}{
+		"errors":  []fieldError{{Field: name, Message: message}},
+		"example": example,
+	})
+}
+
+// writeProblem writes a problem document with its status code
+func writeProblem(w http.ResponseWriter, problem *Problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
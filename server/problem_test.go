@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/claims/42?verbose=1", nil)
+	w := httptest.NewRecorder()
+
+	writeError(w, r, http.StatusNotFound, codeNotFound, "Claim not found", map[string]interface{}{
+		"claim_id": "42",
+	})
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Equal(t, map[string]interface{}{
+		"type":     "urn:pharmacy-claims:problem:not_found",
+		"title":    "Resource not found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "Claim not found",
+		"instance": "/api/v1/claims/42?verbose=1",
+		"code":     "not_found",
+		"claim_id": "42",
+	}, body)
+}
+
+func TestWriteValidationError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/claims", nil)
+	w := httptest.NewRecorder()
+
+	writeValidationError(w, r, validateRequest(CreateClaimRequest{NDC: "00002323401", Quantity: 1}))
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	var problem struct {
+		Code   string       `json:"code"`
+		Status int          `json:"status"`
+		Errors []fieldError `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Equal(t, string(codeInvalidFields), problem.Code)
+	require.Equal(t, http.StatusBadRequest, problem.Status)
+	require.Equal(t, []fieldError{{Field: "npi", Rule: "required", Message: "is required"}}, problem.Errors)
+}
+
+// TestErrorTitles checks that every error code has a title
+func TestErrorTitles(t *testing.T) {
+	codes := []errorCode{
+		codeInvalidJSON, codeInvalidFields, codeInvalidParameter, codeBodyTooLarge,
+		codeUnsupportedMediaType, codeBatchTooLarge, codeEmptyBatch, codeInvalidClaimFile,
+		codeUnauthenticated, codeInvalidCredentials, codeForbidden, codePharmacyNotAuthorized,
+		codeRateLimited, codeIdempotencyKeyReused, codeNotFound, codeAlreadyExists,
+		codeAlreadyRevoked, codeContractOverlap, codeBatchRolledBack, codeDuplicateClaim,
+		codeUnknownNDC, codeInactiveNDC, codeUnknownPharmacy, codeClaimAlreadyReversed,
+		codeClaimRejected, codeInvalidReasonCode, codeEmptyAdjustment, codeReversalWindowClosed,
+		codeReversalExceedsBalance, codeInvalidReference, codeInternal, codeTransactionConflict,
+		codeServiceUnavailable,
+	}
+
+	require.Len(t, errorTitles, len(codes))
+	for _, code := range codes {
+		require.NotEmpty(t, errorTitles[code], code)
+	}
+}
+
+// decodeProblem reads the problem document of an error response
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	require.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	require.Equal(t, w.Code, problem.Status)
+	return problem
+}
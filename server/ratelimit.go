@@ -0,0 +1,177 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/ratelimit"
+	"github.com/pharmacy_claims_application/util"
+)
+
+const (
+	rateLimitAllowed     = "allowed"
+	rateLimitLimited     = "limited"
+	rateLimitLimitedByIP = "limited_by_ip"
+
+	rateLimitPurgeInterval = time.Minute
+)
+
+// rateLimits holds the token buckets of the routes with their own limit, of the limit the other
+// routes share, and of the per-IP limit checked before authentication
+type rateLimits struct {
+	routes   map[string]*ratelimit.Limiter
+	fallback *ratelimit.Limiter
+	perIP    *ratelimit.Limiter
+}
+
+// newRateLimits builds the limiters configured in RATE_LIMIT_DEFAULT, RATE_LIMIT_ROUTES and
+// RATE_LIMIT_PER_IP
+func newRateLimits(config util.Config) *rateLimits {
+	limits := &rateLimits{routes: make(map[string]*ratelimit.Limiter)}
+
+	for route, limit := range config.RouteRateLimits {
+		limits.routes[route] = ratelimit.NewLimiter(ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst})
+	}
+	if limit := config.DefaultRateLimit; limit != nil {
+		limits.fallback = ratelimit.NewLimiter(ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst})
+	}
+	if limit := config.PerIPRateLimit; limit != nil {
+		limits.perIP = ratelimit.NewLimiter(ratelimit.Limit{Rate: limit.Rate, Burst: limit.Burst})
+	}
+
+	return limits
+}
+
+// limiterFor returns the limiter of a route pattern, or nil if the route is unlimited
+func (l *rateLimits) limiterFor(pattern string) *ratelimit.Limiter {
+	if limiter, ok := l.routes[pattern]; ok {
+		return limiter
+	}
+	return l.fallback
+}
+
+// ipRateLimitMiddleware refuses API requests over the per-IP limit with 429. It runs before
+// authentication, so requests with invalid credentials are limited too and never reach the
+// API key lookup once their address is over the limit.
+func (server *Server) ipRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter := server.rateLimits.perIP
+		if !server.config.RateLimitEnabled || limiter == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if result := limiter.Allow(clientIPKey(r)); !result.Allowed {
+			rateLimitRequests.Inc(server.routeLabel(r), rateLimitLimitedByIP)
+			writeRateLimitHeaders(w, result)
+			writeRateLimited(w, r, result)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitMiddleware refuses API requests over the caller's limit with 429. It runs after
+// authentication so that authenticated callers are limited by identity rather than address.
+func (server *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !server.config.RateLimitEnabled || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, pattern := server.router.Handler(r)
+		limiter := server.rateLimits.limiterFor(pattern)
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result := limiter.Allow(rateLimitKey(r))
+		writeRateLimitHeaders(w, result)
+
+		if !result.Allowed {
+			rateLimitRequests.Inc(server.routeLabel(r), rateLimitLimited)
+			writeRateLimited(w, r, result)
+			return
+		}
+
+		rateLimitRequests.Inc(server.routeLabel(r), rateLimitAllowed)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeRateLimitHeaders reports the state of the caller's bucket
+func writeRateLimitHeaders(w http.ResponseWriter, result ratelimit.Result) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// writeRateLimited writes the 429 response of a request over its limit
+func writeRateLimited(w http.ResponseWriter, r *http.Request, result ratelimit.Result) {
+	retryAfter := ceilSeconds(result.RetryAfter)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeError(w, r, http.StatusTooManyRequests, codeRateLimited, fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter), map[string]interface{}{
+		"limit":               result.Limit,
+		"retry_after_seconds": retryAfter,
+	})
+}
+
+// rateLimitKey identifies whose bucket a request is charged to: the API key, the pharmacies or
+// chain of a client certificate, the user of a bearer token, or else the client address
+func rateLimitKey(r *http.Request) string {
+	if p, ok := principalFromContext(r.Context()); ok {
+		switch {
+		case p.KeyID != uuid.Nil:
+			return "api_key:" + p.KeyID.String()
+		case len(p.NPIs) > 0:
+			return "npi:" + strings.Join(p.NPIs, "|")
+		case p.Chain != "":
+			return "chain:" + p.Chain
+		case p.Name != "":
+			return "user:" + p.Name
+		}
+	}
+
+	return clientIPKey(r)
+}
+
+// clientIPKey identifies the bucket of the request's client address
+func clientIPKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// purgeRateLimits periodically forgets the buckets of callers that have gone quiet
+func (server *Server) purgeRateLimits(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, limiter := range server.rateLimits.routes {
+			limiter.Purge()
+		}
+		if server.rateLimits.fallback != nil {
+			server.rateLimits.fallback.Purge()
+		}
+		if server.rateLimits.perIP != nil {
+			server.rateLimits.perIP.Purge()
+		}
+	}
+}
+
+// ceilSeconds rounds a duration up to whole seconds for response headers
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+// newRateLimitedServer serves the full middleware chain with a per-IP limit of three requests
+// and a limit of two requests on claim submission
+func newRateLimitedServer(t *testing.T, store *fakeStore) http.Handler {
+	eventLogger, err := logger.NewLogger(t.TempDir())
+	require.NoError(t, err)
+
+	config := util.Config{
+		AuthEnabled:         true,
+		AdminAPIKey:         testAdminKey,
+		MaxRequestBodyBytes: 1 << 10,
+		RateLimitEnabled:    true,
+		RouteRateLimits:     map[string]util.RateLimit{"POST /api/v1/claims": {Rate: 0.001, Burst: 2}},
+		PerIPRateLimit:      &util.RateLimit{Rate: 0.001, Burst: 3},
+	}
+	return NewServer(config, store, eventLogger, nil, nil, nil).handler()
+}
+
+func sendFrom(handler http.Handler, remoteAddr, key string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/claims", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(apiKeyHeader, key)
+	r.RemoteAddr = remoteAddr
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	store, _ := authStore()
+	handler := newRateLimitedServer(t, store)
+
+	// The invalid body is refused by the handler, after the request passed the limits
+	for _, remaining := range []string{"1", "0"} {
+		w := sendFrom(handler, "192.0.2.1:1234", testChainKey)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		require.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+		require.Equal(t, remaining, w.Header().Get("X-RateLimit-Remaining"))
+		require.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+		require.Empty(t, w.Header().Get("Retry-After"))
+	}
+
+	w := sendFrom(handler, "192.0.2.1:1234", testChainKey)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, codeRateLimited, decodeProblem(t, w).Code)
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Contains(t, w.Body.String(), `"retry_after_seconds":`)
+
+	// Another caller has a bucket of its own
+	w = sendFrom(handler, "192.0.2.2:1234", testNPIKey)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestIPRateLimitBeforeAuthentication(t *testing.T) {
+	store, _ := authStore()
+	lookups := 0
+	getAPIKeyByHash := store.getAPIKeyByHash
+	store.getAPIKeyByHash = func(ctx context.Context, keyHash string) (sqlc.APIKey, error) {
+		lookups++
+		return getAPIKeyByHash(ctx, keyHash)
+	}
+	handler := newRateLimitedServer(t, store)
+
+	for range 3 {
+		w := sendFrom(handler, "198.51.100.7:4000", "pck_guessed1")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+	require.Equal(t, 3, lookups)
+
+	// Once the address is over its limit, guesses are refused without looking the key up
+	w := sendFrom(handler, "198.51.100.7:4001", "pck_guessed2")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, codeRateLimited, decodeProblem(t, w).Code)
+	require.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, 3, lookups)
+
+	// Other addresses are unaffected
+	w = sendFrom(handler, "198.51.100.8:4000", "pck_guessed3")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, 4, lookups)
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// effectiveDateLayout is the date-only form accepted for effective dates, read as midnight UTC
+const effectiveDateLayout = "2006-01-02"
+
+// listReferencePrices handles GET /api/v1/drugs/{ndc}/prices
+func (server *Server) listReferencePrices(w http.ResponseWriter, r *http.Request) {
+	ndc := r.PathValue("ndc")
+
+	if _, err := server.store.GetDrug(r.Context(), ndc); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Drug not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to list reference prices")
+		return
+	}
+
+	prices, err := server.store.ListReferencePricesByNDC(r.Context(), ndc)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list reference prices")
+		return
+	}
+
+	data := make([]ReferencePrice, 0, len(prices))
+	for _, price := range prices {
+		data = append(data, convertDBReferencePriceToAPI(price))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// createReferencePrice handles POST /api/v1/drugs/{ndc}/prices
+func (server *Server) createReferencePrice(w http.ResponseWriter, r *http.Request) {
+	var req ReferencePriceRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: unit_price (number), effective_from (date), effective_to (date, optional)",
+		"example": map[string]interface{}{
+			"unit_price":     0.125,
+			"effective_from": "2025-01-01",
+		},
+	}) {
+		return
+	}
+
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	// The dates were checked by validateRequest
+	from, _ := parseEffectiveDate(req.EffectiveFrom)
+	arg := sqlc.CreateReferencePriceParams{
+		NDC:           r.PathValue("ndc"),
+		UnitPrice:     req.UnitPrice,
+		EffectiveFrom: from,
+	}
+
+	if req.EffectiveTo != "" {
+		to, _ := parseEffectiveDate(req.EffectiveTo)
+		if !to.After(from) {
+			verr := invalidField("effective_to", "must be after effective_from")
+			writeValidationError(w, r, verr)
+			return
+		}
+		arg.EffectiveTo = pgtype.Timestamptz{Time: to, Valid: true}
+	}
+
+	if _, err := server.store.GetDrug(r.Context(), arg.NDC); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Drug not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to create reference price")
+		return
+	}
+
+	price, err := server.store.CreateReferencePrice(r.Context(), arg)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to create reference price")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Reference price created successfully",
+		Data:    convertDBReferencePriceToAPI(price),
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// parseEffectiveDate accepts a date or an RFC3339 timestamp
+func parseEffectiveDate(value string) (time.Time, error) {
+	if t, err := time.Parse(effectiveDateLayout, value); err == nil {
+		return t, nil
+	}
+	return parseTime(value)
+}
+
+// convertDBReferencePriceToAPI converts a database reference price to the API model
+func convertDBReferencePriceToAPI(price sqlc.ReferencePrice) ReferencePrice {
+	result := ReferencePrice{
+		ID:            price.ID.String(),
+		NDC:           price.NDC,
+		UnitPrice:     price.UnitPrice,
+		EffectiveFrom: price.EffectiveFrom,
+		Timestamp:     price.Timestamp,
+	}
+
+	if price.EffectiveTo.Valid {
+		result.EffectiveTo = &price.EffectiveTo.Time
+	}
+
+	return result
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// reasonCodePattern restricts reason codes to upper-case identifiers such as BILLED_IN_ERROR
+var reasonCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,63}$`)
+
+// listReversalReasons handles GET /api/v1/reversal-reasons
+func (server *Server) listReversalReasons(w http.ResponseWriter, r *http.Request) {
+	codes, err := server.store.ListReversalReasonCodes(r.Context())
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list reversal reasons")
+		return
+	}
+
+	data := make([]ReversalReason, 0, len(codes))
+	for _, code := range codes {
+		data = append(data, convertDBReversalReasonToAPI(code))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// createReversalReason handles POST /api/v1/reversal-reasons
+func (server *Server) createReversalReason(w http.ResponseWriter, r *http.Request) {
+	var req ReversalReasonRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: code (string), description (string), active (boolean, optional)",
+		"example": map[string]interface{}{
+			"code":        "PATIENT_DECEASED",
+			"description": "Patient deceased before pickup",
+		},
+	}) {
+		return
+	}
+
+	req.Code = strings.TrimSpace(req.Code)
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	if _, err := server.store.GetReversalReasonCode(r.Context(), req.Code); err == nil {
+		writeError(w, r, http.StatusConflict, codeAlreadyExists, "Reason code already exists", map[string]interface{}{
+			"reason_code": req.Code,
+		})
+		return
+	} else if !errors.Is(err, db.ErrNotFound) {
+		writeStoreError(w, r, err, "Failed to create reversal reason")
+		return
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	code, err := server.store.CreateReversalReasonCode(r.Context(), sqlc.CreateReversalReasonCodeParams{
+		Code:        req.Code,
+		Description: strings.TrimSpace(req.Description),
+		Active:      active,
+	})
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to create reversal reason")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Reversal reason created successfully",
+		Data:    convertDBReversalReasonToAPI(code),
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// updateReversalReason handles PUT /api/v1/reversal-reasons/{code}
+func (server *Server) updateReversalReason(w http.ResponseWriter, r *http.Request) {
+	codeParam := r.PathValue("code")
+
+	var req ReversalReasonRequest
+	if !server.decodeJSON(w, r, &req, map[string]interface{}{
+		"expected_format": "JSON object with fields: description (string), active (boolean)",
+		"example": map[string]interface{}{
+			"description": "Claim was billed in error",
+			"active":      false,
+		},
+	}) {
+		return
+	}
+
+	existing, err := server.store.GetReversalReasonCode(r.Context(), codeParam)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Reversal reason not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to update reversal reason")
+		return
+	}
+
+	// Fields left out of the request keep their stored values
+	req.Code = existing.Code
+	if strings.TrimSpace(req.Description) == "" {
+		req.Description = existing.Description
+	}
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	arg := sqlc.UpdateReversalReasonCodeParams{
+		Code:        existing.Code,
+		Description: strings.TrimSpace(req.Description),
+		Active:      existing.Active,
+	}
+	if req.Active != nil {
+		arg.Active = *req.Active
+	}
+
+	code, err := server.store.UpdateReversalReasonCode(r.Context(), arg)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to update reversal reason")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: "Reversal reason updated successfully",
+		Data:    convertDBReversalReasonToAPI(code),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// convertDBReversalReasonToAPI converts a database reason code to the API model
+func convertDBReversalReasonToAPI(code sqlc.ReversalReasonCode) ReversalReason {
+	return ReversalReason{
+		Code:        code.Code,
+		Description: code.Description,
+		Active:      code.Active,
+		Timestamp:   code.Timestamp,
+	}
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pharmacy_claims_application/auth"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// postAs sends a JSON request on behalf of the principal, or anonymously when it is nil
+func postAs(server *Server, p *principal, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if p != nil {
+		ctx := context.WithValue(r.Context(), principalContextKey, p)
+		r = r.WithContext(withActor(ctx, p.Name))
+	}
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, r)
+	return w
+}
+
+// expiredWindowStore reverses every claim as if its reversal window had closed, so only
+// overridden reversals succeed
+func expiredWindowStore(calls *int) *fakeStore {
+	reverse := func(arg db.CreateReversalTxParams) (db.CreateReversalTxResult, error) {
+		*calls++
+		if !arg.OverrideWindow {
+			return db.CreateReversalTxResult{}, &db.ReversalWindowExpiredError{}
+		}
+
+		return db.CreateReversalTxResult{
+			Reversal: sqlc.Reversal{
+				ID:         uuid.New(),
+				ClaimID:    arg.ClaimID,
+				Kind:       db.ReversalKindReversal,
+				ReasonCode: arg.ReasonCode,
+				Actor:      arg.Actor,
+			},
+			WindowOverridden: true,
+		}, nil
+	}
+
+	return &fakeStore{
+		createReversalTx: func(ctx context.Context, arg db.CreateReversalTxParams) (db.CreateReversalTxResult, error) {
+			return reverse(arg)
+		},
+		createReversalBatchTx: func(ctx context.Context, args []db.CreateReversalTxParams, allOrNothing bool) ([]db.CreateReversalBatchItem, error) {
+			items := make([]db.CreateReversalBatchItem, len(args))
+			for i, arg := range args {
+				items[i].ClaimID = arg.ClaimID
+				items[i].CreateReversalTxResult, items[i].Err = reverse(arg)
+			}
+			return items, nil
+		},
+	}
+}
+
+func TestCreateReversalOverrideWindow(t *testing.T) {
+	claimID := uuid.New()
+	body := fmt.Sprintf(`{"claim_id": %q, "reason_code": "BILLED_IN_ERROR", "override_window": true}`, claimID)
+
+	testCases := []struct {
+		name      string
+		principal *principal
+		actor     string
+		allowed   bool
+	}{
+		{name: "admin key", principal: &principal{Admin: true, Name: adminActor}, actor: adminActor, allowed: true},
+		{name: "admin role", principal: &principal{Admin: true, Name: "jane", Permissions: auth.PermissionsFor([]string{auth.RoleAdmin})}, actor: "jane", allowed: true},
+		{name: "authentication disabled", actor: anonymousActor, allowed: true},
+		{name: "scoped key", principal: &principal{Name: "cvs-key", Chain: "CVS"}},
+		{name: "role with reverse permission", principal: &principal{Name: "joe", Permissions: map[auth.Permission]bool{auth.PermissionReverseClaims: true}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := newBatchServer(t, expiredWindowStore(&calls))
+
+			w := postAs(server, tc.principal, "/api/v1/reversals", body)
+
+			if !tc.allowed {
+				require.Equal(t, http.StatusForbidden, w.Code)
+				require.Equal(t, codeForbidden, decodeProblem(t, w).Code)
+				require.Contains(t, w.Body.String(), `"field":"override_window"`)
+				require.Zero(t, calls)
+				return
+			}
+
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			require.Equal(t, 1, calls)
+
+			var response struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Equal(t, true, response.Data["window_override"])
+			require.Equal(t, tc.actor, response.Data["actor"])
+
+			// The override is recorded together with the actor who made it
+			events, err := server.logger.GetEventsByType(logger.EventClaimReversed)
+			require.NoError(t, err)
+			require.Len(t, events, 1)
+			require.Equal(t, claimID.String(), events[0].Data["claim_id"])
+			require.Equal(t, tc.actor, events[0].Data["actor"])
+			require.Equal(t, true, events[0].Data["window_override"])
+		})
+	}
+}
+
+func TestCreateReversalWithoutOverride(t *testing.T) {
+	calls := 0
+	server := newBatchServer(t, expiredWindowStore(&calls))
+
+	body := fmt.Sprintf(`{"claim_id": %q, "reason_code": "BILLED_IN_ERROR"}`, uuid.New())
+	w := postAs(server, &principal{Name: "joe", Permissions: map[auth.Permission]bool{auth.PermissionReverseClaims: true}}, "/api/v1/reversals", body)
+
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	require.Equal(t, codeReversalWindowClosed, decodeProblem(t, w).Code)
+	require.Equal(t, 1, calls)
+}
+
+func TestCreateReversalBatchOverrideWindow(t *testing.T) {
+	body := fmt.Sprintf(`{"claim_ids": [%q, %q], "reason_code": "BILLED_IN_ERROR", "override_window": true}`, uuid.New(), uuid.New())
+
+	t.Run("admin", func(t *testing.T) {
+		calls := 0
+		server := newBatchServer(t, expiredWindowStore(&calls))
+
+		w := postAs(server, &principal{Admin: true, Name: adminActor}, "/api/v1/reversals/batch", body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Equal(t, 2, calls)
+
+		events, err := server.logger.GetEventsByType(logger.EventClaimReversed)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		for _, event := range events {
+			require.Equal(t, adminActor, event.Data["actor"])
+			require.Equal(t, true, event.Data["window_override"])
+		}
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		calls := 0
+		server := newBatchServer(t, expiredWindowStore(&calls))
+
+		w := postAs(server, &principal{Name: "joe", Permissions: map[auth.Permission]bool{auth.PermissionReverseClaims: true}}, "/api/v1/reversals/batch", body)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Equal(t, codeForbidden, decodeProblem(t, w).Code)
+		require.Zero(t, calls)
+	})
+}
+
+func TestListReversalsFilters(t *testing.T) {
+	claimID := uuid.New()
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 7, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name  string
+		query string
+		want  sqlc.ListReversalsParams
+	}{
+		{
+			name: "no filters",
+			want: sqlc.ListReversalsParams{RowLimit: defaultPageSize},
+		},
+		{
+			name:  "reason code",
+			query: "reason_code=BILLED_IN_ERROR",
+			want:  sqlc.ListReversalsParams{ReasonCode: pgtype.Text{String: "BILLED_IN_ERROR", Valid: true}, RowLimit: defaultPageSize},
+		},
+		{
+			name:  "actor",
+			query: "actor=jane",
+			want:  sqlc.ListReversalsParams{Actor: pgtype.Text{String: "jane", Valid: true}, RowLimit: defaultPageSize},
+		},
+		{
+			name:  "claim",
+			query: "claim_id=" + claimID.String(),
+			want:  sqlc.ListReversalsParams{ClaimID: pgtype.UUID{Bytes: claimID, Valid: true}, RowLimit: defaultPageSize},
+		},
+		{
+			name:  "date range",
+			query: "since=2025-03-01T00:00:00Z&until=2025-03-07T12:30:00Z",
+			want: sqlc.ListReversalsParams{
+				Since:    pgtype.Timestamptz{Time: since, Valid: true},
+				Until:    pgtype.Timestamptz{Time: until, Valid: true},
+				RowLimit: defaultPageSize,
+			},
+		},
+		{
+			name:  "all filters with pagination",
+			query: "reason_code=PAYMENT_ADJUSTMENT&actor=admin&since=2025-03-01T00:00:00Z&limit=5&offset=10",
+			want: sqlc.ListReversalsParams{
+				ReasonCode: pgtype.Text{String: "PAYMENT_ADJUSTMENT", Valid: true},
+				Actor:      pgtype.Text{String: "admin", Valid: true},
+				Since:      pgtype.Timestamptz{Time: since, Valid: true},
+				RowLimit:   5,
+				RowOffset:  10,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reversal := sqlc.Reversal{ID: uuid.New(), ClaimID: claimID, Kind: db.ReversalKindReversal, ReasonCode: "BILLED_IN_ERROR", Actor: "jane"}
+
+			var got sqlc.ListReversalsParams
+			server := newBatchServer(t, &fakeStore{
+				listReversals: func(ctx context.Context, arg sqlc.ListReversalsParams) ([]sqlc.Reversal, error) {
+					got = arg
+					return []sqlc.Reversal{reversal}, nil
+				},
+			})
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reversals?"+tc.query, nil))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.Equal(t, tc.want, got)
+
+			var response struct {
+				Data []Reversal `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response.Data, 1)
+			require.Equal(t, reversal.ID.String(), response.Data[0].ID)
+			require.Equal(t, "jane", response.Data[0].Actor)
+		})
+	}
+}
+
+func TestListReversalsInvalidFilters(t *testing.T) {
+	testCases := []struct {
+		name  string
+		query string
+		field string
+	}{
+		{name: "claim id", query: "claim_id=abc", field: "claim_id"},
+		{name: "since", query: "since=2025-03-01", field: "since"},
+		{name: "until", query: "until=yesterday", field: "until"},
+		{name: "limit", query: "limit=0", field: "limit"},
+		{name: "offset", query: "offset=-1", field: "offset"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newBatchServer(t, &fakeStore{})
+
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reversals?"+tc.query, nil))
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			require.Equal(t, codeInvalidParameter, decodeProblem(t, w).Code)
+			require.Contains(t, w.Body.String(), fmt.Sprintf(`"field":%q`, tc.field))
+		})
+	}
+}
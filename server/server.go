@@ -3,24 +3,42 @@ package server
 import (
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/auth"
+	"github.com/pharmacy_claims_application/certs"
 	"github.com/pharmacy_claims_application/db"
 	"github.com/pharmacy_claims_application/logger"
+	"github.com/pharmacy_claims_application/metrics"
+	"github.com/pharmacy_claims_application/pricing"
 	"github.com/pharmacy_claims_application/util"
 )
 
 type Server struct {
+	config util.Config
 	store  db.Store
 	router *http.ServeMux
 	logger *logger.Logger
+
+	adjudicator *adjudication.Engine
+	pricer      *pricing.Pricer
+	verifier    *auth.Verifier
+	rateLimits  *rateLimits
 }
 
-func NewServer(store db.Store, logger *logger.Logger) *Server {
+func NewServer(config util.Config, store db.Store, logger *logger.Logger, adjudicator *adjudication.Engine, pricer *pricing.Pricer, verifier *auth.Verifier) *Server {
 	server := &Server{
-		store:  store,
-		router: http.NewServeMux(),
-		logger: logger,
+		config:      config,
+		store:       store,
+		router:      http.NewServeMux(),
+		logger:      logger,
+		adjudicator: adjudicator,
+		pricer:      pricer,
+		verifier:    verifier,
+		rateLimits:  newRateLimits(config),
 	}
 
 	server.setupRoutes()
@@ -30,29 +48,103 @@ func NewServer(store db.Store, logger *logger.Logger) *Server {
 func (server *Server) setupRoutes() {
 	// Health check endpoint
 	server.router.HandleFunc("GET /health", server.healthCheck)
+	server.router.HandleFunc("GET /health/live", server.livenessCheck)
+	server.router.HandleFunc("GET /health/ready", server.readinessCheck)
+
+	// Prometheus metrics
+	server.router.Handle("GET /metrics", metrics.DefaultRegistry.Handler())
+
+	// API documentation
+	server.router.HandleFunc("GET /openapi.json", server.serveOpenAPI)
+	server.router.HandleFunc("GET /docs", server.serveAPIDocs)
 
 	// API endpoints
 	server.router.HandleFunc("POST /api/v1/claims", server.createClaim)
+	server.router.HandleFunc("POST /api/v1/claims/batch", server.createClaimBatch)
+	server.router.HandleFunc("POST /api/v1/claims/files", server.uploadClaimFile)
 	server.router.HandleFunc("GET /api/v1/claims/{id}", server.getClaim)
 	server.router.HandleFunc("POST /api/v1/reversals", server.createReversal)
+	server.router.HandleFunc("POST /api/v1/reversals/batch", server.createReversalBatch)
+	server.router.HandleFunc("GET /api/v1/reversals", server.listReversals)
+	server.router.HandleFunc("GET /api/v1/reversal-reasons", server.listReversalReasons)
+	server.router.HandleFunc("POST /api/v1/reversal-reasons", server.createReversalReason)
+	server.router.HandleFunc("PUT /api/v1/reversal-reasons/{code}", server.updateReversalReason)
+	server.router.HandleFunc("PATCH /api/v1/pharmacies/{npi}", server.updatePharmacy)
+	server.router.HandleFunc("GET /api/v1/drugs", server.listDrugs)
+	server.router.HandleFunc("POST /api/v1/drugs", server.createDrug)
+	server.router.HandleFunc("GET /api/v1/drugs/{ndc}", server.getDrug)
+	server.router.HandleFunc("PUT /api/v1/drugs/{ndc}", server.updateDrug)
+	server.router.HandleFunc("DELETE /api/v1/drugs/{ndc}", server.deleteDrug)
+	server.router.HandleFunc("GET /api/v1/drugs/{ndc}/prices", server.listReferencePrices)
+	server.router.HandleFunc("POST /api/v1/drugs/{ndc}/prices", server.createReferencePrice)
+	server.router.HandleFunc("GET /api/v1/contracts", server.listContracts)
+	server.router.HandleFunc("POST /api/v1/contracts", server.createContract)
+	server.router.HandleFunc("GET /api/v1/contracts/{id}", server.getContract)
+	server.router.HandleFunc("PUT /api/v1/contracts/{id}", server.updateContract)
+	server.router.HandleFunc("GET /api/v1/reports/chain-pricing", server.chainPricingReport)
+	server.router.HandleFunc("POST /api/v1/settlements", server.createSettlement)
+	server.router.HandleFunc("GET /api/v1/settlements", server.listSettlements)
+	server.router.HandleFunc("GET /api/v1/settlements/{id}", server.getSettlement)
+	server.router.HandleFunc("GET /api/v1/settlement-batches/{id}", server.getSettlementBatch)
+	server.router.HandleFunc("GET /api/v1/settlement-batches/{id}/remittance", server.getSettlementRemittance)
+	server.router.HandleFunc("GET /api/v1/pharmacies/{npi}/settlement-batches", server.listPharmacySettlementBatches)
+	server.router.HandleFunc("GET /api/v1/api-keys", server.listAPIKeys)
+	server.router.HandleFunc("POST /api/v1/api-keys", server.createAPIKey)
+	server.router.HandleFunc("DELETE /api/v1/api-keys/{id}", server.revokeAPIKey)
+
+	// v2 claim representation; the v1 claim routes above are deprecated in its favour
+	server.router.HandleFunc("POST /api/v2/claims", server.createClaimV2)
+	server.router.HandleFunc("GET /api/v2/claims/{id}", server.getClaimV2)
+}
+
+// handler wraps the router in the middleware every request passes through
+func (server *Server) handler() http.Handler {
+	return server.loggingMiddleware(server.deprecationMiddleware(server.actorMiddleware(server.ipRateLimitMiddleware(server.authMiddleware(server.rateLimitMiddleware(server.router))))))
 }
 
-func (server *Server) Start(config util.Config) error {
-	serverWithMiddleware := server.loggingMiddleware(server.router)
+func (server *Server) Start() error {
+	serverWithMiddleware := server.handler()
+
+	if !server.config.AuthEnabled {
+		log.Printf("Warning: AUTH_ENABLED is false; every endpoint is open to unauthenticated callers")
+	}
+
+	// Remove expired idempotency keys in the background
+	go server.purgeIdempotencyKeys(idempotencyPurgeInterval)
+
+	// Forget the token buckets of idle callers
+	go server.purgeRateLimits(rateLimitPurgeInterval)
 
 	srv := &http.Server{
-		Addr:         config.ServerAddress,
+		Addr:         server.config.ServerAddress,
 		Handler:      serverWithMiddleware,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	log.Printf("Starting server on %s", config.ServerAddress)
+	if server.config.TLSCertFile != "" || server.config.TLSKeyFile != "" {
+		reloader, err := certs.NewReloader(server.config.TLSCertFile, server.config.TLSKeyFile, server.config.TLSClientCAFile, server.config.TLSRequireClientCert)
+		if err != nil {
+			return err
+		}
+
+		// Pick up rotated certificates without a restart
+		if server.config.TLSReloadInterval > 0 {
+			go reloader.Watch(server.config.TLSReloadInterval)
+		}
+
+		srv.TLSConfig = reloader.TLSConfig()
+
+		log.Printf("Starting server with TLS on %s", server.config.ServerAddress)
+		return srv.ListenAndServeTLS("", "")
+	}
+
+	log.Printf("Starting server on %s", server.config.ServerAddress)
 	return srv.ListenAndServe()
 }
 
-// Middleware for logging requests
+// Middleware for logging requests and recording handler latency
 func (server *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -62,6 +154,8 @@ func (server *Server) loggingMiddleware(next http.Handler) http.Handler {
 
 		next.ServeHTTP(wrapped, r)
 
+		requestDuration.Observe(time.Since(start).Seconds(), r.Method, server.routeLabel(r), strconv.Itoa(wrapped.statusCode))
+
 		log.Printf(
 			"%s %s %d %v",
 			r.Method,
@@ -72,6 +166,20 @@ func (server *Server) loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// routeLabel returns the route pattern the router matches for r, without the method prefix.
+// The router is asked directly because middleware may have served a copy of the request.
+func (server *Server) routeLabel(r *http.Request) string {
+	_, pattern := server.router.Handler(r)
+	if pattern == "" {
+		return "unmatched"
+	}
+
+	if _, path, found := strings.Cut(pattern, " "); found {
+		return path
+	}
+	return pattern
+}
+
 // Custom response writer to capture status code
 type responseWriter struct {
 	http.ResponseWriter
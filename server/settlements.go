@@ -0,0 +1,302 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+	"github.com/pharmacy_claims_application/remittance"
+)
+
+// createSettlement handles POST /api/v1/settlements
+func (server *Server) createSettlement(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; without one the cycle settles everything recorded until now
+	var req CreateSettlementRequest
+	if rerr := decodeJSONBody(w, r, &req, server.config.MaxRequestBodyBytes, true); rerr != nil {
+		writeRequestError(w, r, rerr, map[string]interface{}{
+			"expected_format": "JSON object with fields: cutoff (RFC3339 timestamp, optional)",
+			"example": map[string]interface{}{
+				"cutoff": "2025-03-07T00:00:00Z",
+			},
+		})
+		return
+	}
+
+	if verr := validateRequest(req); verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	now := time.Now()
+	cutoff := now
+	if req.Cutoff != "" {
+		cutoff, _ = parseTime(req.Cutoff)
+		if cutoff.After(now) {
+			verr := invalidField("cutoff", "cannot be in the future")
+			writeValidationError(w, r, verr)
+			return
+		}
+	}
+
+	result, err := server.store.CreateSettlementCycleTx(r.Context(), cutoff)
+	if err != nil {
+		log.Printf("Settlement failed: %v", err)
+		writeStoreError(w, r, err, "Failed to run settlement cycle")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("Settlement cycle created with %d payment batches", len(result.Batches)),
+		Data:    convertDBSettlementCycleToAPI(result.Cycle, result.Batches),
+	}
+
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// listSettlements handles GET /api/v1/settlements
+func (server *Server) listSettlements(w http.ResponseWriter, r *http.Request) {
+	limit, offset, verr := parsePagination(r.URL.Query())
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	cycles, err := server.store.ListSettlementCycles(r.Context(), sqlc.ListSettlementCyclesParams{
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list settlement cycles")
+		return
+	}
+
+	data := make([]SettlementCycle, 0, len(cycles))
+	for _, cycle := range cycles {
+		data = append(data, convertDBSettlementCycleToAPI(cycle, nil))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getSettlement handles GET /api/v1/settlements/{id}
+func (server *Server) getSettlement(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSettlementID(w, r)
+	if !ok {
+		return
+	}
+
+	cycle, err := server.store.GetSettlementCycle(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Settlement cycle not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to get settlement cycle")
+		return
+	}
+
+	batches, err := server.store.ListSettlementBatchesByCycle(r.Context(), cycle.ID)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to get settlement cycle")
+		return
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    convertDBSettlementCycleToAPI(cycle, batches),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getSettlementBatch handles GET /api/v1/settlement-batches/{id}
+func (server *Server) getSettlementBatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSettlementID(w, r)
+	if !ok {
+		return
+	}
+
+	batch, err := server.store.GetSettlementBatch(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Settlement batch not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to get settlement batch")
+		return
+	}
+
+	items, err := server.store.ListSettlementItemsByBatch(r.Context(), batch.ID)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to get settlement batch")
+		return
+	}
+
+	apiBatch := convertDBSettlementBatchToAPI(batch)
+	apiBatch.Items = make([]SettlementItem, 0, len(items))
+	for _, item := range items {
+		apiBatch.Items = append(apiBatch.Items, convertDBSettlementItemToAPI(item))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    apiBatch,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getSettlementRemittance handles GET /api/v1/settlement-batches/{id}/remittance
+func (server *Server) getSettlementRemittance(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSettlementID(w, r)
+	if !ok {
+		return
+	}
+
+	batch, err := server.store.GetSettlementBatch(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			writeError(w, r, http.StatusNotFound, codeNotFound, "Settlement batch not found")
+			return
+		}
+		writeStoreError(w, r, err, "Failed to build remittance")
+		return
+	}
+
+	cycle, err := server.store.GetSettlementCycle(r.Context(), batch.CycleID)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to build remittance")
+		return
+	}
+
+	pharmacy, err := server.store.GetPharmacy(r.Context(), batch.NPI)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to build remittance")
+		return
+	}
+
+	items, err := server.store.ListSettlementItemsByBatch(r.Context(), batch.ID)
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to build remittance")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", remittance.FileName(batch, cycle)))
+	w.WriteHeader(http.StatusOK)
+
+	err = remittance.Write(w, remittance.Remittance{
+		Payer: remittance.Payer{
+			ID:   server.config.SettlementPayerID,
+			Name: server.config.SettlementPayerName,
+		},
+		Pharmacy: pharmacy,
+		Cycle:    cycle,
+		Batch:    batch,
+		Items:    items,
+		Created:  batch.Timestamp,
+	})
+	if err != nil {
+		log.Printf("Failed to write remittance for batch %s: %v", batch.ID, err)
+	}
+}
+
+// listPharmacySettlementBatches handles GET /api/v1/pharmacies/{npi}/settlement-batches
+func (server *Server) listPharmacySettlementBatches(w http.ResponseWriter, r *http.Request) {
+	limit, offset, verr := parsePagination(r.URL.Query())
+	if verr != nil {
+		writeValidationError(w, r, verr)
+		return
+	}
+
+	batches, err := server.store.ListSettlementBatchesByNPI(r.Context(), sqlc.ListSettlementBatchesByNPIParams{
+		NPI:       r.PathValue("npi"),
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		writeStoreError(w, r, err, "Failed to list settlement batches")
+		return
+	}
+
+	data := make([]SettlementBatch, 0, len(batches))
+	for _, batch := range batches {
+		data = append(data, convertDBSettlementBatchToAPI(batch))
+	}
+
+	response := APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parseSettlementID reads the ID path value, writing a 400 when it is not a UUID
+func parseSettlementID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeParameterError(w, r, "id", "must be a UUID", "550e8400-e29b-41d4-a716-446655440000")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// convertDBSettlementCycleToAPI converts a database settlement cycle and its batches to the API model
+func convertDBSettlementCycleToAPI(cycle sqlc.SettlementCycle, batches []sqlc.SettlementBatch) SettlementCycle {
+	result := SettlementCycle{
+		ID:        cycle.ID.String(),
+		Cutoff:    cycle.Cutoff,
+		Timestamp: cycle.Timestamp,
+	}
+
+	for _, batch := range batches {
+		result.Batches = append(result.Batches, convertDBSettlementBatchToAPI(batch))
+	}
+
+	return result
+}
+
+// convertDBSettlementBatchToAPI converts a database payment batch to the API model
+func convertDBSettlementBatchToAPI(batch sqlc.SettlementBatch) SettlementBatch {
+	return SettlementBatch{
+		ID:             batch.ID.String(),
+		CycleID:        batch.CycleID.String(),
+		NPI:            batch.NPI,
+		ClaimCount:     batch.ClaimCount,
+		PaymentAmount:  batch.PaymentAmount,
+		ClawbackAmount: batch.ClawbackAmount,
+		NetAmount:      batch.NetAmount,
+		Timestamp:      batch.Timestamp,
+	}
+}
+
+// convertDBSettlementItemToAPI converts a database settlement item to the API model
+func convertDBSettlementItemToAPI(item sqlc.ListSettlementItemsByBatchRow) SettlementItem {
+	result := SettlementItem{
+		ID:         item.ID.String(),
+		ClaimID:    item.ClaimID.String(),
+		Kind:       item.Kind,
+		Amount:     item.Amount,
+		NDC:        item.NDC,
+		Quantity:   item.Quantity,
+		ClaimPrice: item.Price,
+	}
+
+	if item.ReversalID.Valid {
+		result.ReversalID = uuid.UUID(item.ReversalID.Bytes).String()
+	}
+
+	return result
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/pharmacy_claims_application/db"
+)
+
+// storeRetryAfter is the number of seconds clients are asked to wait before retrying a request
+// that failed because the database was busy or unavailable
+const storeRetryAfter = 1
+
+// storeErrorStatus maps a store error to the status code and error code of its response.
+// Conflicts and outages are reported as 503 so that clients retry them, and anything the store
+// could not classify is a 500.
+func storeErrorStatus(err error) (int, errorCode) {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return http.StatusNotFound, codeNotFound
+	case errors.Is(err, db.ErrUniqueViolation):
+		return http.StatusConflict, codeAlreadyExists
+	case errors.Is(err, db.ErrForeignKeyViolation):
+		return http.StatusUnprocessableEntity, codeInvalidReference
+	case errors.Is(err, db.ErrSerializationFailure):
+		return http.StatusServiceUnavailable, codeTransactionConflict
+	case errors.Is(err, db.ErrTimeout), errors.Is(err, db.ErrUnavailable):
+		return http.StatusServiceUnavailable, codeServiceUnavailable
+	default:
+		return http.StatusInternalServerError, codeInternal
+	}
+}
+
+// writeStoreError writes the problem response for a failed store call. detail describes the
+// operation that failed, such as "Failed to get claim".
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error, detail string) {
+	statusCode, code := storeErrorStatus(err)
+
+	switch statusCode {
+	case http.StatusServiceUnavailable:
+		setStoreRetryAfter(w)
+	case http.StatusInternalServerError:
+		log.Printf("%s: %v", detail, err)
+	}
+
+	writeError(w, r, statusCode, code, detail)
+}
+
+// setStoreRetryAfter asks the client to retry a request the database could not serve
+func setStoreRetryAfter(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(storeRetryAfter))
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pharmacy_claims_application/db"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteStoreError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		err        error
+		statusCode int
+		code       errorCode
+		retry      bool
+	}{
+		{"not found", db.TranslateError(pgx.ErrNoRows), http.StatusNotFound, codeNotFound, false},
+		{"unique", db.TranslateError(&pgconn.PgError{Code: "23505"}), http.StatusConflict, codeAlreadyExists, false},
+		{"foreign key", db.TranslateError(&pgconn.PgError{Code: "23503"}), http.StatusUnprocessableEntity, codeInvalidReference, false},
+		{"serialization", db.TranslateError(&pgconn.PgError{Code: "40001"}), http.StatusServiceUnavailable, codeTransactionConflict, true},
+		{"timeout", db.TranslateError(&pgconn.PgError{Code: "57014"}), http.StatusServiceUnavailable, codeServiceUnavailable, true},
+		{"unavailable", db.TranslateError(&pgconn.ConnectError{}), http.StatusServiceUnavailable, codeServiceUnavailable, true},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, codeInternal, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/claims/42", nil)
+			w := httptest.NewRecorder()
+
+			writeStoreError(w, r, tc.err, "Failed to get claim")
+
+			require.Equal(t, tc.statusCode, w.Code)
+			require.Contains(t, w.Body.String(), `"code":"`+string(tc.code)+`"`)
+			require.Equal(t, tc.retry, w.Header().Get("Retry-After") != "")
+		})
+	}
+}
+
+func TestCreateClaimErrorFromStore(t *testing.T) {
+	verr, statusCode := createClaimError(db.TranslateError(&pgconn.PgError{Code: "40001"}))
+	require.Equal(t, http.StatusServiceUnavailable, statusCode)
+	require.Equal(t, codeTransactionConflict, verr.Code)
+	require.Equal(t, rejectStoreError, verr.Reason)
+
+	verr, statusCode = createClaimError(errors.New("boom"))
+	require.Equal(t, http.StatusInternalServerError, statusCode)
+	require.Equal(t, codeInternal, verr.Code)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/db"
+	sqlc "github.com/pharmacy_claims_application/db/sqlc"
+)
+
+// fakeStore is a db.Store for handler tests. Each method a test needs is backed by a function
+// field; calling any other method panics on the nil embedded Store.
+type fakeStore struct {
+	db.Store
+
+	ping             func(ctx context.Context) error
+	migrationVersion func(ctx context.Context) (int64, bool, error)
+	countPharmacies  func(ctx context.Context) (int64, error)
+
+	getClaim        func(ctx context.Context, id uuid.UUID) (sqlc.Claim, error)
+	getPharmacy     func(ctx context.Context, npi string) (sqlc.Pharmacy, error)
+	getAPIKeyByHash func(ctx context.Context, keyHash string) (sqlc.APIKey, error)
+	listReversals   func(ctx context.Context, arg sqlc.ListReversalsParams) ([]sqlc.Reversal, error)
+
+	createClaimIdempotentTx func(ctx context.Context, arg db.CreateClaimIdempotentTxParams) (db.CreateClaimIdempotentTxResult, error)
+	createClaimBatchTx      func(ctx context.Context, args []db.CreateClaimTxParams) ([]db.CreateClaimBatchItem, error)
+	createReversalTx        func(ctx context.Context, arg db.CreateReversalTxParams) (db.CreateReversalTxResult, error)
+	createReversalBatchTx   func(ctx context.Context, args []db.CreateReversalTxParams, allOrNothing bool) ([]db.CreateReversalBatchItem, error)
+}
+
+func (store *fakeStore) Ping(ctx context.Context) error {
+	return store.ping(ctx)
+}
+
+func (store *fakeStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	return store.migrationVersion(ctx)
+}
+
+func (store *fakeStore) CountPharmacies(ctx context.Context) (int64, error) {
+	return store.countPharmacies(ctx)
+}
+
+func (store *fakeStore) GetClaim(ctx context.Context, id uuid.UUID) (sqlc.Claim, error) {
+	return store.getClaim(ctx, id)
+}
+
+func (store *fakeStore) GetPharmacy(ctx context.Context, npi string) (sqlc.Pharmacy, error) {
+	return store.getPharmacy(ctx, npi)
+}
+
+func (store *fakeStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (sqlc.APIKey, error) {
+	return store.getAPIKeyByHash(ctx, keyHash)
+}
+
+func (store *fakeStore) ListReversals(ctx context.Context, arg sqlc.ListReversalsParams) ([]sqlc.Reversal, error) {
+	return store.listReversals(ctx, arg)
+}
+
+func (store *fakeStore) CreateClaimIdempotentTx(ctx context.Context, arg db.CreateClaimIdempotentTxParams) (db.CreateClaimIdempotentTxResult, error) {
+	return store.createClaimIdempotentTx(ctx, arg)
+}
+
+func (store *fakeStore) CreateClaimBatchTx(ctx context.Context, args []db.CreateClaimTxParams) ([]db.CreateClaimBatchItem, error) {
+	return store.createClaimBatchTx(ctx, args)
+}
+
+func (store *fakeStore) CreateReversalTx(ctx context.Context, arg db.CreateReversalTxParams) (db.CreateReversalTxResult, error) {
+	return store.createReversalTx(ctx, arg)
+}
+
+func (store *fakeStore) CreateReversalBatchTx(ctx context.Context, args []db.CreateReversalTxParams, allOrNothing bool) ([]db.CreateReversalBatchItem, error) {
+	return store.createReversalBatchTx(ctx, args, allOrNothing)
+}
+
+// healthyStore returns a fake store whose readiness checks all pass
+func healthyStore() *fakeStore {
+	return &fakeStore{
+		ping: func(ctx context.Context) error { return nil },
+		migrationVersion: func(ctx context.Context) (int64, bool, error) {
+			return db.SchemaVersion, false, nil
+		},
+		countPharmacies: func(ctx context.Context) (int64, error) { return 17, nil },
+	}
+}
@@ -4,36 +4,303 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/adjudication"
+	"github.com/pharmacy_claims_application/db"
 )
 
 // Claim represents a pharmacy claim
 type Claim struct {
-	ID        string    `json:"id"`
-	NDC       string    `json:"ndc"`
-	Quantity  int       `json:"quantity"`
-	NPI       string    `json:"npi"`
-	Price     float64   `json:"price"`
-	Timestamp time.Time `json:"timestamp"`
+	ID                string    `json:"id"`
+	NDC               string    `json:"ndc"`
+	Quantity          int       `json:"quantity"`
+	NPI               string    `json:"npi"`
+	Price             float64   `json:"price"`
+	Timestamp         time.Time `json:"timestamp"`
+	PossibleDuplicate bool      `json:"possible_duplicate"`
+
+	// AllowedAmount is the reference price of the fill; nil when the NDC was not priced
+	AllowedAmount       *float64 `json:"allowed_amount"`
+	PriceExceedsAllowed bool     `json:"price_exceeds_allowed"`
+
+	Adjudication adjudication.Decision `json:"adjudication"`
+
+	// Balance and Reversals are populated when a single claim is retrieved
+	Balance   *db.ClaimBalance `json:"balance,omitempty"`
+	Reversals []Reversal       `json:"reversals,omitempty"`
 }
 
 // Reversal represents a pharmacy claim reversal
 type Reversal struct {
-	ID        string    `json:"id"`
-	ClaimID   string    `json:"claim_id"`
+	ID         string    `json:"id"`
+	ClaimID    string    `json:"claim_id"`
+	Kind       string    `json:"kind"`
+	Quantity   int64     `json:"quantity"`
+	Amount     float64   `json:"amount"`
+	ReasonCode string    `json:"reason_code"`
+	Notes      string    `json:"notes,omitempty"`
+	Actor      string    `json:"actor"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// ClaimV2 represents a claim in /api/v2. Amounts are decimal strings, timestamps are RFC 3339
+// in UTC, and the pharmacy, balance and reversals are always included.
+type ClaimV2 struct {
+	ID       string        `json:"id"`
+	Status   string        `json:"status"`
+	NDC      string        `json:"ndc"`
+	Quantity int64         `json:"quantity"`
+	Pharmacy ClaimPharmacy `json:"pharmacy"`
+
+	SubmittedAmount Decimal `json:"submitted_amount"`
+	// AllowedAmount is the reference price of the fill; nil when the NDC was not priced
+	AllowedAmount       *Decimal `json:"allowed_amount"`
+	PriceExceedsAllowed bool     `json:"price_exceeds_allowed"`
+
+	// DuplicateOf is only known when the claim is created
+	PossibleDuplicate bool   `json:"possible_duplicate"`
+	DuplicateOf       string `json:"duplicate_of,omitempty"`
+
+	Adjudication adjudication.Decision `json:"adjudication"`
+	Balance      ClaimBalanceV2        `json:"balance"`
+	Reversals    []ReversalV2          `json:"reversals"`
+	SubmittedAt  string                `json:"submitted_at"`
+}
+
+// ClaimPharmacy represents the submitting pharmacy embedded in a v2 claim
+type ClaimPharmacy struct {
+	NPI    string `json:"npi"`
+	Chain  string `json:"chain"`
+	Active bool   `json:"active"`
+}
+
+// ClaimBalanceV2 represents the net balance of a v2 claim after reversals and adjustments
+type ClaimBalanceV2 struct {
+	ReversedQuantity int64   `json:"reversed_quantity"`
+	ReversedAmount   Decimal `json:"reversed_amount"`
+	AdjustedQuantity int64   `json:"adjusted_quantity"`
+	AdjustedAmount   Decimal `json:"adjusted_amount"`
+	NetQuantity      int64   `json:"net_quantity"`
+	NetAmount        Decimal `json:"net_amount"`
+}
+
+// ReversalV2 represents a reversal or adjustment embedded in a v2 claim
+type ReversalV2 struct {
+	ID         string  `json:"id"`
+	Kind       string  `json:"kind"`
+	Quantity   int64   `json:"quantity"`
+	Amount     Decimal `json:"amount"`
+	ReasonCode string  `json:"reason_code"`
+	Notes      string  `json:"notes,omitempty"`
+	Actor      string  `json:"actor"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// Pharmacy represents a pharmacy and whether it may submit claims
+type Pharmacy struct {
+	NPI       string    `json:"npi"`
+	Chain     string    `json:"chain"`
+	Active    bool      `json:"active"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// CreateClaimRequest represents the request body for creating a claim
+// UpdatePharmacyRequest represents the request body for activating or deactivating a pharmacy
+type UpdatePharmacyRequest struct {
+	Active *bool `json:"active" validate:"required"`
+}
+
+// Drug represents a product in the drug reference table
+type Drug struct {
+	NDC           string  `json:"ndc"`
+	Name          string  `json:"name"`
+	Strength      string  `json:"strength"`
+	PackageSize   float64 `json:"package_size"`
+	UnitOfMeasure string  `json:"unit_of_measure"`
+	Active        bool    `json:"active"`
+	// QuantityLimit is the most that one claim may dispense; 0 means no limit
+	QuantityLimit int64     `json:"quantity_limit"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// DrugRequest represents the request body for creating or replacing a drug
+type DrugRequest struct {
+	NDC           string  `json:"ndc" validate:"required,billing_ndc"`
+	Name          string  `json:"name" validate:"required"`
+	Strength      string  `json:"strength"`
+	PackageSize   float64 `json:"package_size" validate:"min=0"`
+	UnitOfMeasure string  `json:"unit_of_measure"`
+	Active        *bool   `json:"active"`
+	QuantityLimit int64   `json:"quantity_limit" validate:"min=0"`
+}
+
+// ReferencePrice represents a unit price of an NDC over an effective date range
+type ReferencePrice struct {
+	ID            string     `json:"id"`
+	NDC           string     `json:"ndc"`
+	UnitPrice     float64    `json:"unit_price"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	Timestamp     time.Time  `json:"timestamp"`
+}
+
+// ReferencePriceRequest represents the request body for adding a reference price
+type ReferencePriceRequest struct {
+	UnitPrice     float64 `json:"unit_price" validate:"min=0"`
+	EffectiveFrom string  `json:"effective_from" validate:"required,date"`
+	EffectiveTo   string  `json:"effective_to" validate:"omitempty,date"`
+}
+
+// Contract represents a chain's negotiated pricing terms over an effective date range
+type Contract struct {
+	ID              string     `json:"id"`
+	Chain           string     `json:"chain"`
+	DiscountPercent float64    `json:"discount_percent"`
+	DispensingFee   float64    `json:"dispensing_fee"`
+	EffectiveFrom   time.Time  `json:"effective_from"`
+	EffectiveTo     *time.Time `json:"effective_to"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// APIKey represents an issued API key. Key is only set in the response that issues it.
+type APIKey struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Key       string     `json:"key,omitempty"`
+	Prefix    string     `json:"prefix"`
+	Chain     string     `json:"chain,omitempty"`
+	NPIs      []string   `json:"npis,omitempty"`
+	Active    bool       `json:"active"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// APIKeyRequest represents the request body for issuing an API key
+type APIKeyRequest struct {
+	Name  string   `json:"name" validate:"required"`
+	NPIs  []string `json:"npis" validate:"dive,npi"`
+	Chain string   `json:"chain"`
+}
+
+// ContractRequest represents the request body for creating or replacing a contract
+type ContractRequest struct {
+	Chain           string  `json:"chain" validate:"required"`
+	DiscountPercent float64 `json:"discount_percent" validate:"min=0,max=100"`
+	DispensingFee   float64 `json:"dispensing_fee" validate:"min=0"`
+	EffectiveFrom   string  `json:"effective_from" validate:"required,date"`
+	EffectiveTo     string  `json:"effective_to" validate:"omitempty,date"`
+}
+
+// ChainPricingSummary compares submitted and contracted totals of a chain's approved claims
+type ChainPricingSummary struct {
+	Chain       string `json:"chain"`
+	ClaimCount  int64  `json:"claim_count"`
+	PricedCount int64  `json:"priced_count"`
+	// ExceedingCount is the number of claims priced above their allowed amount plus tolerance
+	ExceedingCount int64   `json:"exceeding_count"`
+	SubmittedTotal float64 `json:"submitted_total"`
+	// PricedSubmittedTotal and ContractedTotal cover only claims with an allowed amount
+	PricedSubmittedTotal float64 `json:"priced_submitted_total"`
+	ContractedTotal      float64 `json:"contracted_total"`
+	Variance             float64 `json:"variance"`
+}
+
+// SettlementCycle represents a settlement run and the payment batches it produced
+type SettlementCycle struct {
+	ID        string            `json:"id"`
+	Cutoff    time.Time         `json:"cutoff"`
+	Timestamp time.Time         `json:"timestamp"`
+	Batches   []SettlementBatch `json:"batches,omitempty"`
+}
+
+// SettlementBatch represents the payment to one pharmacy in a settlement cycle
+type SettlementBatch struct {
+	ID             string    `json:"id"`
+	CycleID        string    `json:"cycle_id"`
+	NPI            string    `json:"npi"`
+	ClaimCount     int64     `json:"claim_count"`
+	PaymentAmount  float64   `json:"payment_amount"`
+	ClawbackAmount float64   `json:"clawback_amount"`
+	NetAmount      float64   `json:"net_amount"`
+	Timestamp      time.Time `json:"timestamp"`
+
+	// Items are populated when a single batch is retrieved
+	Items []SettlementItem `json:"items,omitempty"`
+}
+
+// SettlementItem represents a payment, reversal, adjustment or clawback in a batch
+type SettlementItem struct {
+	ID         string  `json:"id"`
+	ClaimID    string  `json:"claim_id"`
+	ReversalID string  `json:"reversal_id,omitempty"`
+	Kind       string  `json:"kind"`
+	Amount     float64 `json:"amount"`
+	NDC        string  `json:"ndc"`
+	Quantity   int64   `json:"quantity"`
+	ClaimPrice float64 `json:"claim_price"`
+}
+
+// CreateSettlementRequest represents the request body for running a settlement cycle
+type CreateSettlementRequest struct {
+	// Cutoff defaults to now; claims and reversals recorded before it are settled
+	Cutoff string `json:"cutoff" validate:"omitempty,timestamp"`
+}
+
+// ReversalReason represents an entry in the managed list of reversal reason codes
+type ReversalReason struct {
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// CreateClaimRequest represents the request body for creating a claim. Price may be zero.
 type CreateClaimRequest struct {
-	NDC      string  `json:"ndc" validate:"required"`
+	NDC      string  `json:"ndc" validate:"required,ndc"`
 	Quantity int     `json:"quantity" validate:"required,min=1"`
-	NPI      string  `json:"npi" validate:"required"`
-	Price    float64 `json:"price" validate:"required,min=0"`
+	NPI      string  `json:"npi" validate:"required,npi"`
+	Price    float64 `json:"price" validate:"min=0"`
 }
 
 // CreateReversalRequest represents the request body for creating a reversal
 type CreateReversalRequest struct {
-	ClaimID uuid.UUID `json:"claim_id" validate:"required"`
+	ClaimID    uuid.UUID `json:"claim_id" validate:"required"`
+	Kind       string    `json:"kind" validate:"omitempty,oneof=reversal adjustment"`
+	Quantity   int64     `json:"quantity" validate:"min=0"`
+	Amount     float64   `json:"amount" validate:"min=0"`
+	ReasonCode string    `json:"reason_code" validate:"required"`
+	Notes      string    `json:"notes" validate:"max=1000"`
+	// OverrideWindow accepts the reversal after the claim's reversal window has closed
+	OverrideWindow bool `json:"override_window"`
+}
+
+// BatchReversalRequest represents the request body for reversing many claims
+type BatchReversalRequest struct {
+	ClaimIDs       []uuid.UUID `json:"claim_ids" validate:"required,min=1"`
+	Mode           string      `json:"mode" validate:"omitempty,oneof=best_effort all_or_nothing"`
+	ReasonCode     string      `json:"reason_code" validate:"required"`
+	Notes          string      `json:"notes" validate:"max=1000"`
+	OverrideWindow bool        `json:"override_window"`
+}
+
+// ReversalReasonRequest represents the request body for creating or updating a reason code
+type ReversalReasonRequest struct {
+	Code        string `json:"code" validate:"required,reason_code"`
+	Description string `json:"description" validate:"required"`
+	Active      *bool  `json:"active"`
+}
+
+// BatchItemResult represents the outcome of one item in a batch request
+type BatchItemResult struct {
+	Index               int                    `json:"index"`
+	Status              string                 `json:"status"`
+	ClaimID             string                 `json:"claim_id,omitempty"`
+	ReversalID          string                 `json:"reversal_id,omitempty"`
+	PossibleDuplicate   bool                   `json:"possible_duplicate,omitempty"`
+	DuplicateOf         string                 `json:"duplicate_of,omitempty"`
+	Adjudication        *adjudication.Decision `json:"adjudication,omitempty"`
+	AllowedAmount       *float64               `json:"allowed_amount,omitempty"`
+	PriceExceedsAllowed bool                   `json:"price_exceeds_allowed,omitempty"`
+	// Error describes why the item failed
+	Error *Problem `json:"error,omitempty"`
 }
 
 // APIResponse represents a standard API response
@@ -45,9 +312,23 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+// Problem represents an RFC 7807 error response, served as application/problem+json
+type Problem struct {
+	Type     string    `json:"type"`
+	Title    string    `json:"title"`
+	Status   int       `json:"status"`
+	Detail   string    `json:"detail,omitempty"`
+	Instance string    `json:"instance,omitempty"`
+	Code     errorCode `json:"code"`
+	// Errors lists the invalid fields of the request, if any
+	Errors []fieldError `json:"errors,omitempty"`
+	// Extensions are further members describing the error, written alongside the others
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// ComponentStatus represents the health of a single dependency
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
 }
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pharmacy_claims_application/util"
+)
+
+// formatRule checks a string field against a custom format
+type formatRule struct {
+	check   func(string) bool
+	message string
+}
+
+// formatRules are the custom validate tag rules for string fields
+var formatRules = map[string]formatRule{
+	"ndc": {
+		check:   util.ValidNDCFormat,
+		message: "must be 9 to 11 digits, optionally hyphenated",
+	},
+	"billing_ndc": {
+		check:   func(value string) bool { _, ok := util.NormalizeNDC(value); return ok },
+		message: "must be 11 digits or a hyphenated 4-4-2, 5-3-2 or 5-4-1 NDC",
+	},
+	"npi": {
+		check:   util.ValidNPIFormat,
+		message: "must be 10 digits",
+	},
+	"date": {
+		check:   func(value string) bool { _, err := parseEffectiveDate(value); return err == nil },
+		message: "must be a date (YYYY-MM-DD) or RFC3339 timestamp",
+	},
+	"timestamp": {
+		check:   func(value string) bool { _, err := parseTime(value); return err == nil },
+		message: "must be an RFC3339 timestamp",
+	},
+	"reason_code": {
+		check:   reasonCodePattern.MatchString,
+		message: "must be 2-64 upper-case letters, digits or underscores",
+	},
+}
+
+// validateRequest checks a decoded request body against the validate tags of its fields and
+// reports every invalid field at once. The rules of a tag are checked in order:
+//
+//	required     the field is set: a non-blank string, non-nil pointer, non-empty slice or non-zero value
+//	omitempty    the remaining rules are skipped when the field is not set
+//	min=N max=N  bound a number, or the length of a string or slice
+//	oneof=a b    the value is one of the listed words
+//	dive         the remaining rules apply to each element of a slice
+//	ndc billing_ndc npi date timestamp reason_code  the string has that format
+//
+// Only the first failing rule of a field is reported.
+func validateRequest(v interface{}) *validationError {
+	return newValidationError(validateStruct(reflect.Indirect(reflect.ValueOf(v))))
+}
+
+// newValidationError reports the field errors, or returns nil when there are none
+func newValidationError(errs []fieldError) *validationError {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+	for i, fe := range errs {
+		messages[i] = fe.Field + " " + fe.Message
+	}
+
+	return &validationError{
+		Message: strings.Join(messages, "; "),
+		Fields:  errs,
+	}
+}
+
+// invalidField reports a single invalid field in the shape of validateRequest, for checks that
+// cannot be expressed as tags
+func invalidField(field, message string) *validationError {
+	return &validationError{
+		Message: field + " " + message,
+		Fields:  []fieldError{{Field: field, Message: message}},
+	}
+}
+
+// validateStruct checks the tagged fields of a struct, in field order
+func validateStruct(value reflect.Value) []fieldError {
+	var errs []fieldError
+
+	names := jsonNames(value.Type())
+	for i := 0; i < value.NumField(); i++ {
+		tag, ok := value.Type().Field(i).Tag.Lookup("validate")
+		if !ok || names[i] == "" {
+			continue
+		}
+		errs = append(errs, validateField(names[i], value.Field(i), strings.Split(tag, ","))...)
+	}
+
+	return errs
+}
+
+// validateField checks one value against its rules
+func validateField(name string, value reflect.Value, rules []string) []fieldError {
+	for i, rule := range rules {
+		rule, param, _ := strings.Cut(rule, "=")
+
+		switch rule {
+		case "required":
+			if !isSet(value) {
+				return []fieldError{{Field: name, Rule: rule, Message: "is required"}}
+			}
+
+		case "omitempty":
+			if !isSet(value) {
+				return nil
+			}
+
+		case "min", "max":
+			if message, ok := checkBound(value, rule, param); !ok {
+				return []fieldError{{Field: name, Rule: rule, Message: message}}
+			}
+
+		case "oneof":
+			allowed := strings.Fields(param)
+			if !slices.Contains(allowed, fmt.Sprint(value.Interface())) {
+				return []fieldError{{Field: name, Rule: rule, Message: "must be one of " + strings.Join(allowed, ", ")}}
+			}
+
+		case "dive":
+			var errs []fieldError
+			for j := 0; j < value.Len(); j++ {
+				errs = append(errs, validateField(fmt.Sprintf("%s[%d]", name, j), value.Index(j), rules[i+1:])...)
+			}
+			return errs
+
+		default:
+			format, ok := formatRules[rule]
+			if !ok || value.Kind() != reflect.String {
+				panic(fmt.Sprintf("validate: unsupported rule %q for field %s", rule, name))
+			}
+			if !format.check(value.String()) {
+				return []fieldError{{Field: name, Rule: rule, Message: format.message}}
+			}
+		}
+	}
+
+	return nil
+}
+
+// isSet reports whether a field holds a value, treating blank strings as missing
+func isSet(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) != ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() > 0
+	case reflect.Pointer, reflect.Interface:
+		return !value.IsNil()
+	default:
+		return !value.IsZero()
+	}
+}
+
+// checkBound checks a min or max rule, returning the message to report when it fails
+func checkBound(value reflect.Value, rule, param string) (string, bool) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: invalid %s bound %q", rule, param))
+	}
+
+	var actual float64
+	var unit string
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	case reflect.String:
+		actual, unit = float64(len(value.String())), " characters"
+	case reflect.Slice, reflect.Map:
+		actual, unit = float64(value.Len()), " items"
+	default:
+		panic(fmt.Sprintf("validate: %s rule on unsupported kind %s", rule, value.Kind()))
+	}
+
+	if rule == "min" && actual < bound {
+		return fmt.Sprintf("must be at least %s%s", param, unit), false
+	}
+	if rule == "max" && actual > bound {
+		return fmt.Sprintf("must be at most %s%s", param, unit), false
+	}
+	return "", true
+}
+
+// jsonNames returns the JSON name of each field of a struct type, or "" for fields that are
+// not decoded
+func jsonNames(t reflect.Type) []string {
+	names := make([]string, t.NumField())
+	for name, index := range jsonFields(t) {
+		names[index] = name
+	}
+	return names
+}
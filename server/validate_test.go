@@ -0,0 +1,123 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequest(t *testing.T) {
+	require.Nil(t, validateRequest(CreateClaimRequest{NDC: "0002-3234-01", NPI: "1234567890", Quantity: 30}))
+	require.Nil(t, validateRequest(CreateClaimRequest{NDC: "123456789", NPI: "9876543210", Quantity: 30}))
+
+	// Every invalid field is reported, in field order
+	verr := validateRequest(CreateClaimRequest{NDC: "123", NPI: "", Quantity: 0, Price: -1})
+	require.NotNil(t, verr)
+	require.Equal(t, []fieldError{
+		{Field: "ndc", Rule: "ndc", Message: "must be 9 to 11 digits, optionally hyphenated"},
+		{Field: "quantity", Rule: "required", Message: "is required"},
+		{Field: "npi", Rule: "required", Message: "is required"},
+		{Field: "price", Rule: "min", Message: "must be at least 0"},
+	}, verr.Fields)
+	require.Contains(t, verr.Message, "quantity is required")
+
+	verr = validateRequest(CreateReversalRequest{Kind: "refund", Quantity: -1, Notes: string(make([]byte, 1001))})
+	require.NotNil(t, verr)
+	require.Equal(t, []fieldError{
+		{Field: "claim_id", Rule: "required", Message: "is required"},
+		{Field: "kind", Rule: "oneof", Message: "must be one of reversal, adjustment"},
+		{Field: "quantity", Rule: "min", Message: "must be at least 0"},
+		{Field: "reason_code", Rule: "required", Message: "is required"},
+		{Field: "notes", Rule: "max", Message: "must be at most 1000 characters"},
+	}, verr.Fields)
+
+	// omitempty skips unset fields
+	require.Nil(t, validateRequest(CreateReversalRequest{ClaimID: uuid.New(), ReasonCode: "DUPLICATE"}))
+
+	// dive checks each element
+	verr = validateRequest(APIKeyRequest{Name: "POS", NPIs: []string{"1234567890", "123456789"}})
+	require.NotNil(t, verr)
+	require.Equal(t, []fieldError{
+		{Field: "npis[1]", Rule: "npi", Message: "must be 10 digits"},
+	}, verr.Fields)
+
+	// Drugs are keyed by the billing format
+	verr = validateRequest(DrugRequest{NDC: "123456789", Name: "Drug"})
+	require.NotNil(t, verr)
+	require.Equal(t, "billing_ndc", verr.Fields[0].Rule)
+
+	// Blank strings and nil pointers are missing
+	verr = validateRequest(UpdatePharmacyRequest{})
+	require.NotNil(t, verr)
+	require.Equal(t, "active is required", verr.Message)
+	verr = validateRequest(ReversalReasonRequest{Code: "NOT_PICKED_UP", Description: "  "})
+	require.NotNil(t, verr)
+	require.Equal(t, "description is required", verr.Message)
+}
+
+func TestValidateClaimRequestReason(t *testing.T) {
+	testCases := []struct {
+		req    CreateClaimRequest
+		reason string
+	}{
+		{CreateClaimRequest{NPI: "1234567890", Quantity: 1}, rejectMissingNDC},
+		{CreateClaimRequest{NDC: "123", NPI: "1234567890", Quantity: 1}, rejectInvalidNDC},
+		{CreateClaimRequest{NDC: "00002323401", Quantity: 1}, rejectMissingNPI},
+		{CreateClaimRequest{NDC: "00002323401", NPI: "123456789", Quantity: 1}, rejectInvalidNPI},
+		{CreateClaimRequest{NDC: "00002323401", NPI: "1234567890"}, rejectInvalidQuantity},
+		{CreateClaimRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 1, Price: -1}, rejectNegativePrice},
+	}
+
+	server := NewServer(util.Config{}, nil, nil, nil, nil, nil)
+	for _, tc := range testCases {
+		verr := server.validateClaimRequest(tc.req)
+		require.NotNil(t, verr)
+		require.Equal(t, tc.reason, verr.Reason)
+	}
+}
+
+func TestValidateClaimRequestStrict(t *testing.T) {
+	// Historical NDCs and NPIs are accepted unless strict validation is enabled
+	req := CreateClaimRequest{NDC: "123456789", NPI: "9876543210", Quantity: 30}
+
+	server := NewServer(util.Config{}, nil, nil, nil, nil, nil)
+	require.Nil(t, server.validateClaimRequest(req))
+
+	server.config.StrictNDCValidation = true
+	verr := server.validateClaimRequest(req)
+	require.NotNil(t, verr)
+	require.Equal(t, rejectInvalidNDC, verr.Reason)
+	require.Equal(t, []fieldError{{Field: "ndc", Rule: "billing_ndc", Message: "must be 11 digits or a hyphenated 4-4-2, 5-3-2 or 5-4-1 NDC"}}, verr.Fields)
+
+	server.config.StrictNDCValidation = false
+	server.config.StrictNPIValidation = true
+	verr = server.validateClaimRequest(req)
+	require.NotNil(t, verr)
+	require.Equal(t, rejectInvalidNPI, verr.Reason)
+	require.Equal(t, []fieldError{{Field: "npi", Rule: "npi_check_digit", Message: "must end in a valid check digit"}}, verr.Fields)
+
+	server.config.StrictNDCValidation = true
+	require.Nil(t, server.validateClaimRequest(CreateClaimRequest{NDC: "0002-3234-01", NPI: "9876543213", Quantity: 30}))
+}
+
+// TestRequestTags checks that the validate tags of every request type are well formed
+func TestRequestTags(t *testing.T) {
+	requests := []interface{}{
+		APIKeyRequest{NPIs: []string{""}},
+		BatchReversalRequest{},
+		ContractRequest{},
+		CreateClaimRequest{},
+		CreateReversalRequest{},
+		CreateSettlementRequest{Cutoff: "yesterday"},
+		DrugRequest{},
+		ReferencePriceRequest{EffectiveTo: "later"},
+		ReversalReasonRequest{},
+		UpdatePharmacyRequest{},
+	}
+
+	for _, req := range requests {
+		require.NotPanics(t, func() { validateRequest(req) })
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// apiVersion is a version of the HTTP API, served under /api/{version}
+type apiVersion string
+
+const (
+	apiV1 apiVersion = "v1"
+	apiV2 apiVersion = "v2"
+)
+
+// path returns the path of a resource in the version, such as /api/v2/claims
+func (version apiVersion) path(resource string) string {
+	return "/api/" + string(version) + resource
+}
+
+// idempotencyKey scopes a client's Idempotency-Key to the caller and the version, so callers
+// cannot collide on each other's keys and a retry is always answered in the representation it
+// asked for. Anonymous v1 keys are stored unchanged.
+func (version apiVersion) idempotencyKey(scope, key string) string {
+	if version != apiV1 {
+		key = string(version) + ":" + key
+	}
+	if scope != "" {
+		key = scope + ":" + key
+	}
+	return key
+}
+
+// successorVersions are the deprecated route patterns and the version that replaces each one.
+// Both versions share their handler logic and differ only in how the resource is written.
+var successorVersions = map[string]apiVersion{
+	"POST /api/v1/claims":     apiV2,
+	"GET /api/v1/claims/{id}": apiV2,
+}
+
+// deprecationMiddleware marks responses of deprecated routes with the configured Deprecation
+// (RFC 9745) and Sunset (RFC 8594) and a Link to the same resource in the successor version.
+// Nothing is marked until a deprecation date is configured.
+func (server *Server) deprecationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := server.router.Handler(r)
+		if successor, ok := successorVersions[pattern]; ok && !server.config.APIV1DeprecatedTime.IsZero() {
+			server.setDeprecationHeaders(w, r, successor)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setDeprecationHeaders writes the headers announcing that r is served by a deprecated v1 route
+func (server *Server) setDeprecationHeaders(w http.ResponseWriter, r *http.Request, successor apiVersion) {
+	w.Header().Set("Deprecation", fmt.Sprintf("@%d", server.config.APIV1DeprecatedTime.Unix()))
+
+	if sunset := server.config.APIV1SunsetTime; !sunset.IsZero() {
+		w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+
+	resource := strings.TrimPrefix(r.URL.Path, apiV1.path(""))
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor.path(resource)))
+}
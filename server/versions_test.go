@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pharmacy_claims_application/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	config := util.Config{
+		APIV1DeprecatedTime: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		APIV1SunsetTime:     time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC),
+	}
+	server := NewServer(config, nil, nil, nil, nil, nil)
+	handler := server.deprecationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(method, path string) http.Header {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Header()
+	}
+
+	header := serve(http.MethodGet, "/api/v1/claims/550e8400-e29b-41d4-a716-446655440000")
+	require.Equal(t, "@1792368000", header.Get("Deprecation"))
+	require.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", header.Get("Sunset"))
+	require.Equal(t, `</api/v2/claims/550e8400-e29b-41d4-a716-446655440000>; rel="successor-version"`, header.Get("Link"))
+
+	header = serve(http.MethodPost, "/api/v1/claims")
+	require.Equal(t, `</api/v2/claims>; rel="successor-version"`, header.Get("Link"))
+
+	// Routes without a successor and the successors themselves are not deprecated
+	for _, path := range []string{"/api/v1/reversals", "/api/v1/claims/batch", "/api/v2/claims"} {
+		header = serve(http.MethodPost, path)
+		require.Empty(t, header.Get("Deprecation"), path)
+		require.Empty(t, header.Get("Link"), path)
+	}
+
+	// The sunset is only announced once it is configured
+	server.config.APIV1SunsetTime = time.Time{}
+	header = serve(http.MethodPost, "/api/v1/claims")
+	require.NotEmpty(t, header.Get("Deprecation"))
+	require.Empty(t, header.Get("Sunset"))
+
+	// Nor are the routes deprecated before a deprecation date is configured
+	server.config.APIV1DeprecatedTime = time.Time{}
+	header = serve(http.MethodPost, "/api/v1/claims")
+	require.Empty(t, header.Get("Deprecation"))
+	require.Empty(t, header.Get("Link"))
+}
+
+func TestDeprecatedRoutesHaveSuccessors(t *testing.T) {
+	server := NewServer(util.Config{}, nil, nil, nil, nil, nil)
+
+	for pattern, successor := range successorVersions {
+		method, path, _ := strings.Cut(pattern, " ")
+		path = successor.path(strings.TrimPrefix(path, apiV1.path("")))
+
+		_, matched := server.router.Handler(httptest.NewRequest(method, path, nil))
+		require.Equal(t, method+" "+path, matched, pattern)
+		require.Equal(t, scopedRoutes[pattern], scopedRoutes[matched], pattern)
+		require.Equal(t, routePermissions[pattern], routePermissions[matched], pattern)
+	}
+}
+
+func TestIdempotencyKeyScope(t *testing.T) {
+	require.Equal(t, "retry-1", apiV1.idempotencyKey("", "retry-1"))
+	require.Equal(t, "v2:retry-1", apiV2.idempotencyKey("", "retry-1"))
+	require.Equal(t, "admin:retry-1", apiV1.idempotencyKey(adminActor, "retry-1"))
+	require.Equal(t, "admin:v2:retry-1", apiV2.idempotencyKey(adminActor, "retry-1"))
+}
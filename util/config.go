@@ -1,17 +1,122 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBSource      string `mapstructure:"DB_SOURCE"`
-	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+	DBDriver          string        `mapstructure:"DB_DRIVER"`
+	DBSource          string        `mapstructure:"DB_SOURCE"`
+	ServerAddress     string        `mapstructure:"SERVER_ADDRESS"`
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+
+	// Duplicate claim detection; a zero window disables the check
+	DuplicateClaimWindow time.Duration `mapstructure:"DUPLICATE_CLAIM_WINDOW"`
+	DuplicateClaimPolicy string        `mapstructure:"DUPLICATE_CLAIM_POLICY"`
+
+	// Refuse claims whose NDC is not in the 11-digit billing format, or is unknown or inactive in
+	// the drugs table, and claims whose NPI does not end in a valid check digit
+	StrictNDCValidation bool `mapstructure:"STRICT_NDC_VALIDATION"`
+	StrictNPIValidation bool `mapstructure:"STRICT_NPI_VALIDATION"`
+
+	// Largest request body accepted, and the larger limit of batch and claim file uploads
+	MaxRequestBodyBytes int64 `mapstructure:"MAX_REQUEST_BODY_BYTES"`
+	MaxUploadBodyBytes  int64 `mapstructure:"MAX_UPLOAD_BODY_BYTES"`
+
+	// Maximum number of claims accepted by the batch endpoint
+	BatchMaxClaims int `mapstructure:"BATCH_MAX_CLAIMS"`
+	// Maximum number of claim records in an ingested claim file
+	ClaimFileMaxRecords int `mapstructure:"CLAIM_FILE_MAX_RECORDS"`
+
+	// How long after adjudication a claim may be reversed; a zero window disables the check.
+	// REVERSAL_WINDOW_BY_CHAIN overrides it per chain as a list such as "CVS=720h,Walgreens=2160h".
+	ReversalWindow        time.Duration            `mapstructure:"REVERSAL_WINDOW"`
+	ReversalWindowByChain string                   `mapstructure:"REVERSAL_WINDOW_BY_CHAIN"`
+	ReversalChainWindows  map[string]time.Duration `mapstructure:"-"`
+
+	// Adjudication rules in evaluation order, and the limits they enforce; a zero limit disables it.
+	// The formulary rule is left out by default because the drugs table starts empty, and it
+	// would reject every claim until product files are imported.
+	AdjudicationRules          string        `mapstructure:"ADJUDICATION_RULES"`
+	AdjudicationMaxQuantity    int64         `mapstructure:"ADJUDICATION_MAX_QUANTITY"`
+	AdjudicationMaxPrice       float64       `mapstructure:"ADJUDICATION_MAX_PRICE"`
+	AdjudicationRefillInterval time.Duration `mapstructure:"ADJUDICATION_REFILL_INTERVAL"`
+
+	// Reference pricing: the allowed amount is quantity times the NDC's unit price plus a
+	// dispensing fee. A chain's contract sets both its discount and fee; chains without one pay
+	// DISPENSING_FEE, which DISPENSING_FEE_BY_CHAIN overrides as "CVS=1.50,Walgreens=2".
+	// Claims priced more than PRICE_TOLERANCE_PERCENT above it are flagged or rejected.
+	DispensingFee         float64            `mapstructure:"DISPENSING_FEE"`
+	DispensingFeeByChain  string             `mapstructure:"DISPENSING_FEE_BY_CHAIN"`
+	ChainDispensingFees   map[string]float64 `mapstructure:"-"`
+	PriceTolerancePercent float64            `mapstructure:"PRICE_TOLERANCE_PERCENT"`
+	PriceCeilingPolicy    string             `mapstructure:"PRICE_CEILING_POLICY"`
+
+	// Require an API key on every /api/ route. ADMIN_API_KEY may call every route, including
+	// the endpoints that issue keys scoped to pharmacies or chains. Loading fails when auth is
+	// enabled without an admin key or a JWKS file, as nobody could then call the API.
+	AuthEnabled bool   `mapstructure:"AUTH_ENABLED"`
+	AdminAPIKey string `mapstructure:"ADMIN_API_KEY"`
+
+	// Bearer tokens of internal users, signed with HS256 or RS256 by a key in JWT_JWKS_FILE.
+	// Tokens are refused when no file is set; issuer and audience are only checked when set.
+	JWTJWKSFile   string        `mapstructure:"JWT_JWKS_FILE"`
+	JWTIssuer     string        `mapstructure:"JWT_ISSUER"`
+	JWTAudience   string        `mapstructure:"JWT_AUDIENCE"`
+	JWTRolesClaim string        `mapstructure:"JWT_ROLES_CLAIM"`
+	JWTLeeway     time.Duration `mapstructure:"JWT_LEEWAY"`
+
+	// HTTPS is served when TLS_CERT_FILE and TLS_KEY_FILE are set; the files are checked for
+	// changes every TLS_RELOAD_INTERVAL. With TLS_CLIENT_CA_FILE, client certificates are verified
+	// against that bundle, and required when TLS_REQUIRE_CLIENT_CERT is set. TLS_CLIENT_SUBJECTS
+	// maps certificate common names to NPIs or a chain, as
+	// "pos-0042=1234567890|1234567891,cvs-gateway=chain:CVS".
+	TLSCertFile          string                 `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile           string                 `mapstructure:"TLS_KEY_FILE"`
+	TLSClientCAFile      string                 `mapstructure:"TLS_CLIENT_CA_FILE"`
+	TLSRequireClientCert bool                   `mapstructure:"TLS_REQUIRE_CLIENT_CERT"`
+	TLSClientSubjects    string                 `mapstructure:"TLS_CLIENT_SUBJECTS"`
+	ClientSubjects       map[string]ClientScope `mapstructure:"-"`
+	TLSReloadInterval    time.Duration          `mapstructure:"TLS_RELOAD_INTERVAL"`
+
+	// Token-bucket rate limits per API key, pharmacy or client IP, written as count/unit with an
+	// optional :burst, such as "10/s:20" or "600/m". RATE_LIMIT_ROUTES sets per-route limits as
+	// "POST /api/v1/claims=5/s:10,POST /api/v1/claims/batch=1/m"; the other routes share
+	// RATE_LIMIT_DEFAULT, and are unlimited when it is empty. RATE_LIMIT_PER_IP limits every API
+	// request by client IP before it is authenticated, so bad credentials cannot be tried unbounded.
+	RateLimitEnabled bool                 `mapstructure:"RATE_LIMIT_ENABLED"`
+	RateLimitDefault string               `mapstructure:"RATE_LIMIT_DEFAULT"`
+	RateLimitRoutes  string               `mapstructure:"RATE_LIMIT_ROUTES"`
+	RateLimitPerIP   string               `mapstructure:"RATE_LIMIT_PER_IP"`
+	DefaultRateLimit *RateLimit           `mapstructure:"-"`
+	RouteRateLimits  map[string]RateLimit `mapstructure:"-"`
+	PerIPRateLimit   *RateLimit           `mapstructure:"-"`
+
+	// Deprecation and sunset of the v1 routes that have a v2 successor, as dates such as
+	// "2027-06-30" or RFC 3339 times. They are announced in the Deprecation and Sunset headers of
+	// those routes; the routes are not marked deprecated while API_V1_DEPRECATED_AT is empty, and
+	// the Sunset header is omitted while API_V1_SUNSET_AT is.
+	APIV1DeprecatedAt   string    `mapstructure:"API_V1_DEPRECATED_AT"`
+	APIV1SunsetAt       string    `mapstructure:"API_V1_SUNSET_AT"`
+	APIV1DeprecatedTime time.Time `mapstructure:"-"`
+	APIV1SunsetTime     time.Time `mapstructure:"-"`
+
+	// Payer identity written to settlement remittance files
+	SettlementPayerID   string `mapstructure:"SETTLEMENT_PAYER_ID"`
+	SettlementPayerName string `mapstructure:"SETTLEMENT_PAYER_NAME"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
+	setDefaults()
 
 	viper.AutomaticEnv()
 
@@ -24,6 +129,7 @@ func LoadConfig(path string) (config Config, err error) {
 		viper.AddConfigPath("../")
 		viper.SetConfigName("app")
 		viper.SetConfigType("env")
+		setDefaults()
 
 		err = viper.ReadInConfig()
 		if err != nil {
@@ -36,5 +142,308 @@ func LoadConfig(path string) (config Config, err error) {
 		return
 	}
 
+	config.ReversalChainWindows, err = ParseChainDurations(config.ReversalWindowByChain)
+	if err != nil {
+		err = fmt.Errorf("invalid REVERSAL_WINDOW_BY_CHAIN: %w", err)
+		return
+	}
+
+	config.ChainDispensingFees, err = ParseChainAmounts(config.DispensingFeeByChain)
+	if err != nil {
+		err = fmt.Errorf("invalid DISPENSING_FEE_BY_CHAIN: %w", err)
+		return
+	}
+
+	config.ClientSubjects, err = ParseClientSubjects(config.TLSClientSubjects)
+	if err != nil {
+		err = fmt.Errorf("invalid TLS_CLIENT_SUBJECTS: %w", err)
+		return
+	}
+
+	if value := strings.TrimSpace(config.RateLimitDefault); value != "" {
+		var limit RateLimit
+		if limit, err = ParseRateLimit(value); err != nil {
+			err = fmt.Errorf("invalid RATE_LIMIT_DEFAULT: %w", err)
+			return
+		}
+		config.DefaultRateLimit = &limit
+	}
+
+	config.RouteRateLimits, err = ParseRouteRateLimits(config.RateLimitRoutes)
+	if err != nil {
+		err = fmt.Errorf("invalid RATE_LIMIT_ROUTES: %w", err)
+		return
+	}
+
+	if value := strings.TrimSpace(config.RateLimitPerIP); value != "" {
+		var limit RateLimit
+		if limit, err = ParseRateLimit(value); err != nil {
+			err = fmt.Errorf("invalid RATE_LIMIT_PER_IP: %w", err)
+			return
+		}
+		config.PerIPRateLimit = &limit
+	}
+
+	config.APIV1DeprecatedTime, err = ParseDate(config.APIV1DeprecatedAt)
+	if err != nil {
+		err = fmt.Errorf("invalid API_V1_DEPRECATED_AT: %w", err)
+		return
+	}
+
+	config.APIV1SunsetTime, err = ParseDate(config.APIV1SunsetAt)
+	if err != nil {
+		err = fmt.Errorf("invalid API_V1_SUNSET_AT: %w", err)
+		return
+	}
+
+	err = CheckAuth(config)
 	return
 }
+
+// CheckAuth refuses authentication settings that would lock every caller out: with AUTH_ENABLED
+// someone must be able to issue keys, either with ADMIN_API_KEY or with an admin-role bearer token
+// verified by JWT_JWKS_FILE
+func CheckAuth(config Config) error {
+	if !config.AuthEnabled || strings.TrimSpace(config.AdminAPIKey) != "" || config.JWTJWKSFile != "" {
+		return nil
+	}
+	return errors.New("AUTH_ENABLED requires ADMIN_API_KEY or JWT_JWKS_FILE; set one of them, or AUTH_ENABLED=false for local development")
+}
+
+// ParseChainDurations parses a comma-separated list of chain=duration pairs
+func ParseChainDurations(value string) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		chain, duration, ok := strings.Cut(pair, "=")
+		chain = strings.TrimSpace(chain)
+		if !ok || chain == "" {
+			return nil, fmt.Errorf("expected chain=duration, got %q", pair)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", chain, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("chain %s: duration cannot be negative", chain)
+		}
+
+		durations[chain] = d
+	}
+
+	return durations, nil
+}
+
+// ParseChainAmounts parses a comma-separated list of chain=amount pairs
+func ParseChainAmounts(value string) (map[string]float64, error) {
+	amounts := make(map[string]float64)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		chain, amount, ok := strings.Cut(pair, "=")
+		chain = strings.TrimSpace(chain)
+		if !ok || chain == "" {
+			return nil, fmt.Errorf("expected chain=amount, got %q", pair)
+		}
+
+		a, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", chain, err)
+		}
+		if a < 0 {
+			return nil, fmt.Errorf("chain %s: amount cannot be negative", chain)
+		}
+
+		amounts[chain] = a
+	}
+
+	return amounts, nil
+}
+
+// ClientScope is the pharmacies a client certificate may act for: a list of NPIs or a chain
+type ClientScope struct {
+	Chain string
+	NPIs  []string
+}
+
+// ParseClientSubjects parses a comma-separated list of subject=scope pairs, where the scope is
+// either chain:<name> or NPIs separated by |
+func ParseClientSubjects(value string) (map[string]ClientScope, error) {
+	subjects := make(map[string]ClientScope)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		subject, scope, ok := strings.Cut(pair, "=")
+		subject = strings.TrimSpace(subject)
+		scope = strings.TrimSpace(scope)
+		if !ok || subject == "" || scope == "" {
+			return nil, fmt.Errorf("expected subject=scope, got %q", pair)
+		}
+
+		if chain, found := strings.CutPrefix(scope, "chain:"); found {
+			chain = strings.TrimSpace(chain)
+			if chain == "" {
+				return nil, fmt.Errorf("subject %s: chain cannot be empty", subject)
+			}
+			subjects[subject] = ClientScope{Chain: chain}
+			continue
+		}
+
+		var npis []string
+		for _, npi := range strings.Split(scope, "|") {
+			npi = strings.TrimSpace(npi)
+			if npi == "" {
+				return nil, fmt.Errorf("subject %s: NPI cannot be empty", subject)
+			}
+			npis = append(npis, npi)
+		}
+		subjects[subject] = ClientScope{NPIs: npis}
+	}
+
+	return subjects, nil
+}
+
+// RateLimit is a sustained request rate and the burst allowed on top of it
+type RateLimit struct {
+	// Rate is the number of requests per second
+	Rate  float64
+	Burst int
+}
+
+// rateLimitUnits are the units a rate limit may be written in
+var rateLimitUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseRateLimit parses a limit written as count/unit[:burst], such as "10/s:20". The burst
+// defaults to the count.
+func ParseRateLimit(value string) (RateLimit, error) {
+	spec, burstValue, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+
+	countValue, unitValue, ok := strings.Cut(spec, "/")
+	unit, known := rateLimitUnits[strings.TrimSpace(unitValue)]
+	if !ok || !known {
+		return RateLimit{}, fmt.Errorf("expected count/unit with unit s, m or h, got %q", value)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countValue))
+	if err != nil || count <= 0 {
+		return RateLimit{}, fmt.Errorf("count must be a positive integer, got %q", countValue)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(strings.TrimSpace(burstValue))
+		if err != nil || burst <= 0 {
+			return RateLimit{}, fmt.Errorf("burst must be a positive integer, got %q", burstValue)
+		}
+	}
+
+	return RateLimit{Rate: float64(count) / unit.Seconds(), Burst: burst}, nil
+}
+
+// ParseRouteRateLimits parses a comma-separated list of route=limit pairs
+func ParseRouteRateLimits(value string) (map[string]RateLimit, error) {
+	limits := make(map[string]RateLimit)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		route, limitValue, ok := strings.Cut(pair, "=")
+		route = strings.Join(strings.Fields(route), " ")
+		if !ok || route == "" {
+			return nil, fmt.Errorf("expected route=limit, got %q", pair)
+		}
+
+		limit, err := ParseRateLimit(limitValue)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", route, err)
+		}
+
+		limits[route] = limit
+	}
+
+	return limits, nil
+}
+
+// ParseDate parses a date such as "2027-06-30", taken as midnight UTC, or an RFC 3339 time.
+// An empty value is the zero time.
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or an RFC 3339 time, got %q", value)
+	}
+	return t, nil
+}
+
+// setDefaults registers default values for optional settings
+func setDefaults() {
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	viper.SetDefault("DUPLICATE_CLAIM_WINDOW", 24*time.Hour)
+	viper.SetDefault("DUPLICATE_CLAIM_POLICY", "flag")
+	viper.SetDefault("STRICT_NDC_VALIDATION", false)
+	viper.SetDefault("STRICT_NPI_VALIDATION", false)
+	viper.SetDefault("MAX_REQUEST_BODY_BYTES", 1<<20)
+	viper.SetDefault("MAX_UPLOAD_BODY_BYTES", 32<<20)
+	viper.SetDefault("BATCH_MAX_CLAIMS", 1000)
+	viper.SetDefault("CLAIM_FILE_MAX_RECORDS", 10000)
+	viper.SetDefault("AUTH_ENABLED", true)
+	viper.SetDefault("ADMIN_API_KEY", "")
+	viper.SetDefault("JWT_JWKS_FILE", "")
+	viper.SetDefault("JWT_ISSUER", "")
+	viper.SetDefault("JWT_AUDIENCE", "")
+	viper.SetDefault("JWT_ROLES_CLAIM", "roles")
+	viper.SetDefault("JWT_LEEWAY", time.Minute)
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("TLS_REQUIRE_CLIENT_CERT", false)
+	viper.SetDefault("TLS_CLIENT_SUBJECTS", "")
+	viper.SetDefault("TLS_RELOAD_INTERVAL", time.Minute)
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("RATE_LIMIT_DEFAULT", "50/s:100")
+	viper.SetDefault("RATE_LIMIT_ROUTES", "POST /api/v1/claims=10/s:20,POST /api/v2/claims=10/s:20,POST /api/v1/claims/batch=1/s:2,POST /api/v1/claims/files=10/m")
+	viper.SetDefault("RATE_LIMIT_PER_IP", "100/s:200")
+	viper.SetDefault("REVERSAL_WINDOW", 90*24*time.Hour)
+	viper.SetDefault("REVERSAL_WINDOW_BY_CHAIN", "")
+	viper.SetDefault("ADJUDICATION_RULES", "pharmacy_active,quantity_limit,price_ceiling,refill_too_soon")
+	viper.SetDefault("ADJUDICATION_MAX_QUANTITY", 10000)
+	viper.SetDefault("ADJUDICATION_MAX_PRICE", 100000)
+	viper.SetDefault("ADJUDICATION_REFILL_INTERVAL", 0)
+	viper.SetDefault("DISPENSING_FEE", 0)
+	viper.SetDefault("DISPENSING_FEE_BY_CHAIN", "")
+	viper.SetDefault("PRICE_TOLERANCE_PERCENT", 10)
+	viper.SetDefault("PRICE_CEILING_POLICY", "flag")
+	viper.SetDefault("API_V1_DEPRECATED_AT", "")
+	viper.SetDefault("API_V1_SUNSET_AT", "")
+	viper.SetDefault("SETTLEMENT_PAYER_ID", "PHARMACYCLAIMS")
+	viper.SetDefault("SETTLEMENT_PAYER_NAME", "Pharmacy Claims Application")
+}
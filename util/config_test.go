@@ -0,0 +1,99 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClientSubjects(t *testing.T) {
+	subjects, err := ParseClientSubjects(" pos-0042 = 1234567890|1234567891 , cvs-gateway=chain:CVS,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]ClientScope{
+		"pos-0042":    {NPIs: []string{"1234567890", "1234567891"}},
+		"cvs-gateway": {Chain: "CVS"},
+	}, subjects)
+
+	subjects, err = ParseClientSubjects("")
+	require.NoError(t, err)
+	require.Empty(t, subjects)
+
+	for _, value := range []string{"pos-0042", "=1234567890", "pos-0042=", "gateway=chain:", "pos-0042=123||456"} {
+		_, err := ParseClientSubjects(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseRateLimit("10/s:20")
+	require.NoError(t, err)
+	require.Equal(t, RateLimit{Rate: 10, Burst: 20}, limit)
+
+	limit, err = ParseRateLimit(" 120/m ")
+	require.NoError(t, err)
+	require.Equal(t, RateLimit{Rate: 2, Burst: 120}, limit)
+
+	for _, value := range []string{"10", "10/d", "0/s", "-1/s", "10/s:0", "ten/s", "10/s:x"} {
+		_, err := ParseRateLimit(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseRouteRateLimits(t *testing.T) {
+	limits, err := ParseRouteRateLimits("POST  /api/v1/claims=5/s:10, GET /api/v1/claims/{id}=1/h")
+	require.NoError(t, err)
+	require.Equal(t, map[string]RateLimit{
+		"POST /api/v1/claims":     {Rate: 5, Burst: 10},
+		"GET /api/v1/claims/{id}": {Rate: 1.0 / 3600, Burst: 1},
+	}, limits)
+
+	_, err = ParseRouteRateLimits("POST /api/v1/claims")
+	require.Error(t, err)
+	_, err = ParseRouteRateLimits("POST /api/v1/claims=fast")
+	require.Error(t, err)
+}
+
+func TestParseDate(t *testing.T) {
+	date, err := ParseDate(" 2027-06-30 ")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = ParseDate("2027-06-30T12:00:00-05:00")
+	require.NoError(t, err)
+	require.True(t, date.Equal(time.Date(2027, time.June, 30, 17, 0, 0, 0, time.UTC)))
+
+	date, err = ParseDate("")
+	require.NoError(t, err)
+	require.True(t, date.IsZero())
+
+	for _, value := range []string{"30/06/2027", "2027-13-01", "soon"} {
+		_, err := ParseDate(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		ok     bool
+	}{
+		{name: "auth disabled", config: Config{}, ok: true},
+		{name: "admin key", config: Config{AuthEnabled: true, AdminAPIKey: "secret"}, ok: true},
+		{name: "bearer tokens", config: Config{AuthEnabled: true, JWTJWKSFile: "jwks.json"}, ok: true},
+		{name: "no way in", config: Config{AuthEnabled: true}},
+		{name: "blank admin key", config: Config{AuthEnabled: true, AdminAPIKey: "  "}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckAuth(tc.config)
+			if tc.ok {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, "ADMIN_API_KEY")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"strings"
+)
+
+// NormalizeNDC converts an NDC to the 11-digit 5-4-2 billing format used on claims.
+// It accepts 11 digits, or the hyphenated 4-4-2, 5-3-2 and 5-4-1 labeler-product-package forms.
+func NormalizeNDC(ndc string) (string, bool) {
+	ndc = strings.TrimSpace(ndc)
+
+	if !strings.Contains(ndc, "-") {
+		if len(ndc) == 11 && isDigits(ndc) {
+			return ndc, true
+		}
+		return "", false
+	}
+
+	parts := strings.Split(ndc, "-")
+	if len(parts) != 3 {
+		return "", false
+	}
+	for _, part := range parts {
+		if part == "" || !isDigits(part) {
+			return "", false
+		}
+	}
+
+	labeler, product, pkg := parts[0], parts[1], parts[2]
+	switch {
+	case len(labeler) == 4 && len(product) == 4 && len(pkg) == 2:
+		labeler = "0" + labeler
+	case len(labeler) == 5 && len(product) == 3 && len(pkg) == 2:
+		product = "0" + product
+	case len(labeler) == 5 && len(product) == 4 && len(pkg) == 1:
+		pkg = "0" + pkg
+	case len(labeler) == 5 && len(product) == 4 && len(pkg) == 2:
+	default:
+		return "", false
+	}
+
+	return labeler + product + pkg, true
+}
+
+// ValidNDCFormat reports whether ndc looks like a National Drug Code: 9 to 11 digits, optionally
+// split by hyphens. Unlike NormalizeNDC it accepts codes that cannot be converted to the billing
+// format, such as the 9-digit labeler-product code.
+func ValidNDCFormat(ndc string) bool {
+	digits := 0
+	for _, part := range strings.Split(ndc, "-") {
+		if part == "" || !isDigits(part) {
+			return false
+		}
+		digits += len(part)
+	}
+	return digits >= 9 && digits <= 11
+}
+
+// isDigits reports whether s consists only of ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
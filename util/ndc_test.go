@@ -0,0 +1,40 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeNDC(t *testing.T) {
+	testCases := []struct {
+		input string
+		ndc   string
+		ok    bool
+	}{
+		{input: "49884024302", ndc: "49884024302", ok: true},
+		{input: "0002-3234-01", ndc: "00002323401", ok: true},
+		{input: "49884-243-02", ndc: "49884024302", ok: true},
+		{input: "49884-0243-2", ndc: "49884024302", ok: true},
+		{input: "49884-0243-02", ndc: "49884024302", ok: true},
+		{input: "4988402430", ok: false},
+		{input: "49884-243-2", ok: false},
+		{input: "4988A024302", ok: false},
+		{input: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		ndc, ok := NormalizeNDC(tc.input)
+		require.Equal(t, tc.ok, ok, tc.input)
+		require.Equal(t, tc.ndc, ndc, tc.input)
+	}
+}
+
+func TestValidNDCFormat(t *testing.T) {
+	for _, ndc := range []string{"123456789", "4988402430", "49884024302", "0002-3234-01", "49884-243-2"} {
+		require.True(t, ValidNDCFormat(ndc), ndc)
+	}
+	for _, ndc := range []string{"12345678", "498840243021", "4988A024302", "49884--243", "-49884024302", ""} {
+		require.False(t, ValidNDCFormat(ndc), ndc)
+	}
+}
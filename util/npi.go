@@ -0,0 +1,33 @@
+package util
+
+// npiPrefix is prepended to an NPI when computing its check digit, as for a card issuer number
+const npiPrefix = "80840"
+
+// ValidNPIFormat reports whether npi has the format of a National Provider Identifier: 10 digits.
+// The check digit is not verified.
+func ValidNPIFormat(npi string) bool {
+	return len(npi) == 10 && isDigits(npi)
+}
+
+// ValidNPI reports whether npi is a 10-digit National Provider Identifier whose last digit is
+// the Luhn check digit of the first nine.
+func ValidNPI(npi string) bool {
+	if len(npi) != 10 || !isDigits(npi) {
+		return false
+	}
+
+	digits := npiPrefix + npi
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-1-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidNPI(t *testing.T) {
+	testCases := []struct {
+		input string
+		ok    bool
+	}{
+		{input: "1234567893", ok: true},
+		{input: "9876543213", ok: true},
+		{input: "1234567890", ok: false},
+		{input: "123456789", ok: false},
+		{input: "12345678930", ok: false},
+		{input: "123456789A", ok: false},
+		{input: "", ok: false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.ok, ValidNPI(tc.input), tc.input)
+	}
+}
+
+func TestValidNPIFormat(t *testing.T) {
+	require.True(t, ValidNPIFormat("1234567890"))
+	require.True(t, ValidNPIFormat("1234567893"))
+	require.False(t, ValidNPIFormat("123456789"))
+	require.False(t, ValidNPIFormat("12345678901"))
+	require.False(t, ValidNPIFormat("123456789A"))
+	require.False(t, ValidNPIFormat(""))
+}